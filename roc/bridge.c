@@ -1,5 +1,6 @@
 #include <pthread.h>
 #include <stdint.h>
+#include <stdlib.h>
 #include <unistd.h>
 #include <string.h>
 
@@ -14,8 +15,11 @@
 
 #include <roc/log.h>
 #include <roc/config.h>
+#include <roc/plugin.h>
 #include <roc/receiver.h>
+#include <roc/receiver_decoder.h>
 #include <roc/sender.h>
+#include <roc/sender_encoder.h>
 
 #include "_cgo_export.h"
 
@@ -41,6 +45,64 @@ void rocGoLogHandlerProxy(const roc_log_message* message, void* argument) {
     rocGoLogHandler((roc_log_message*)message);
 }
 
+typedef struct rocGoPlcPlugin {
+    roc_plugin_plc plugin;
+    unsigned long long factory_handle;
+} rocGoPlcPlugin;
+
+static void* rocGoPlcNewProxy(roc_plugin_plc* plugin, const roc_media_encoding* encoding) {
+    unsigned long long handle = rocGoPlcNew(((rocGoPlcPlugin*)plugin)->factory_handle,
+                                            (roc_media_encoding*)encoding);
+    return (void*)(uintptr_t)handle;
+}
+
+static void rocGoPlcDeleteProxy(void* plugin_instance) {
+    rocGoPlcDelete((unsigned long long)(uintptr_t)plugin_instance);
+}
+
+static unsigned int rocGoPlcLookaheadLenProxy(void* plugin_instance) {
+    return rocGoPlcLookaheadLen((unsigned long long)(uintptr_t)plugin_instance);
+}
+
+static void rocGoPlcProcessHistoryProxy(void* plugin_instance, const roc_frame* history_frame) {
+    rocGoPlcProcessHistory((unsigned long long)(uintptr_t)plugin_instance,
+                           (float*)history_frame->samples,
+                           history_frame->samples_size / sizeof(float));
+}
+
+static void rocGoPlcProcessLossProxy(void* plugin_instance, roc_frame* lost_frame,
+                                     const roc_frame* next_frame) {
+    float* next_samples = NULL;
+    unsigned long next_samples_size = 0;
+    if (next_frame != NULL) {
+        next_samples = (float*)next_frame->samples;
+        next_samples_size = next_frame->samples_size / sizeof(float);
+    }
+    rocGoPlcProcessLoss((unsigned long long)(uintptr_t)plugin_instance,
+                        (float*)lost_frame->samples,
+                        lost_frame->samples_size / sizeof(float),
+                        next_samples,
+                        next_samples_size);
+}
+
+roc_plugin_plc* rocGoPlcPluginAllocate(unsigned long long factory_handle) {
+    rocGoPlcPlugin* plugin = calloc(1, sizeof(rocGoPlcPlugin));
+    if (plugin == NULL) {
+        return NULL;
+    }
+    plugin->plugin.new_cb = rocGoPlcNewProxy;
+    plugin->plugin.delete_cb = rocGoPlcDeleteProxy;
+    plugin->plugin.lookahead_len_cb = rocGoPlcLookaheadLenProxy;
+    plugin->plugin.process_history_cb = rocGoPlcProcessHistoryProxy;
+    plugin->plugin.process_loss_cb = rocGoPlcProcessLossProxy;
+    plugin->factory_handle = factory_handle;
+    return &plugin->plugin;
+}
+
+void rocGoPlcPluginDeallocate(roc_plugin_plc* plugin) {
+    free(plugin);
+}
+
 int rocGoSetOutgoingAddress(roc_interface_config* config, const char* value) {
     if (strlen(value) >= sizeof(config->outgoing_address)) {
         return -1;
@@ -74,3 +136,65 @@ int rocGoSenderWriteFloats(roc_sender* sender, float* samples,
     };
     return roc_sender_write(sender, &frame);
 }
+
+int rocGoSenderEncoderPushFloats(roc_sender_encoder* encoder, float* samples,
+                                 unsigned long samples_size) {
+    roc_frame frame = {
+        (void*)samples,
+        samples_size * sizeof(float),
+    };
+    return roc_sender_encoder_push_frame(encoder, &frame);
+}
+
+int rocGoSenderEncoderPushFeedbackPacket(roc_sender_encoder* encoder, roc_interface iface,
+                                         unsigned char* bytes, unsigned long bytes_size) {
+    roc_packet packet = {
+        (void*)bytes,
+        bytes_size,
+    };
+    return roc_sender_encoder_push_feedback_packet(encoder, iface, &packet);
+}
+
+int rocGoSenderEncoderPopPacket(roc_sender_encoder* encoder, roc_interface iface,
+                                unsigned char* bytes, unsigned long* bytes_size) {
+    roc_packet packet = {
+        (void*)bytes,
+        *bytes_size,
+    };
+    int err = roc_sender_encoder_pop_packet(encoder, iface, &packet);
+    if (err == 0) {
+        *bytes_size = packet.bytes_size;
+    }
+    return err;
+}
+
+int rocGoReceiverDecoderPushPacket(roc_receiver_decoder* decoder, roc_interface iface,
+                                   unsigned char* bytes, unsigned long bytes_size) {
+    roc_packet packet = {
+        (void*)bytes,
+        bytes_size,
+    };
+    return roc_receiver_decoder_push_packet(decoder, iface, &packet);
+}
+
+int rocGoReceiverDecoderPopFeedbackPacket(roc_receiver_decoder* decoder, roc_interface iface,
+                                          unsigned char* bytes, unsigned long* bytes_size) {
+    roc_packet packet = {
+        (void*)bytes,
+        *bytes_size,
+    };
+    int err = roc_receiver_decoder_pop_feedback_packet(decoder, iface, &packet);
+    if (err == 0) {
+        *bytes_size = packet.bytes_size;
+    }
+    return err;
+}
+
+int rocGoReceiverDecoderPopFloats(roc_receiver_decoder* decoder, float* samples,
+                                  unsigned long samples_size) {
+    roc_frame frame = {
+        (void*)samples,
+        samples_size * sizeof(float),
+    };
+    return roc_receiver_decoder_pop_frame(decoder, &frame);
+}
@@ -0,0 +1,194 @@
+package roc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Broadcaster sends one stream to multiple destinations.
+//
+// Broadcaster is built on top of a single Sender and manages one sender slot
+// per destination. Destinations can be added and removed at any time, while
+// the stream is being written to the sender; this doesn't interrupt the
+// stream delivered to other destinations.
+//
+// Each destination is identified by a unique name, which is also used as the
+// name of its slot (see Sender.NewSlot()).
+//
+// Broadcaster doesn't own the sender. The user writes the stream to the sender
+// and closes it when it's no longer needed. The broadcaster should not be used
+// after the sender is closed.
+//
+// # Thread safety
+//
+// Can be used concurrently.
+type Broadcaster struct {
+	mu     sync.Mutex
+	sender *Sender
+	dests  map[string]*broadcastDest
+}
+
+// DestinationStatus describes state of a destination of Broadcaster.
+type DestinationStatus struct {
+	// Destination name passed to Broadcaster.AddDestination().
+	Name string
+
+	// Sender slot used by destination.
+	Slot Slot
+
+	// Endpoints passed to Broadcaster.AddDestination().
+	Endpoints *EndpointSet
+
+	// Metrics of destination slot, see Sender.Query().
+	Metrics SenderMetrics
+
+	// Metrics of every active connection of destination slot, see
+	// Sender.Query().
+	Connections []ConnectionMetrics
+
+	// Error returned by Sender.Query(), or nil if metrics were retrieved
+	// successfully.
+	Err error
+}
+
+type broadcastDest struct {
+	slot Slot
+	set  *EndpointSet
+}
+
+// Create a new broadcaster on top of the sender.
+//
+// The sender should be opened and should not be closed until the broadcaster
+// is no longer used. The user may write the stream to the sender before and
+// after adding destinations.
+func NewBroadcaster(sender *Sender) *Broadcaster {
+	return &Broadcaster{
+		sender: sender,
+		dests:  make(map[string]*broadcastDest),
+	}
+}
+
+// Get the underlying sender.
+func (b *Broadcaster) Sender() *Sender {
+	return b.sender
+}
+
+// Add a new destination.
+//
+// Allocates a new sender slot using Sender.NewSlot() and connects all
+// endpoints of the set to it using Sender.ConnectSet(). If connect fails, the
+// slot is removed and the destination is not added.
+//
+// name should be non-empty and unique among destinations of this broadcaster
+// and among named slots of the sender. Returns ErrInvalidArgument if name is
+// empty, and ErrExists if it's already used.
+func (b *Broadcaster) AddDestination(name string, set *EndpointSet) (err error) {
+	logWrite(LogDebug,
+		"entering Broadcaster.AddDestination(): broadcaster=%p name=%q set=%+v", b, name, set,
+	)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving Broadcaster.AddDestination(): broadcaster=%p err=%#v", b, err,
+		)
+	}()
+
+	if name == "" {
+		return newErr(ErrInvalidArgument, "destination name is empty")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.dests[name]; ok {
+		return newErr(ErrExists, fmt.Sprintf("destination %q already exists", name))
+	}
+
+	// check set before allocating slot
+	if _, err := set.entries(); err != nil {
+		return err
+	}
+
+	slot, err := b.sender.NewSlot(name)
+	if err != nil {
+		return err
+	}
+
+	if err := b.sender.ConnectSet(slot, set); err != nil {
+		// ConnectSet() removes slot if it was created, otherwise it's still
+		// reserved by NewSlot()
+		b.sender.slots.removeReserved(slot)
+		return err
+	}
+
+	b.dests[name] = &broadcastDest{
+		slot: slot,
+		set:  set.clone(),
+	}
+
+	return nil
+}
+
+// Remove destination.
+//
+// Removes destination slot using Sender.Unlink(), which terminates connection
+// to the destination. If unlink fails, the destination is kept. Returns
+// ErrNotFound if there is no destination with given name.
+func (b *Broadcaster) RemoveDestination(name string) (err error) {
+	logWrite(LogDebug,
+		"entering Broadcaster.RemoveDestination(): broadcaster=%p name=%q", b, name,
+	)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving Broadcaster.RemoveDestination(): broadcaster=%p err=%#v", b, err,
+		)
+	}()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	dest, ok := b.dests[name]
+	if !ok {
+		return newErr(ErrNotFound, fmt.Sprintf("destination %q not found", name))
+	}
+
+	if err := b.sender.Unlink(dest.slot); err != nil {
+		return err
+	}
+
+	delete(b.dests, name)
+
+	return nil
+}
+
+// Get status of all destinations.
+//
+// Returns destinations sorted by name, together with their slot metrics
+// retrieved using Sender.Query(). If query fails for a destination, its Err
+// field is set and metrics are zero.
+//
+// Returned values are copies and can be freely modified.
+func (b *Broadcaster) Destinations() []DestinationStatus {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	statuses := make([]DestinationStatus, 0, len(b.dests))
+
+	for name, dest := range b.dests {
+		status := DestinationStatus{
+			Name:      name,
+			Slot:      dest.slot,
+			Endpoints: dest.set.clone(),
+		}
+
+		status.Metrics, status.Connections, status.Err = b.sender.Query(dest.slot)
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
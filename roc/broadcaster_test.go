@@ -0,0 +1,132 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBroadcaster(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+	require.NotNil(t, sender)
+
+	broadcaster := NewBroadcaster(sender)
+	require.Equal(t, sender, broadcaster.Sender())
+	require.Empty(t, broadcaster.Destinations())
+
+	frame := make([]float32, 100)
+
+	room1, err := NewEndpointSet("127.0.0.1", 10001, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	room2, err := NewEndpointSet("127.0.0.1", 20001, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	err = broadcaster.AddDestination("room1", room1)
+	require.NoError(t, err)
+
+	require.NoError(t, sender.WriteFloats(frame))
+
+	err = broadcaster.AddDestination("room2", room2)
+	require.NoError(t, err)
+
+	require.NoError(t, sender.WriteFloats(frame))
+
+	// broadcaster keeps its own copy of endpoints
+	room2.Source.Port = 30001
+
+	statuses := broadcaster.Destinations()
+	require.Len(t, statuses, 2)
+
+	require.Equal(t, "room1", statuses[0].Name)
+	require.Equal(t, Slot(0), statuses[0].Slot)
+	require.Equal(t, 10001, statuses[0].Endpoints.Source.Port)
+	require.NoError(t, statuses[0].Err)
+
+	require.Equal(t, "room2", statuses[1].Name)
+	require.Equal(t, Slot(1), statuses[1].Slot)
+	require.Equal(t, 20001, statuses[1].Endpoints.Source.Port)
+	require.NoError(t, statuses[1].Err)
+
+	slot, ok := sender.SlotByName("room2")
+	require.True(t, ok)
+	require.Equal(t, Slot(1), slot)
+
+	err = broadcaster.RemoveDestination("room1")
+	require.NoError(t, err)
+
+	require.NoError(t, sender.WriteFloats(frame))
+
+	statuses = broadcaster.Destinations()
+	require.Len(t, statuses, 1)
+	require.Equal(t, "room2", statuses[0].Name)
+
+	// slot of removed destination is reused
+	err = broadcaster.AddDestination("room3", room1)
+	require.NoError(t, err)
+
+	slot, ok = sender.SlotByName("room3")
+	require.True(t, ok)
+	require.Equal(t, Slot(0), slot)
+
+	err = sender.Close()
+	require.NoError(t, err)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestBroadcaster_Errors(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+	require.NotNil(t, sender)
+
+	broadcaster := NewBroadcaster(sender)
+
+	room, err := NewEndpointSet("127.0.0.1", 10001, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	noFecRoom, err := NewEndpointSet("127.0.0.1", 20001, FecEncodingDisable)
+	require.NoError(t, err)
+
+	err = broadcaster.AddDestination("", room)
+	require.True(t, errors.Is(err, ErrInvalidArgument))
+
+	err = broadcaster.AddDestination("room", nil)
+	require.True(t, errors.Is(err, ErrBadEndpoint))
+
+	// protocol doesn't match fec encoding of sender
+	err = broadcaster.AddDestination("room", noFecRoom)
+	require.True(t, errors.Is(err, ErrBadEndpoint))
+
+	require.Empty(t, broadcaster.Destinations())
+	require.Empty(t, sender.Slots())
+
+	err = broadcaster.AddDestination("room", room)
+	require.NoError(t, err)
+
+	err = broadcaster.AddDestination("room", room)
+	require.True(t, errors.Is(err, ErrExists))
+
+	err = broadcaster.RemoveDestination("other")
+	require.True(t, errors.Is(err, ErrNotFound))
+
+	require.Len(t, broadcaster.Destinations(), 1)
+
+	err = sender.Close()
+	require.NoError(t, err)
+
+	err = broadcaster.AddDestination("other", room)
+	require.True(t, errors.Is(err, ErrClosed))
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"flag"
+	"time"
+
+	"github.com/roc-streaming/roc-go/roc"
+)
+
+// Default frame encoding used by tools.
+var DefaultFrameEncoding = roc.MediaEncoding{
+	Rate:     44100,
+	Format:   roc.FormatPcmFloat32,
+	Channels: roc.ChannelLayoutStereo,
+}
+
+// LogLevelVar defines --log-level flag.
+func LogLevelVar(fs *flag.FlagSet, level *roc.LogLevel) {
+	EnumVar(fs, level, "log-level", "log level",
+		roc.LogNone, roc.LogError, roc.LogInfo, roc.LogDebug, roc.LogTrace)
+}
+
+// ContextFlags defines flags for all fields of roc.ContextConfig.
+func ContextFlags(fs *flag.FlagSet, config *roc.ContextConfig) {
+	Uint32Var(fs, &config.MaxPacketSize, "max-packet-size",
+		"maximum size of a network packet in bytes, 0 for default")
+	Uint32Var(fs, &config.MaxFrameSize, "max-frame-size",
+		"maximum size of a frame in bytes, 0 for default")
+}
+
+// InterfaceFlags defines flags for all fields of roc.InterfaceConfig.
+func InterfaceFlags(fs *flag.FlagSet, config *roc.InterfaceConfig) {
+	fs.StringVar(&config.OutgoingAddress, "outgoing-address", config.OutgoingAddress,
+		"IP address of network interface for outgoing packets")
+	fs.StringVar(&config.MulticastGroup, "multicast-group", config.MulticastGroup,
+		"IP address of multicast group to join")
+	fs.BoolVar(&config.ReuseAddress, "reuse-address", config.ReuseAddress,
+		"enable SO_REUSEADDR when binding socket")
+}
+
+// SenderFlags defines flags for all fields of roc.SenderConfig.
+func SenderFlags(fs *flag.FlagSet, config *roc.SenderConfig) {
+	frameEncodingFlags(fs, &config.FrameEncoding)
+
+	EnumVar(fs, &config.PacketEncoding, "packet-encoding", "encoding of network packets",
+		roc.PacketEncodingAvpL16Mono, roc.PacketEncodingAvpL16Stereo)
+	fs.DurationVar(&config.PacketLength, "packet-length", config.PacketLength,
+		"duration of samples in network packet, 0 for default")
+	fs.BoolVar(&config.PacketInterleaving, "packet-interleaving", config.PacketInterleaving,
+		"enable packet interleaving")
+
+	EnumVar(fs, &config.FecEncoding, "fec-encoding", "FEC encoding",
+		roc.FecEncodingDisable, roc.FecEncodingDefault,
+		roc.FecEncodingRs8m, roc.FecEncodingLdpcStaircase)
+	Uint32Var(fs, &config.FecBlockSourcePackets, "fec-block-source-packets",
+		"number of source packets per FEC block, 0 for default")
+	Uint32Var(fs, &config.FecBlockRepairPackets, "fec-block-repair-packets",
+		"number of repair packets per FEC block, 0 for default")
+
+	clockSourceFlag(fs, &config.ClockSource)
+	latencyFlags(fs, &config.LatencyTunerBackend, &config.LatencyTunerProfile,
+		&config.TargetLatency, &config.LatencyTolerance)
+	resamplerFlags(fs, &config.ResamplerBackend, &config.ResamplerProfile)
+}
+
+// SenderFecEncoding sets FecEncoding of config to the one implied by
+// endpoints (see Endpoints.FecEncoding), unless --fec-encoding flag was set
+// explicitly. Should be called after parsing flags defined by SenderFlags.
+func SenderFecEncoding(fs *flag.FlagSet, config *roc.SenderConfig, endpoints Endpoints) error {
+	explicit := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "fec-encoding" {
+			explicit = true
+		}
+	})
+	if explicit {
+		return nil
+	}
+
+	fecEncoding, ok, err := endpoints.FecEncoding()
+	if err != nil {
+		return err
+	}
+	if ok {
+		config.FecEncoding = fecEncoding
+	}
+
+	return nil
+}
+
+// ReceiverFlags defines flags for all fields of roc.ReceiverConfig.
+func ReceiverFlags(fs *flag.FlagSet, config *roc.ReceiverConfig) {
+	frameEncodingFlags(fs, &config.FrameEncoding)
+
+	clockSourceFlag(fs, &config.ClockSource)
+	latencyFlags(fs, &config.LatencyTunerBackend, &config.LatencyTunerProfile,
+		&config.TargetLatency, &config.LatencyTolerance)
+	resamplerFlags(fs, &config.ResamplerBackend, &config.ResamplerProfile)
+
+	EnumVar(fs, &config.PlcBackend, "plc-backend", "packet loss concealment backend",
+		roc.PlcBackendDefault, roc.PlcBackendNone)
+
+	fs.DurationVar(&config.NoPlaybackTimeout, "no-playback-timeout",
+		config.NoPlaybackTimeout,
+		"timeout for the lack of playback, 0 for default, negative to disable")
+	fs.DurationVar(&config.ChoppyPlaybackTimeout, "choppy-playback-timeout",
+		config.ChoppyPlaybackTimeout,
+		"timeout for choppy playback, 0 for default, negative to disable")
+}
+
+func frameEncodingFlags(fs *flag.FlagSet, encoding *roc.MediaEncoding) {
+	Uint32Var(fs, &encoding.Rate, "frame-rate", "sample rate of frames")
+	EnumVar(fs, &encoding.Format, "frame-format", "sample format of frames",
+		roc.FormatPcmFloat32)
+	EnumVar(fs, &encoding.Channels, "frame-channels", "channel layout of frames",
+		roc.ChannelLayoutMono, roc.ChannelLayoutStereo, roc.ChannelLayoutMultitrack)
+	Uint32Var(fs, &encoding.Tracks, "frame-tracks", "number of tracks for multitrack layout")
+}
+
+func clockSourceFlag(fs *flag.FlagSet, clockSource *roc.ClockSource) {
+	EnumVar(fs, clockSource, "clock-source", "clock source",
+		roc.ClockSourceDefault, roc.ClockSourceExternal, roc.ClockSourceInternal)
+}
+
+func latencyFlags(fs *flag.FlagSet,
+	backend *roc.LatencyTunerBackend, profile *roc.LatencyTunerProfile,
+	targetLatency *time.Duration, latencyTolerance *time.Duration,
+) {
+	EnumVar(fs, backend, "latency-tuner-backend", "latency tuner backend",
+		roc.LatencyTunerBackendDefault, roc.LatencyTunerBackendNiq)
+	EnumVar(fs, profile, "latency-tuner-profile", "latency tuner profile",
+		roc.LatencyTunerProfileDefault, roc.LatencyTunerProfileIntact,
+		roc.LatencyTunerProfileResponsive, roc.LatencyTunerProfileGradual)
+	fs.DurationVar(targetLatency, "target-latency", *targetLatency,
+		"target latency, 0 for default")
+	fs.DurationVar(latencyTolerance, "latency-tolerance", *latencyTolerance,
+		"maximum deviation from target latency, 0 for default")
+}
+
+func resamplerFlags(fs *flag.FlagSet,
+	backend *roc.ResamplerBackend, profile *roc.ResamplerProfile,
+) {
+	EnumVar(fs, backend, "resampler-backend", "resampler backend",
+		roc.ResamplerBackendDefault, roc.ResamplerBackendBuiltin,
+		roc.ResamplerBackendSpeex, roc.ResamplerBackendSpeexdec)
+	EnumVar(fs, profile, "resampler-profile", "resampler profile",
+		roc.ResamplerProfileDefault, roc.ResamplerProfileHigh,
+		roc.ResamplerProfileMedium, roc.ResamplerProfileLow)
+}
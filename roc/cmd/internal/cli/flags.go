@@ -0,0 +1,91 @@
+// Package cli contains helpers shared by roc-go-send and roc-go-recv tools.
+package cli
+
+import (
+	"encoding"
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Flag for enum types from roc package.
+//
+// Accepts names produced by MarshalText() method of enum values (or lowercase
+// String(), if enum doesn't implement encoding.TextMarshaler), e.g. "ldpc" for
+// roc.FecEncodingLdpcStaircase.
+type enumFlag struct {
+	ptr     reflect.Value
+	choices []reflect.Value
+}
+
+// EnumVar defines enum flag with given name and usage.
+// ptr should be a pointer to enum variable, choices are allowed values.
+func EnumVar(fs *flag.FlagSet, ptr interface{}, name string, usage string,
+	choices ...fmt.Stringer,
+) {
+	f := &enumFlag{
+		ptr: reflect.ValueOf(ptr).Elem(),
+	}
+
+	names := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		f.choices = append(f.choices, reflect.ValueOf(choice))
+		names = append(names, enumName(choice))
+	}
+
+	fs.Var(f, name, fmt.Sprintf("%s (%s)", usage, strings.Join(names, ", ")))
+}
+
+func (f *enumFlag) String() string {
+	if !f.ptr.IsValid() {
+		return ""
+	}
+	return enumName(f.ptr.Interface().(fmt.Stringer))
+}
+
+func (f *enumFlag) Set(value string) error {
+	for _, choice := range f.choices {
+		if enumName(choice.Interface().(fmt.Stringer)) == strings.ToLower(value) {
+			f.ptr.Set(choice)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown value %q", value)
+}
+
+func enumName(value fmt.Stringer) string {
+	if marshaler, ok := value.(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return strings.ToLower(value.String())
+}
+
+// Flag for uint32 values.
+type uint32Flag struct {
+	ptr *uint32
+}
+
+// Uint32Var defines uint32 flag with given name and usage.
+func Uint32Var(fs *flag.FlagSet, ptr *uint32, name string, usage string) {
+	fs.Var(&uint32Flag{ptr: ptr}, name, usage)
+}
+
+func (f *uint32Flag) String() string {
+	if f.ptr == nil {
+		return "0"
+	}
+	return strconv.FormatUint(uint64(*f.ptr), 10)
+}
+
+func (f *uint32Flag) Set(value string) error {
+	v, err := strconv.ParseUint(value, 0, 32)
+	if err != nil {
+		return err
+	}
+	*f.ptr = uint32(v)
+	return nil
+}
@@ -0,0 +1,377 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/roc-streaming/roc-go/roc"
+	"github.com/stretchr/testify/require"
+)
+
+func makeFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	return fs
+}
+
+func TestEnumVar(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    roc.FecEncoding
+		wantErr error
+	}{
+		{
+			name: "default",
+			args: []string{},
+			want: roc.FecEncodingRs8m,
+		},
+		{
+			name: "lowercase",
+			args: []string{"--fec", "ldpc"},
+			want: roc.FecEncodingLdpcStaircase,
+		},
+		{
+			name: "mixed case",
+			args: []string{"--fec", "Disable"},
+			want: roc.FecEncodingDisable,
+		},
+		{
+			name: "not a choice",
+			args: []string{"--fec", "default"},
+			wantErr: errors.New(
+				`invalid value "default" for flag -fec: unknown value "default"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value := roc.FecEncodingRs8m
+
+			fs := makeFlagSet()
+			EnumVar(fs, &value, "fec", "fec",
+				roc.FecEncodingDisable, roc.FecEncodingRs8m, roc.FecEncodingLdpcStaircase)
+
+			err := fs.Parse(tt.args)
+			if tt.wantErr != nil {
+				require.Equal(t, tt.wantErr.Error(), err.Error())
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, value)
+		})
+	}
+}
+
+func TestSenderFlags(t *testing.T) {
+	config := roc.SenderConfig{
+		FrameEncoding:  DefaultFrameEncoding,
+		PacketEncoding: roc.PacketEncodingAvpL16Stereo,
+	}
+
+	fs := makeFlagSet()
+	SenderFlags(fs, &config)
+
+	err := fs.Parse([]string{
+		"--frame-rate", "48000",
+		"--frame-channels", "multitrack",
+		"--frame-tracks", "4",
+		"--packet-encoding", "avp_l16_mono",
+		"--packet-length", "5ms",
+		"--packet-interleaving",
+		"--fec-encoding", "rs8m",
+		"--fec-block-source-packets", "20",
+		"--fec-block-repair-packets", "10",
+		"--clock-source", "external",
+		"--latency-tuner-backend", "niq",
+		"--latency-tuner-profile", "gradual",
+		"--resampler-backend", "speex",
+		"--resampler-profile", "low",
+		"--target-latency", "100ms",
+		"--latency-tolerance", "20ms",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, roc.SenderConfig{
+		FrameEncoding: roc.MediaEncoding{
+			Rate:     48000,
+			Format:   roc.FormatPcmFloat32,
+			Channels: roc.ChannelLayoutMultitrack,
+			Tracks:   4,
+		},
+		PacketEncoding:        roc.PacketEncodingAvpL16Mono,
+		PacketLength:          5 * time.Millisecond,
+		PacketInterleaving:    true,
+		FecEncoding:           roc.FecEncodingRs8m,
+		FecBlockSourcePackets: 20,
+		FecBlockRepairPackets: 10,
+		ClockSource:           roc.ClockSourceExternal,
+		LatencyTunerBackend:   roc.LatencyTunerBackendNiq,
+		LatencyTunerProfile:   roc.LatencyTunerProfileGradual,
+		ResamplerBackend:      roc.ResamplerBackendSpeex,
+		ResamplerProfile:      roc.ResamplerProfileLow,
+		TargetLatency:         100 * time.Millisecond,
+		LatencyTolerance:      20 * time.Millisecond,
+	}, config)
+}
+
+func TestReceiverFlags(t *testing.T) {
+	config := roc.ReceiverConfig{
+		FrameEncoding: DefaultFrameEncoding,
+	}
+
+	fs := makeFlagSet()
+	ReceiverFlags(fs, &config)
+
+	err := fs.Parse([]string{
+		"--frame-channels", "mono",
+		"--clock-source", "internal",
+		"--latency-tuner-profile", "responsive",
+		"--resampler-backend", "builtin",
+		"--resampler-profile", "high",
+		"--plc-backend", "none",
+		"--target-latency", "200ms",
+		"--latency-tolerance", "50ms",
+		"--no-playback-timeout", "-1ns",
+		"--choppy-playback-timeout", "2s",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, roc.ReceiverConfig{
+		FrameEncoding: roc.MediaEncoding{
+			Rate:     44100,
+			Format:   roc.FormatPcmFloat32,
+			Channels: roc.ChannelLayoutMono,
+		},
+		ClockSource:           roc.ClockSourceInternal,
+		LatencyTunerProfile:   roc.LatencyTunerProfileResponsive,
+		ResamplerBackend:      roc.ResamplerBackendBuiltin,
+		ResamplerProfile:      roc.ResamplerProfileHigh,
+		PlcBackend:            roc.PlcBackendNone,
+		TargetLatency:         200 * time.Millisecond,
+		LatencyTolerance:      50 * time.Millisecond,
+		NoPlaybackTimeout:     -1,
+		ChoppyPlaybackTimeout: 2 * time.Second,
+	}, config)
+}
+
+func TestContextInterfaceFlags(t *testing.T) {
+	var (
+		contextConfig   roc.ContextConfig
+		interfaceConfig roc.InterfaceConfig
+	)
+
+	fs := makeFlagSet()
+	ContextFlags(fs, &contextConfig)
+	InterfaceFlags(fs, &interfaceConfig)
+
+	err := fs.Parse([]string{
+		"--max-packet-size", "2000",
+		"--max-frame-size", "4000",
+		"--outgoing-address", "127.0.0.1",
+		"--multicast-group", "224.0.0.1",
+		"--reuse-address",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, roc.ContextConfig{
+		MaxPacketSize: 2000,
+		MaxFrameSize:  4000,
+	}, contextConfig)
+
+	require.Equal(t, roc.InterfaceConfig{
+		OutgoingAddress: "127.0.0.1",
+		MulticastGroup:  "224.0.0.1",
+		ReuseAddress:    true,
+	}, interfaceConfig)
+
+	fs = makeFlagSet()
+	ContextFlags(fs, &contextConfig)
+
+	err = fs.Parse([]string{"--max-packet-size", "-1"})
+	require.Error(t, err)
+}
+
+func TestStreamFormat(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    StreamFormat
+		wantErr error
+	}{
+		{
+			value: "wav",
+			want:  StreamFormat{Wav: true, Pcm: roc.PcmFormatS16LE},
+		},
+		{
+			value: "wav:f32le",
+			want:  StreamFormat{Wav: true, Pcm: roc.PcmFormatF32LE},
+		},
+		{
+			value: "S24LE",
+			want:  StreamFormat{Wav: false, Pcm: roc.PcmFormatS24LE},
+		},
+		{
+			value:   "u8",
+			wantErr: errors.New(`unknown format "u8"`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			var format StreamFormat
+
+			err := format.Set(tt.value)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.want, format)
+		})
+	}
+}
+
+func TestEndpoints_Each(t *testing.T) {
+	endpoints := Endpoints{
+		Source:  "rtp+rs8m://127.0.0.1:10001",
+		Control: "rtcp://127.0.0.1:10003",
+	}
+
+	var ifaces []roc.Interface
+	var uris []string
+
+	err := endpoints.Each(func(iface roc.Interface, uri string) error {
+		ifaces = append(ifaces, iface)
+		uris = append(uris, uri)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]roc.Interface{roc.InterfaceAudioSource, roc.InterfaceAudioControl}, ifaces)
+	require.Equal(t, []string{endpoints.Source, endpoints.Control}, uris)
+
+	err = endpoints.Each(func(iface roc.Interface, uri string) error {
+		return errors.New("test")
+	})
+	require.Equal(t, "AudioSource: test", err.Error())
+}
+
+func TestEndpoints_Stream(t *testing.T) {
+	endpoints := Endpoints{
+		Stream: "roc://127.0.0.1:10001?fec=ldpc",
+	}
+	require.NoError(t, endpoints.Validate())
+
+	var ifaces []roc.Interface
+	var uris []string
+
+	err := endpoints.Each(func(iface roc.Interface, uri string) error {
+		ifaces = append(ifaces, iface)
+		uris = append(uris, uri)
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t,
+		[]roc.Interface{
+			roc.InterfaceAudioSource, roc.InterfaceAudioRepair, roc.InterfaceAudioControl,
+		}, ifaces)
+	require.Equal(t,
+		[]string{
+			"rtp+ldpc://127.0.0.1:10001", "ldpc://127.0.0.1:10002", "rtcp://127.0.0.1:10003",
+		}, uris)
+
+	err = Endpoints{Stream: "roc://127.0.0.1:10001?fec=foo"}.Each(
+		func(iface roc.Interface, uri string) error {
+			return nil
+		})
+	require.Error(t, err)
+}
+
+func TestEndpoints_Validate(t *testing.T) {
+	require.NoError(t, Endpoints{Source: "rtp://127.0.0.1:10001"}.Validate())
+	require.NoError(t, Endpoints{Stream: "roc://127.0.0.1:10001"}.Validate())
+
+	require.Error(t, Endpoints{}.Validate())
+	require.Error(t, Endpoints{Repair: "rs8m://127.0.0.1:10002"}.Validate())
+	require.Error(t, Endpoints{
+		Source: "rtp://127.0.0.1:10001",
+		Stream: "roc://127.0.0.1:10001",
+	}.Validate())
+}
+
+func TestSenderFecEncoding(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want roc.FecEncoding
+	}{
+		{
+			name: "stream ldpc",
+			args: []string{"--stream", "roc://127.0.0.1:10001?fec=ldpc"},
+			want: roc.FecEncodingLdpcStaircase,
+		},
+		{
+			name: "stream disable",
+			args: []string{"--stream", "roc://127.0.0.1:10001?fec=disable"},
+			want: roc.FecEncodingDisable,
+		},
+		{
+			name: "stream default",
+			args: []string{"--stream", "roc://127.0.0.1:10001"},
+			want: roc.FecEncodingRs8m,
+		},
+		{
+			name: "source ldpc",
+			args: []string{"--source", "rtp+ldpc://127.0.0.1:10001"},
+			want: roc.FecEncodingLdpcStaircase,
+		},
+		{
+			name: "source without fec",
+			args: []string{"--source", "rtp://127.0.0.1:10001"},
+			want: roc.FecEncodingDisable,
+		},
+		{
+			name: "explicit flag",
+			args: []string{
+				"--stream", "roc://127.0.0.1:10001?fec=ldpc", "--fec-encoding", "rs8m",
+			},
+			want: roc.FecEncodingRs8m,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var endpoints Endpoints
+			config := roc.SenderConfig{
+				FrameEncoding:  DefaultFrameEncoding,
+				PacketEncoding: roc.PacketEncodingAvpL16Stereo,
+			}
+
+			fs := makeFlagSet()
+			EndpointFlags(fs, &endpoints, "remote")
+			SenderFlags(fs, &config)
+
+			require.NoError(t, fs.Parse(tt.args))
+			require.NoError(t, SenderFecEncoding(fs, &config, endpoints))
+			require.Equal(t, tt.want, config.FecEncoding)
+		})
+	}
+
+	err := SenderFecEncoding(makeFlagSet(), &roc.SenderConfig{},
+		Endpoints{Stream: "roc://127.0.0.1:10001?fec=foo"})
+	require.Error(t, err)
+}
+
+func TestOpenOutput_Stdout(t *testing.T) {
+	out, err := OpenOutput("-")
+	require.NoError(t, err)
+
+	// needed to update WAV header when stdout is redirected to a file
+	_, ok := out.(io.Seeker)
+	require.True(t, ok)
+
+	require.NoError(t, out.Close())
+}
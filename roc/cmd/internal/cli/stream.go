@@ -0,0 +1,234 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/roc-streaming/roc-go/roc"
+)
+
+// StreamFormat defines format of audio stream read from input or written to
+// output: either WAV file, or raw interleaved PCM samples.
+type StreamFormat struct {
+	// If true, stream is a WAV file, and sample format is taken from
+	// (or written to) WAV header.
+	Wav bool
+
+	// Sample format of raw stream, or of WAV file being written.
+	Pcm roc.PcmFormat
+}
+
+// StreamFormatVar defines flag for stream format.
+func StreamFormatVar(fs *flag.FlagSet, format *StreamFormat, name string, usage string) {
+	fs.Var(format, name, usage+" (wav, s16le, s24le, s32le, f32le)")
+}
+
+func (f *StreamFormat) String() string {
+	if f.Wav {
+		return "wav"
+	}
+	if f.Pcm == 0 {
+		return ""
+	}
+	return strings.ToLower(f.Pcm.String())
+}
+
+// Set parses stream format.
+// "wav" selects WAV file with s16le samples, "wav:<pcm>" selects WAV file
+// with given samples, and "<pcm>" selects raw samples.
+func (f *StreamFormat) Set(value string) error {
+	value = strings.ToLower(value)
+
+	wav := false
+	if value == "wav" {
+		*f = StreamFormat{Wav: true, Pcm: roc.PcmFormatS16LE}
+		return nil
+	}
+	if strings.HasPrefix(value, "wav:") {
+		wav = true
+		value = strings.TrimPrefix(value, "wav:")
+	}
+
+	for _, pcm := range []roc.PcmFormat{
+		roc.PcmFormatS16LE, roc.PcmFormatS24LE, roc.PcmFormatS32LE, roc.PcmFormatF32LE,
+	} {
+		if strings.ToLower(pcm.String()) == value {
+			*f = StreamFormat{Wav: wav, Pcm: pcm}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("unknown format %q", value)
+}
+
+// OpenInput opens file for reading, or returns stdin if path is "-".
+func OpenInput(path string) (io.ReadCloser, error) {
+	if path == "-" {
+		return ioutil.NopCloser(os.Stdin), nil
+	}
+	return os.Open(path)
+}
+
+// OpenOutput creates file for writing, or returns stdout if path is "-".
+//
+// Stdout is returned as *os.File with no-op Close(), so that WAV header can
+// still be updated if stdout is redirected to a regular file.
+func OpenOutput(path string) (io.WriteCloser, error) {
+	if path == "-" {
+		return stdoutFile{os.Stdout}, nil
+	}
+	return os.Create(path)
+}
+
+// keeps Seek() and other methods of *os.File, but doesn't close it
+type stdoutFile struct {
+	*os.File
+}
+
+func (stdoutFile) Close() error {
+	return nil
+}
+
+// SignalContext returns context that is cancelled on SIGINT or SIGTERM.
+func SignalContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-sigCh:
+			cancel()
+		case <-ctx.Done():
+		}
+		signal.Stop(sigCh)
+	}()
+
+	return ctx, cancel
+}
+
+// Endpoints holds URIs of source, repair, and control endpoints, or compact
+// "roc://" URI of the whole stream (see roc.ParseEndpointSet).
+type Endpoints struct {
+	Source  string
+	Repair  string
+	Control string
+	Stream  string
+}
+
+// EndpointFlags defines --source, --repair, --control, and --stream flags.
+func EndpointFlags(fs *flag.FlagSet, endpoints *Endpoints, verb string) {
+	fs.StringVar(&endpoints.Source, "source", "",
+		verb+" source (audio) endpoint, e.g. rtp+rs8m://127.0.0.1:10001")
+	fs.StringVar(&endpoints.Repair, "repair", "",
+		verb+" repair (FEC) endpoint, e.g. rs8m://127.0.0.1:10002")
+	fs.StringVar(&endpoints.Control, "control", "",
+		verb+" control endpoint, e.g. rtcp://127.0.0.1:10003")
+	fs.StringVar(&endpoints.Stream, "stream", "",
+		verb+" source, repair, and control endpoints in compact form,"+
+			" e.g. roc://127.0.0.1:10001?fec=rs8m (replaces --source, --repair, --control)")
+}
+
+// Validate checks that either --source or --stream is specified, but not both.
+func (e Endpoints) Validate() error {
+	if e.Stream != "" {
+		if e.Source != "" || e.Repair != "" || e.Control != "" {
+			return errors.New("--stream can't be used together with --source, --repair, --control")
+		}
+		return nil
+	}
+	if e.Source == "" {
+		return errors.New("--source or --stream is required")
+	}
+	return nil
+}
+
+// Each calls fn for every non-empty endpoint, with its interface.
+// Compact stream URI is expanded into individual endpoints.
+func (e Endpoints) Each(fn func(iface roc.Interface, uri string) error) error {
+	if e.Stream != "" {
+		expanded, err := e.expandStream()
+		if err != nil {
+			return err
+		}
+		return expanded.Each(fn)
+	}
+
+	for _, item := range []struct {
+		iface roc.Interface
+		uri   string
+	}{
+		{roc.InterfaceAudioSource, e.Source},
+		{roc.InterfaceAudioRepair, e.Repair},
+		{roc.InterfaceAudioControl, e.Control},
+	} {
+		if item.uri == "" {
+			continue
+		}
+		if err := fn(item.iface, item.uri); err != nil {
+			return fmt.Errorf("%v: %w", item.iface, err)
+		}
+	}
+	return nil
+}
+
+// FecEncoding returns FEC encoding implied by protocol of source endpoint,
+// e.g. roc.FecEncodingLdpcStaircase for "rtp+ldpc://" source or for
+// "roc://" stream with "fec=ldpc", and roc.FecEncodingDisable for "rtp://"
+// source. Returns false if source endpoint is not specified.
+func (e Endpoints) FecEncoding() (roc.FecEncoding, bool, error) {
+	if e.Stream != "" {
+		expanded, err := e.expandStream()
+		if err != nil {
+			return 0, false, err
+		}
+		return expanded.FecEncoding()
+	}
+
+	if e.Source == "" {
+		return 0, false, nil
+	}
+
+	endpoint, err := roc.ParseEndpoint(e.Source)
+	if err != nil {
+		return 0, false, fmt.Errorf("%v: %w", roc.InterfaceAudioSource, err)
+	}
+
+	fecEncoding, ok := endpoint.Protocol.FecEncoding()
+	return fecEncoding, ok, nil
+}
+
+func (e Endpoints) expandStream() (Endpoints, error) {
+	set, err := roc.ParseEndpointSet(e.Stream)
+	if err != nil {
+		return Endpoints{}, fmt.Errorf("--stream: %w", err)
+	}
+
+	var expanded Endpoints
+	for _, item := range []struct {
+		endpoint *roc.Endpoint
+		uri      *string
+	}{
+		{set.Source, &expanded.Source},
+		{set.Repair, &expanded.Repair},
+		{set.Control, &expanded.Control},
+	} {
+		if item.endpoint == nil {
+			continue
+		}
+		if *item.uri, err = item.endpoint.URI(); err != nil {
+			return Endpoints{}, fmt.Errorf("--stream: %w", err)
+		}
+	}
+
+	return expanded, nil
+}
@@ -0,0 +1,149 @@
+// Command roc-go-recv receives audio stream from remote sender using
+// roc.Receiver and writes it to a file or stdout.
+//
+// Usage:
+//
+//	roc-go-recv --source rtp+rs8m://0.0.0.0:PORT --repair rs8m://0.0.0.0:PORT \
+//	    --control rtcp://0.0.0.0:PORT [options] > output.wav
+//
+// or, using compact form of the same endpoints:
+//
+//	roc-go-recv --stream roc://0.0.0.0:PORT [options] > output.wav
+//
+// Output can be a WAV file (--output-format wav or wav:s24le, etc.) or raw
+// interleaved PCM samples (--output-format s16le, etc.). If output is a
+// regular file, WAV header is updated when recording is finished. Run with
+// --help for the list of options.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/roc-streaming/roc-go/roc"
+	"github.com/roc-streaming/roc-go/roc/cmd/internal/cli"
+	"github.com/roc-streaming/roc-go/roc/wav"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "roc-go-recv: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var (
+		endpoints       cli.Endpoints
+		output          = "-"
+		outputFormat    = cli.StreamFormat{Wav: true, Pcm: roc.PcmFormatS16LE}
+		duration        time.Duration
+		logLevel        = roc.LogError
+		contextConfig   roc.ContextConfig
+		interfaceConfig roc.InterfaceConfig
+		receiverConfig  = roc.ReceiverConfig{
+			FrameEncoding: cli.DefaultFrameEncoding,
+		}
+	)
+
+	fs := flag.NewFlagSet("roc-go-recv", flag.ContinueOnError)
+
+	cli.EndpointFlags(fs, &endpoints, "local")
+	fs.StringVar(&output, "output", output, "output file path, \"-\" for stdout")
+	cli.StreamFormatVar(fs, &outputFormat, "output-format", "output format")
+	fs.DurationVar(&duration, "duration", duration,
+		"stop after given duration, 0 to run until interrupted")
+	cli.LogLevelVar(fs, &logLevel)
+	cli.ContextFlags(fs, &contextConfig)
+	cli.InterfaceFlags(fs, &interfaceConfig)
+	cli.ReceiverFlags(fs, &receiverConfig)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if err := endpoints.Validate(); err != nil {
+		return err
+	}
+
+	roc.SetLogLevel(logLevel)
+
+	ctx, cancel := cli.SignalContext()
+	defer cancel()
+
+	rocContext, err := roc.OpenContext(contextConfig)
+	if err != nil {
+		return err
+	}
+	defer rocContext.Close()
+
+	receiver, err := roc.OpenReceiver(rocContext, receiverConfig)
+	if err != nil {
+		return err
+	}
+	defer receiver.Close()
+
+	err = endpoints.Each(func(iface roc.Interface, uri string) error {
+		if interfaceConfig != (roc.InterfaceConfig{}) {
+			if err := receiver.Configure(roc.SlotDefault, iface, interfaceConfig); err != nil {
+				return err
+			}
+		}
+
+		endpoint, err := roc.ParseEndpoint(uri)
+		if err != nil {
+			return err
+		}
+
+		if err := receiver.Bind(roc.SlotDefault, iface, endpoint); err != nil {
+			return err
+		}
+
+		// port may be chosen by receiver
+		if boundURI, err := endpoint.URI(); err == nil {
+			fmt.Fprintf(os.Stderr, "roc-go-recv: bound %v to %s\n", iface, boundURI)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	out, err := cli.OpenOutput(output)
+	if err != nil {
+		return err
+	}
+
+	err = record(ctx, receiver, receiverConfig.FrameEncoding, out, outputFormat, duration)
+	if err == context.Canceled {
+		err = nil
+	}
+
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+
+	return err
+}
+
+func record(ctx context.Context, receiver *roc.Receiver, encoding roc.MediaEncoding,
+	out io.Writer, format cli.StreamFormat, duration time.Duration,
+) error {
+	if format.Wav {
+		return wav.Record(ctx, receiver, encoding, out, format.Pcm, duration)
+	}
+
+	return wav.RecordRaw(ctx, receiver, encoding, out, format.Pcm, duration)
+}
@@ -0,0 +1,136 @@
+// Command roc-go-send reads audio stream from a file or stdin and sends it to
+// remote receiver using roc.Sender.
+//
+// Usage:
+//
+//	roc-go-send --source rtp+rs8m://HOST:PORT --repair rs8m://HOST:PORT \
+//	    --control rtcp://HOST:PORT [options] < input.wav
+//
+// or, using compact form of the same endpoints:
+//
+//	roc-go-send --stream roc://HOST:PORT [options] < input.wav
+//
+// Input can be a WAV file (--input-format wav) or raw interleaved PCM samples
+// (--input-format s16le, etc.). Run with --help for the list of options.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/roc-streaming/roc-go/roc"
+	"github.com/roc-streaming/roc-go/roc/cmd/internal/cli"
+	"github.com/roc-streaming/roc-go/roc/wav"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(0)
+		}
+		fmt.Fprintf(os.Stderr, "roc-go-send: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string) error {
+	var (
+		endpoints       cli.Endpoints
+		input           = "-"
+		inputFormat     = cli.StreamFormat{Wav: true}
+		logLevel        = roc.LogError
+		contextConfig   roc.ContextConfig
+		interfaceConfig roc.InterfaceConfig
+		senderConfig    = roc.SenderConfig{
+			FrameEncoding:  cli.DefaultFrameEncoding,
+			PacketEncoding: roc.PacketEncodingAvpL16Stereo,
+		}
+	)
+
+	fs := flag.NewFlagSet("roc-go-send", flag.ContinueOnError)
+
+	cli.EndpointFlags(fs, &endpoints, "remote")
+	fs.StringVar(&input, "input", input, "input file path, \"-\" for stdin")
+	cli.StreamFormatVar(fs, &inputFormat, "input-format", "input format")
+	cli.LogLevelVar(fs, &logLevel)
+	cli.ContextFlags(fs, &contextConfig)
+	cli.InterfaceFlags(fs, &interfaceConfig)
+	cli.SenderFlags(fs, &senderConfig)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
+
+	if err := endpoints.Validate(); err != nil {
+		return err
+	}
+
+	if err := cli.SenderFecEncoding(fs, &senderConfig, endpoints); err != nil {
+		return err
+	}
+
+	roc.SetLogLevel(logLevel)
+
+	ctx, cancel := cli.SignalContext()
+	defer cancel()
+
+	rocContext, err := roc.OpenContext(contextConfig)
+	if err != nil {
+		return err
+	}
+	defer rocContext.Close()
+
+	sender, err := roc.OpenSender(rocContext, senderConfig)
+	if err != nil {
+		return err
+	}
+	defer sender.Close()
+
+	err = endpoints.Each(func(iface roc.Interface, uri string) error {
+		if interfaceConfig != (roc.InterfaceConfig{}) {
+			if err := sender.Configure(roc.SlotDefault, iface, interfaceConfig); err != nil {
+				return err
+			}
+		}
+
+		endpoint, err := roc.ParseEndpoint(uri)
+		if err != nil {
+			return err
+		}
+
+		return sender.Connect(roc.SlotDefault, iface, endpoint)
+	})
+	if err != nil {
+		return err
+	}
+
+	in, err := cli.OpenInput(input)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	err = send(ctx, sender, senderConfig.FrameEncoding, in, inputFormat)
+	if err == context.Canceled {
+		return nil
+	}
+
+	return err
+}
+
+func send(ctx context.Context, sender *roc.Sender, encoding roc.MediaEncoding,
+	in io.Reader, format cli.StreamFormat,
+) error {
+	if format.Wav {
+		return wav.Send(ctx, sender, encoding, in)
+	}
+
+	return wav.SendRaw(ctx, sender, encoding, in, format.Pcm)
+}
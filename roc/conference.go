@@ -0,0 +1,601 @@
+package roc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Default duration of frame processed by Conference.Step().
+const defaultConferenceFrameLength = 10 * time.Millisecond
+
+// Default level above which participant is considered speaking.
+const defaultConferenceSpeakerThreshold = 0.01
+
+// Default hold-off before active speaker is switched.
+const defaultConferenceSpeakerHoldOff = 500 * time.Millisecond
+
+// Conference configuration.
+//
+// See OpenConference().
+type ConferenceConfig struct {
+	// Encoding of frames used for mixing.
+	//
+	// Overrides FrameEncoding of ReceiverConfig and SenderConfig. Should have
+	// FormatPcmFloat32 format, non-zero rate, and mono or stereo channel layout.
+	FrameEncoding MediaEncoding
+
+	// Config of participant receivers.
+	//
+	// FrameEncoding and ClockSource are overridden by conference.
+	ReceiverConfig ReceiverConfig
+
+	// Config of participant senders.
+	//
+	// FrameEncoding and ClockSource are overridden by conference.
+	SenderConfig SenderConfig
+
+	// Duration of frame processed by a single Conference.Step().
+	//
+	// If zero, default value is used.
+	FrameLength time.Duration
+
+	// RMS level of frame above which participant is considered speaking.
+	//
+	// If zero, default value is used.
+	SpeakerThreshold float32
+
+	// How long active speaker should be silent before another participant
+	// can become active speaker.
+	//
+	// If zero, default value is used. If negative, active speaker is switched
+	// immediately to the loudest participant.
+	SpeakerHoldOff time.Duration
+}
+
+// Status of a conference participant.
+//
+// See Conference.Participants().
+type ParticipantStatus struct {
+	// Participant name passed to Conference.Join().
+	Name string
+
+	// Gain applied to participant uplink before mixing.
+	Gain float32
+
+	// RMS level of participant uplink during the last step.
+	Level float32
+
+	// True if Level is above ConferenceConfig.SpeakerThreshold.
+	Speaking bool
+
+	// Endpoints to which participant receiver is bound. If ports were zero in
+	// Conference.Join(), contains actually bound ports.
+	Uplink *EndpointSet
+
+	// Endpoints to which participant sender is connected.
+	Downlink *EndpointSet
+}
+
+// N-way conference bridge with mix-minus.
+//
+// For every participant, conference owns a Receiver for participant uplink and
+// a Sender for participant downlink. On every step, it reads a frame from every
+// receiver, and sends to every participant the mix of all other participants
+// (mix-minus), so that participants don't hear themselves.
+//
+// Participants can join and leave at any time. Each participant has a gain,
+// applied to its uplink before mixing. Conference also tracks RMS level of
+// every uplink and detects active speaker.
+//
+// # Clock
+//
+// Receivers and senders of participants use ClockSourceExternal, and
+// conference is driven either by Conference.Run(), which invokes
+// Conference.Step() on a timer every ConferenceConfig.FrameLength, or by the
+// user invoking Conference.Step() at the right pace.
+//
+// # Thread safety
+//
+// Can be used concurrently. Steps are serialized.
+type Conference struct {
+	stepMu       sync.Mutex
+	mu           sync.Mutex
+	context      *Context
+	config       ConferenceConfig
+	frameLen     int
+	participants map[string]*confParticipant
+	speaker      confSpeakerDetector
+	closed       bool
+
+	// reused on every step, guarded by stepMu
+	stepParts   []*confParticipant
+	stepGains   []float32
+	stepInputs  [][]float32
+	stepOutputs [][]float32
+	stepTotal   []float32
+	stepLevels  map[string]float32
+}
+
+type confParticipant struct {
+	name     string
+	receiver *Receiver
+	sender   *Sender
+	uplink   *EndpointSet
+	downlink *EndpointSet
+	gain     float32
+	level    float32
+	in       []float32
+	out      []float32
+}
+
+// Open a new conference.
+//
+// Validates config and applies defaults. Conference is attached to the context
+// and uses it to open receivers and senders of participants. The user should
+// not close the context until the conference is closed.
+func OpenConference(context *Context, config ConferenceConfig) (conf *Conference, err error) {
+	logWrite(LogDebug, "entering OpenConference(): context=%p config=%+v", context, config)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving OpenConference(): context=%p conference=%p err=%#v", context, conf, err,
+		)
+	}()
+
+	if context == nil {
+		return nil, ErrNilContext
+	}
+
+	if err := mixerCheckEncoding(config.FrameEncoding); err != nil {
+		return nil, err
+	}
+
+	config.ReceiverConfig.FrameEncoding = config.FrameEncoding
+	config.ReceiverConfig.ClockSource = ClockSourceExternal
+	if err := config.ReceiverConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	config.SenderConfig.FrameEncoding = config.FrameEncoding
+	config.SenderConfig.ClockSource = ClockSourceExternal
+	if err := config.SenderConfig.Validate(); err != nil {
+		return nil, err
+	}
+
+	if config.FrameLength == 0 {
+		config.FrameLength = defaultConferenceFrameLength
+	}
+	if config.FrameLength < 0 {
+		return nil, newErr(ErrInvalidConfig,
+			fmt.Sprintf("frame length should be positive, got %v", config.FrameLength))
+	}
+
+	frameLen := config.FrameEncoding.durationLen(config.FrameLength)
+	if frameLen == 0 {
+		return nil, newErr(ErrInvalidConfig,
+			fmt.Sprintf("frame length %v is too small", config.FrameLength))
+	}
+
+	if config.SpeakerThreshold == 0 {
+		config.SpeakerThreshold = defaultConferenceSpeakerThreshold
+	}
+	if config.SpeakerHoldOff == 0 {
+		config.SpeakerHoldOff = defaultConferenceSpeakerHoldOff
+	}
+
+	conf = &Conference{
+		context:      context,
+		config:       config,
+		frameLen:     frameLen,
+		participants: make(map[string]*confParticipant),
+		stepTotal:    make([]float32, frameLen*config.FrameEncoding.channelCount()),
+		stepLevels:   make(map[string]float32),
+		speaker: confSpeakerDetector{
+			threshold: config.SpeakerThreshold,
+			holdOff:   config.FrameEncoding.durationLen(config.SpeakerHoldOff),
+			silentLen: make(map[string]int),
+		},
+	}
+
+	return conf, nil
+}
+
+// Add participant to conference.
+//
+// Opens a receiver and binds it to uplink endpoints, and opens a sender and
+// connects it to downlink endpoints. Endpoints are bound and connected to
+// SlotDefault using Receiver.BindSet() and Sender.ConnectSet(). If uplink
+// ports are zero, receiver is bound to randomly chosen ports, which are
+// reported by Conference.Participants().
+//
+// name should be non-empty and unique among participants. New participant has
+// gain 1. If any operation fails, participant is not added.
+//
+// Returns ErrInvalidArgument if name is empty, and ErrExists if participant
+// with this name already exists. Receiver and sender are opened without
+// blocking other operations on the conference.
+func (c *Conference) Join(name string, uplink, downlink *EndpointSet) (err error) {
+	logWrite(LogDebug,
+		"entering Conference.Join(): conference=%p name=%q uplink=%+v downlink=%+v",
+		c, name, uplink, downlink,
+	)
+	defer func() {
+		logWrite(LogDebug, "leaving Conference.Join(): conference=%p err=%#v", c, err)
+	}()
+
+	if name == "" {
+		return newErr(ErrInvalidArgument, "participant name is empty")
+	}
+
+	// fail fast before opening anything; checked again below, because
+	// conference may change while lock is released
+	c.mu.Lock()
+	err = c.checkJoinLocked(name)
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	receiver, err := OpenReceiver(c.context, c.config.ReceiverConfig)
+	if err != nil {
+		return err
+	}
+
+	sender, err := OpenSender(c.context, c.config.SenderConfig)
+	if err != nil {
+		_ = receiver.Close()
+		return err
+	}
+
+	part := &confParticipant{
+		name:     name,
+		receiver: receiver,
+		sender:   sender,
+		gain:     1,
+	}
+
+	if err := receiver.BindSet(SlotDefault, uplink); err != nil {
+		_ = part.close()
+		return err
+	}
+
+	if err := sender.ConnectSet(SlotDefault, downlink); err != nil {
+		_ = part.close()
+		return err
+	}
+
+	part.uplink = uplink.clone()
+	part.downlink = downlink.clone()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.checkJoinLocked(name); err != nil {
+		_ = part.close()
+		return err
+	}
+
+	c.participants[name] = part
+
+	return nil
+}
+
+// Remove participant from conference.
+//
+// Closes receiver and sender of the participant. Waits until the step in
+// progress, if any, is finished. Returns ErrNotFound if there is no
+// participant with given name.
+func (c *Conference) Leave(name string) (err error) {
+	logWrite(LogDebug, "entering Conference.Leave(): conference=%p name=%q", c, name)
+	defer func() {
+		logWrite(LogDebug, "leaving Conference.Leave(): conference=%p err=%#v", c, err)
+	}()
+
+	c.stepMu.Lock()
+	defer c.stepMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	part, ok := c.participants[name]
+	if !ok {
+		return newErr(ErrNotFound, fmt.Sprintf("participant %q not found", name))
+	}
+
+	delete(c.participants, name)
+	c.speaker.remove(name)
+
+	return part.close()
+}
+
+// Set gain of participant.
+//
+// Participant uplink is multiplied by gain before it's mixed into downlinks of
+// other participants. Gain 1 keeps samples unchanged. Takes effect on the next
+// step. Returns ErrNotFound if there is no participant with given name.
+func (c *Conference) SetGain(name string, gain float32) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	part, ok := c.participants[name]
+	if !ok {
+		return newErr(ErrNotFound, fmt.Sprintf("participant %q not found", name))
+	}
+
+	part.gain = gain
+
+	return nil
+}
+
+// Get status of all participants.
+//
+// Returns participants sorted by name. Levels are updated on every step.
+//
+// Returned values are copies and can be freely modified.
+func (c *Conference) Participants() []ParticipantStatus {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	statuses := make([]ParticipantStatus, 0, len(c.participants))
+	for _, part := range c.participants {
+		statuses = append(statuses, ParticipantStatus{
+			Name:     part.name,
+			Gain:     part.gain,
+			Level:    part.level,
+			Speaking: part.level >= c.config.SpeakerThreshold,
+			Uplink:   part.uplink.clone(),
+			Downlink: part.downlink.clone(),
+		})
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Name < statuses[j].Name
+	})
+
+	return statuses
+}
+
+// Get name of active speaker.
+//
+// Active speaker is the loudest speaking participant. It remains active while
+// it speaks, and during ConferenceConfig.SpeakerHoldOff after it becomes
+// silent, even if other participants are louder. Returns false if nobody has
+// spoken yet, or if active speaker left the conference.
+func (c *Conference) ActiveSpeaker() (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.speaker.active, c.speaker.active != ""
+}
+
+// Process one frame.
+//
+// Reads a frame of ConferenceConfig.FrameLength from every participant
+// receiver, mixes frames, and writes to every participant sender the mix of
+// all other participants. Then updates levels and active speaker.
+//
+// If reading or writing fails for some participant, the error is logged, the
+// participant is treated as silent, and other participants are still
+// processed.
+func (c *Conference) Step() error {
+	c.stepMu.Lock()
+	defer c.stepMu.Unlock()
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return newErr(ErrClosed, "conference is closed")
+	}
+	parts := c.stepParts[:0]
+	gains := c.stepGains[:0]
+	for _, part := range c.participants {
+		parts = append(parts, part)
+		gains = append(gains, part.gain)
+	}
+	c.mu.Unlock()
+
+	numChans := c.config.FrameEncoding.channelCount()
+	bufLen := c.frameLen * numChans
+
+	inputs := c.stepInputs[:0]
+	outputs := c.stepOutputs[:0]
+
+	for _, part := range parts {
+		if len(part.in) != bufLen {
+			part.in = make([]float32, bufLen)
+			part.out = make([]float32, bufLen)
+		}
+		inputs = append(inputs, part.in)
+		outputs = append(outputs, part.out)
+
+		if err := part.receiver.ReadFloats(part.in); err != nil {
+			logWrite(LogError,
+				"conference: can't read from participant %q: %v", part.name, err)
+			for i := range part.in {
+				part.in[i] = 0
+			}
+		}
+	}
+
+	confMixMinus(inputs, gains, outputs, c.stepTotal, numChans)
+
+	for n, part := range parts {
+		if err := part.sender.WriteFloats(outputs[n]); err != nil {
+			logWrite(LogError,
+				"conference: can't write to participant %q: %v", part.name, err)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	levels := c.stepLevels
+	for name := range levels {
+		delete(levels, name)
+	}
+	for n, part := range parts {
+		part.level = confLevel(inputs[n])
+		levels[part.name] = part.level
+	}
+
+	c.speaker.update(levels, c.frameLen)
+
+	// keep buffers for next step, but don't hold participants that may leave
+	for n := range parts {
+		parts[n] = nil
+	}
+	c.stepParts = parts
+	c.stepGains = gains
+	c.stepInputs = inputs
+	c.stepOutputs = outputs
+
+	return nil
+}
+
+// Run conference until ctx is cancelled or conference is closed.
+//
+// Invokes Conference.Step() every ConferenceConfig.FrameLength. Returns
+// ctx.Err() if ctx was cancelled, or ErrClosed if conference was closed.
+func (c *Conference) Run(ctx context.Context) error {
+	ticker := time.NewTicker(c.config.FrameLength)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if err := c.Step(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close the conference.
+//
+// Closes receivers and senders of all participants. If closing some of them
+// fails, others are still closed, and the first error is returned.
+func (c *Conference) Close() (err error) {
+	logWrite(LogDebug, "entering Conference.Close(): conference=%p", c)
+	defer func() {
+		logWrite(LogDebug, "leaving Conference.Close(): conference=%p err=%#v", c, err)
+	}()
+
+	c.stepMu.Lock()
+	defer c.stepMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for name, part := range c.participants {
+		if closeErr := part.close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+		delete(c.participants, name)
+	}
+
+	c.closed = true
+
+	return err
+}
+
+// should be called with c.mu locked
+func (c *Conference) checkJoinLocked(name string) error {
+	if c.closed {
+		return newErr(ErrClosed, "conference is closed")
+	}
+	if _, ok := c.participants[name]; ok {
+		return newErr(ErrExists, fmt.Sprintf("participant %q already exists", name))
+	}
+	return nil
+}
+
+func (part *confParticipant) close() error {
+	err := part.sender.Close()
+	if closeErr := part.receiver.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writes to every output the sum of all inputs except the one with the same
+// index, multiplied by their gains, and applies limiter to the result;
+// total is a scratch buffer of the same size as inputs
+func confMixMinus(
+	inputs [][]float32, gains []float32, outputs [][]float32, total []float32, numChans int,
+) {
+	if len(inputs) == 0 {
+		return
+	}
+
+	for i := range total {
+		total[i] = 0
+	}
+	for n := range inputs {
+		mixerAdd(total, numChans, inputs[n], numChans, gains[n])
+	}
+
+	for n := range outputs {
+		copy(outputs[n], total)
+		mixerAdd(outputs[n], numChans, inputs[n], numChans, -gains[n])
+		mixerLimit(outputs[n])
+	}
+}
+
+// returns RMS level of frame
+func confLevel(frame []float32) float32 {
+	if len(frame) == 0 {
+		return 0
+	}
+
+	var sum float64
+	for _, s := range frame {
+		sum += float64(s) * float64(s)
+	}
+
+	return float32(math.Sqrt(sum / float64(len(frame))))
+}
+
+// tracks active speaker; lengths are in samples per channel
+type confSpeakerDetector struct {
+	threshold float32
+	holdOff   int
+	active    string
+	silentLen map[string]int
+}
+
+// updates speaker state after a frame of frameLen samples per channel
+func (d *confSpeakerDetector) update(levels map[string]float32, frameLen int) {
+	loudest := ""
+	for name, level := range levels {
+		if level < d.threshold {
+			d.silentLen[name] += frameLen
+			continue
+		}
+		d.silentLen[name] = 0
+		if loudest == "" || level > levels[loudest] ||
+			(level == levels[loudest] && name < loudest) {
+			loudest = name
+		}
+	}
+
+	if loudest == "" || loudest == d.active {
+		return
+	}
+
+	if d.active == "" || d.silentLen[d.active] >= d.holdOff {
+		d.active = loudest
+	}
+}
+
+// forgets participant that left conference
+func (d *confSpeakerDetector) remove(name string) {
+	delete(d.silentLen, name)
+	if d.active == name {
+		d.active = ""
+	}
+}
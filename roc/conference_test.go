@@ -0,0 +1,268 @@
+package roc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func makeConferenceConfig() ConferenceConfig {
+	return ConferenceConfig{
+		FrameEncoding: makeMediaEncoding(),
+		SenderConfig: SenderConfig{
+			PacketEncoding: PacketEncodingAvpL16Stereo,
+		},
+	}
+}
+
+func TestConference_MixMinus(t *testing.T) {
+	inputs := [][]float32{
+		{0.1, 0.2},
+		{0.3, -0.4},
+		{0.5, 0.9},
+	}
+	gains := []float32{1, 0.5, 1}
+	outputs := [][]float32{
+		make([]float32, 2),
+		make([]float32, 2),
+		make([]float32, 2),
+	}
+
+	total := make([]float32, 2)
+
+	confMixMinus(inputs, gains, outputs, total, 1)
+
+	require.InDeltaSlice(t, []float32{0.65, 0.7}, outputs[0], 1e-6)
+	// 1.1 is soft-limited
+	require.InDeltaSlice(t, []float32{0.6, 0.98103}, outputs[1], 1e-5)
+	require.InDeltaSlice(t, []float32{0.25, 0}, outputs[2], 1e-6)
+
+	// single participant hears silence
+	outputs = [][]float32{make([]float32, 2)}
+	confMixMinus(inputs[:1], gains[:1], outputs, total, 1)
+	require.InDeltaSlice(t, []float32{0, 0}, outputs[0], 1e-6)
+
+	// no participants
+	confMixMinus(nil, nil, nil, nil, 1)
+}
+
+func TestConference_Level(t *testing.T) {
+	require.Equal(t, float32(0), confLevel(nil))
+	require.Equal(t, float32(0), confLevel([]float32{0, 0}))
+	require.InDelta(t, 0.5, confLevel([]float32{0.5, -0.5, 0.5, -0.5}), 1e-6)
+}
+
+func TestConference_SpeakerDetector(t *testing.T) {
+	d := confSpeakerDetector{
+		threshold: 0.1,
+		holdOff:   10,
+		silentLen: make(map[string]int),
+	}
+
+	// nobody speaks
+	d.update(map[string]float32{"alice": 0, "bob": 0.05}, 5)
+	require.Equal(t, "", d.active)
+
+	// loudest becomes active
+	d.update(map[string]float32{"alice": 0.2, "bob": 0.3}, 5)
+	require.Equal(t, "bob", d.active)
+
+	// active speaker still speaks
+	d.update(map[string]float32{"alice": 0.5, "bob": 0.2}, 5)
+	require.Equal(t, "bob", d.active)
+
+	// active speaker is silent, but not for hold-off yet
+	d.update(map[string]float32{"alice": 0.5, "bob": 0}, 5)
+	require.Equal(t, "bob", d.active)
+
+	// active speaker is silent for hold-off
+	d.update(map[string]float32{"alice": 0.5, "bob": 0}, 5)
+	require.Equal(t, "alice", d.active)
+
+	// nobody speaks, keep last speaker
+	d.update(map[string]float32{"alice": 0, "bob": 0}, 100)
+	require.Equal(t, "alice", d.active)
+
+	d.remove("alice")
+	require.Equal(t, "", d.active)
+
+	d.update(map[string]float32{"bob": 0.2}, 5)
+	require.Equal(t, "bob", d.active)
+}
+
+func TestConference_Open(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		config  func() ConferenceConfig
+		wantErr error
+	}{
+		{
+			name:   "ok",
+			config: makeConferenceConfig,
+		},
+		{
+			name: "bad encoding",
+			config: func() ConferenceConfig {
+				config := makeConferenceConfig()
+				config.FrameEncoding.Channels = ChannelLayoutMultitrack
+				config.FrameEncoding.Tracks = 4
+				return config
+			},
+			wantErr: ErrNotSupported,
+		},
+		{
+			name: "bad sender config",
+			config: func() ConferenceConfig {
+				config := makeConferenceConfig()
+				config.SenderConfig.PacketEncoding = -1
+				return config
+			},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name: "bad frame length",
+			config: func() ConferenceConfig {
+				config := makeConferenceConfig()
+				config.FrameLength = -time.Millisecond
+				return config
+			},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name: "too small frame length",
+			config: func() ConferenceConfig {
+				config := makeConferenceConfig()
+				config.FrameLength = time.Microsecond
+				return config
+			},
+			wantErr: ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf, err := OpenConference(ctx, tt.config())
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr))
+				require.Nil(t, conf)
+				return
+			}
+			require.NoError(t, err)
+			require.NotNil(t, conf)
+			require.NoError(t, conf.Close())
+		})
+	}
+
+	conf, err := OpenConference(nil, makeConferenceConfig())
+	require.Equal(t, ErrNilContext, err)
+	require.Nil(t, conf)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestConference_Participants(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	conf, err := OpenConference(ctx, makeConferenceConfig())
+	require.NoError(t, err)
+
+	makeSet := func(port int) *EndpointSet {
+		set, err := NewEndpointSet("127.0.0.1", port, FecEncodingRs8m)
+		require.NoError(t, err)
+		return set
+	}
+
+	require.NoError(t, conf.Join("alice", makeSet(0), makeSet(10001)))
+	require.NoError(t, conf.Join("bob", makeSet(0), makeSet(20001)))
+	require.NoError(t, conf.Join("carol", makeSet(0), makeSet(30001)))
+
+	require.True(t, errors.Is(conf.Join("", makeSet(0), makeSet(40001)), ErrInvalidArgument))
+	require.True(t, errors.Is(conf.Join("alice", makeSet(0), makeSet(40001)), ErrExists))
+	require.True(t, errors.Is(conf.Join("dave", nil, makeSet(40001)), ErrBadEndpoint))
+	require.True(t, errors.Is(conf.Join("dave", makeSet(0), nil), ErrBadEndpoint))
+
+	require.NoError(t, conf.SetGain("bob", 0.5))
+	require.True(t, errors.Is(conf.SetGain("dave", 0.5), ErrNotFound))
+
+	for n := 0; n < 10; n++ {
+		require.NoError(t, conf.Step())
+	}
+
+	parts := conf.Participants()
+	require.Len(t, parts, 3)
+
+	require.Equal(t, "alice", parts[0].Name)
+	require.Equal(t, float32(1), parts[0].Gain)
+	require.NotEqual(t, 0, parts[0].Uplink.Source.Port)
+	require.Equal(t, 10001, parts[0].Downlink.Source.Port)
+
+	require.Equal(t, "bob", parts[1].Name)
+	require.Equal(t, float32(0.5), parts[1].Gain)
+
+	require.Equal(t, "carol", parts[2].Name)
+
+	// nobody is connected
+	for _, part := range parts {
+		require.Equal(t, float32(0), part.Level)
+		require.False(t, part.Speaking)
+	}
+	_, ok := conf.ActiveSpeaker()
+	require.False(t, ok)
+
+	require.NoError(t, conf.Leave("bob"))
+	require.True(t, errors.Is(conf.Leave("bob"), ErrNotFound))
+	require.Len(t, conf.Participants(), 2)
+
+	require.NoError(t, conf.Step())
+
+	require.NoError(t, conf.Close())
+
+	require.True(t, errors.Is(conf.Step(), ErrClosed))
+	require.True(t, errors.Is(conf.Join("bob", makeSet(0), makeSet(20001)), ErrClosed))
+	require.Empty(t, conf.Participants())
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestConference_Run(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	conf, err := OpenConference(ctx, makeConferenceConfig())
+	require.NoError(t, err)
+
+	uplink, err := NewEndpointSet("127.0.0.1", 0, FecEncodingRs8m)
+	require.NoError(t, err)
+	downlink, err := NewEndpointSet("127.0.0.1", 10001, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	require.NoError(t, conf.Join("alice", uplink, downlink))
+
+	runCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = conf.Run(runCtx)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- conf.Run(context.Background())
+	}()
+
+	require.NoError(t, conf.Close())
+
+	err = <-errCh
+	require.True(t, errors.Is(err, ErrClosed))
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
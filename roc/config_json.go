@@ -0,0 +1,208 @@
+package roc
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Configuration structs implement json.Marshaler and json.Unmarshaler.
+//
+// Fields are named in snake_case after the corresponding fields of C structs
+// (e.g. "frame_encoding", "fec_block_source_packets"), enums are encoded using
+// their text names (see MarshalText methods), and durations are encoded as
+// strings in time.ParseDuration format (e.g. "200ms"). Zero fields are
+// omitted, and missing fields are decoded as zero (i.e. default) values.
+
+// duration encoded as string
+type jsonDuration time.Duration
+
+func (d jsonDuration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *jsonDuration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = jsonDuration(v)
+	return nil
+}
+
+type mediaEncodingJSON struct {
+	Rate     uint32        `json:"rate,omitempty"`
+	Format   Format        `json:"format,omitempty"`
+	Channels ChannelLayout `json:"channels,omitempty"`
+	Tracks   uint32        `json:"tracks,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (encoding MediaEncoding) MarshalJSON() ([]byte, error) {
+	return json.Marshal(mediaEncodingJSON(encoding))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (encoding *MediaEncoding) UnmarshalJSON(data []byte) error {
+	var j mediaEncodingJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*encoding = MediaEncoding(j)
+	return nil
+}
+
+type contextConfigJSON struct {
+	MaxPacketSize uint32 `json:"max_packet_size,omitempty"`
+	MaxFrameSize  uint32 `json:"max_frame_size,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (config ContextConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(contextConfigJSON(config))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (config *ContextConfig) UnmarshalJSON(data []byte) error {
+	var j contextConfigJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*config = ContextConfig(j)
+	return nil
+}
+
+type interfaceConfigJSON struct {
+	OutgoingAddress string `json:"outgoing_address,omitempty"`
+	MulticastGroup  string `json:"multicast_group,omitempty"`
+	ReuseAddress    bool   `json:"reuse_address,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (config InterfaceConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(interfaceConfigJSON(config))
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (config *InterfaceConfig) UnmarshalJSON(data []byte) error {
+	var j interfaceConfigJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*config = InterfaceConfig(j)
+	return nil
+}
+
+type senderConfigJSON struct {
+	FrameEncoding         MediaEncoding       `json:"frame_encoding"`
+	PacketEncoding        PacketEncoding      `json:"packet_encoding,omitempty"`
+	PacketLength          jsonDuration        `json:"packet_length,omitempty"`
+	PacketInterleaving    bool                `json:"packet_interleaving,omitempty"`
+	FecEncoding           FecEncoding         `json:"fec_encoding,omitempty"`
+	FecBlockSourcePackets uint32              `json:"fec_block_source_packets,omitempty"`
+	FecBlockRepairPackets uint32              `json:"fec_block_repair_packets,omitempty"`
+	ClockSource           ClockSource         `json:"clock_source,omitempty"`
+	LatencyTunerBackend   LatencyTunerBackend `json:"latency_tuner_backend,omitempty"`
+	LatencyTunerProfile   LatencyTunerProfile `json:"latency_tuner_profile,omitempty"`
+	ResamplerBackend      ResamplerBackend    `json:"resampler_backend,omitempty"`
+	ResamplerProfile      ResamplerProfile    `json:"resampler_profile,omitempty"`
+	TargetLatency         jsonDuration        `json:"target_latency,omitempty"`
+	LatencyTolerance      jsonDuration        `json:"latency_tolerance,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (config SenderConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(senderConfigJSON{
+		FrameEncoding:         config.FrameEncoding,
+		PacketEncoding:        config.PacketEncoding,
+		PacketLength:          jsonDuration(config.PacketLength),
+		PacketInterleaving:    config.PacketInterleaving,
+		FecEncoding:           config.FecEncoding,
+		FecBlockSourcePackets: config.FecBlockSourcePackets,
+		FecBlockRepairPackets: config.FecBlockRepairPackets,
+		ClockSource:           config.ClockSource,
+		LatencyTunerBackend:   config.LatencyTunerBackend,
+		LatencyTunerProfile:   config.LatencyTunerProfile,
+		ResamplerBackend:      config.ResamplerBackend,
+		ResamplerProfile:      config.ResamplerProfile,
+		TargetLatency:         jsonDuration(config.TargetLatency),
+		LatencyTolerance:      jsonDuration(config.LatencyTolerance),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (config *SenderConfig) UnmarshalJSON(data []byte) error {
+	var j senderConfigJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*config = SenderConfig{
+		FrameEncoding:         j.FrameEncoding,
+		PacketEncoding:        j.PacketEncoding,
+		PacketLength:          time.Duration(j.PacketLength),
+		PacketInterleaving:    j.PacketInterleaving,
+		FecEncoding:           j.FecEncoding,
+		FecBlockSourcePackets: j.FecBlockSourcePackets,
+		FecBlockRepairPackets: j.FecBlockRepairPackets,
+		ClockSource:           j.ClockSource,
+		LatencyTunerBackend:   j.LatencyTunerBackend,
+		LatencyTunerProfile:   j.LatencyTunerProfile,
+		ResamplerBackend:      j.ResamplerBackend,
+		ResamplerProfile:      j.ResamplerProfile,
+		TargetLatency:         time.Duration(j.TargetLatency),
+		LatencyTolerance:      time.Duration(j.LatencyTolerance),
+	}
+	return nil
+}
+
+type receiverConfigJSON struct {
+	FrameEncoding         MediaEncoding       `json:"frame_encoding"`
+	ClockSource           ClockSource         `json:"clock_source,omitempty"`
+	LatencyTunerBackend   LatencyTunerBackend `json:"latency_tuner_backend,omitempty"`
+	LatencyTunerProfile   LatencyTunerProfile `json:"latency_tuner_profile,omitempty"`
+	ResamplerBackend      ResamplerBackend    `json:"resampler_backend,omitempty"`
+	ResamplerProfile      ResamplerProfile    `json:"resampler_profile,omitempty"`
+	PlcBackend            PlcBackend          `json:"plc_backend,omitempty"`
+	TargetLatency         jsonDuration        `json:"target_latency,omitempty"`
+	LatencyTolerance      jsonDuration        `json:"latency_tolerance,omitempty"`
+	NoPlaybackTimeout     jsonDuration        `json:"no_playback_timeout,omitempty"`
+	ChoppyPlaybackTimeout jsonDuration        `json:"choppy_playback_timeout,omitempty"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (config ReceiverConfig) MarshalJSON() ([]byte, error) {
+	return json.Marshal(receiverConfigJSON{
+		FrameEncoding:         config.FrameEncoding,
+		ClockSource:           config.ClockSource,
+		LatencyTunerBackend:   config.LatencyTunerBackend,
+		LatencyTunerProfile:   config.LatencyTunerProfile,
+		ResamplerBackend:      config.ResamplerBackend,
+		ResamplerProfile:      config.ResamplerProfile,
+		PlcBackend:            config.PlcBackend,
+		TargetLatency:         jsonDuration(config.TargetLatency),
+		LatencyTolerance:      jsonDuration(config.LatencyTolerance),
+		NoPlaybackTimeout:     jsonDuration(config.NoPlaybackTimeout),
+		ChoppyPlaybackTimeout: jsonDuration(config.ChoppyPlaybackTimeout),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (config *ReceiverConfig) UnmarshalJSON(data []byte) error {
+	var j receiverConfigJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	*config = ReceiverConfig{
+		FrameEncoding:         j.FrameEncoding,
+		ClockSource:           j.ClockSource,
+		LatencyTunerBackend:   j.LatencyTunerBackend,
+		LatencyTunerProfile:   j.LatencyTunerProfile,
+		ResamplerBackend:      j.ResamplerBackend,
+		ResamplerProfile:      j.ResamplerProfile,
+		PlcBackend:            j.PlcBackend,
+		TargetLatency:         time.Duration(j.TargetLatency),
+		LatencyTolerance:      time.Duration(j.LatencyTolerance),
+		NoPlaybackTimeout:     time.Duration(j.NoPlaybackTimeout),
+		ChoppyPlaybackTimeout: time.Duration(j.ChoppyPlaybackTimeout),
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package roc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigJSON_Sender(t *testing.T) {
+	config := SenderConfig{
+		FrameEncoding: MediaEncoding{
+			Rate:     48000,
+			Format:   FormatPcmFloat32,
+			Channels: ChannelLayoutMultitrack,
+			Tracks:   4,
+		},
+		PacketLength:          5 * time.Millisecond,
+		FecEncoding:           FecEncodingLdpcStaircase,
+		FecBlockSourcePackets: 20,
+		FecBlockRepairPackets: 10,
+		ClockSource:           ClockSourceInternal,
+		LatencyTunerProfile:   LatencyTunerProfileIntact,
+		TargetLatency:         200 * time.Millisecond,
+	}
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"frame_encoding": {
+			"rate": 48000,
+			"format": "pcm_float32",
+			"channels": "multitrack",
+			"tracks": 4
+		},
+		"packet_length": "5ms",
+		"fec_encoding": "ldpc",
+		"fec_block_source_packets": 20,
+		"fec_block_repair_packets": 10,
+		"clock_source": "internal",
+		"latency_tuner_profile": "intact",
+		"target_latency": "200ms"
+	}`, string(data))
+
+	var decoded SenderConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, config, decoded)
+}
+
+func TestConfigJSON_Receiver(t *testing.T) {
+	config := ReceiverConfig{
+		FrameEncoding:       makeMediaEncoding(),
+		LatencyTunerBackend: LatencyTunerBackendNiq,
+		ResamplerBackend:    ResamplerBackendSpeex,
+		ResamplerProfile:    ResamplerProfileHigh,
+		PlcBackend:          PlcBackend(1000),
+		TargetLatency:       100 * time.Millisecond,
+		LatencyTolerance:    20 * time.Millisecond,
+		NoPlaybackTimeout:   -1,
+	}
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{
+		"frame_encoding": {
+			"rate": 44100,
+			"format": "pcm_float32",
+			"channels": "stereo"
+		},
+		"latency_tuner_backend": "niq",
+		"resampler_backend": "speex",
+		"resampler_profile": "high",
+		"plc_backend": "1000",
+		"target_latency": "100ms",
+		"latency_tolerance": "20ms",
+		"no_playback_timeout": "-1ns"
+	}`, string(data))
+
+	var decoded ReceiverConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, config, decoded)
+}
+
+func TestConfigJSON_Interface(t *testing.T) {
+	config := makeInterfaceConfig()
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	assert.JSONEq(t,
+		`{"outgoing_address": "127.0.0.1", "reuse_address": true}`, string(data))
+
+	var decoded InterfaceConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, config, decoded)
+}
+
+func TestConfigJSON_Context(t *testing.T) {
+	config := makeContextConfig()
+
+	data, err := json.Marshal(config)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"max_packet_size": 2000, "max_frame_size": 4000}`, string(data))
+
+	var decoded ContextConfig
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(t, config, decoded)
+}
+
+// config structs are generated, so JSON encoding uses hand-written shadow
+// structs; check that they're not out of sync
+func TestConfigJSON_Fields(t *testing.T) {
+	tests := []struct {
+		config interface{}
+		json   interface{}
+	}{
+		{config: SenderConfig{}, json: senderConfigJSON{}},
+		{config: ReceiverConfig{}, json: receiverConfigJSON{}},
+		{config: MediaEncoding{}, json: mediaEncodingJSON{}},
+		{config: ContextConfig{}, json: contextConfigJSON{}},
+		{config: InterfaceConfig{}, json: interfaceConfigJSON{}},
+	}
+
+	durationType := reflect.TypeOf(time.Duration(0))
+	jsonDurationType := reflect.TypeOf(jsonDuration(0))
+
+	for _, tt := range tests {
+		configType := reflect.TypeOf(tt.config)
+		jsonType := reflect.TypeOf(tt.json)
+
+		t.Run(configType.Name(), func(t *testing.T) {
+			require.Equal(t, configType.NumField(), jsonType.NumField())
+
+			for i := 0; i < configType.NumField(); i++ {
+				field := configType.Field(i)
+				if field.PkgPath != "" {
+					continue
+				}
+
+				jsonField, ok := jsonType.FieldByName(field.Name)
+				require.True(t, ok, "field %s has no JSON counterpart", field.Name)
+				require.NotEmpty(t, jsonField.Tag.Get("json"))
+
+				if field.Type == durationType {
+					assert.Equal(t, jsonDurationType, jsonField.Type, field.Name)
+				} else {
+					assert.Equal(t, field.Type, jsonField.Type, field.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestConfigJSON_Errors(t *testing.T) {
+	var config SenderConfig
+
+	err := json.Unmarshal([]byte(`{"fec_encoding": "bogus"}`), &config)
+	assert.Error(t, err)
+
+	err = json.Unmarshal([]byte(`{"packet_length": "5 parsecs"}`), &config)
+	assert.Error(t, err)
+
+	err = json.Unmarshal([]byte(`{"frame_encoding": {"channels": "quad"}}`), &config)
+	assert.Error(t, err)
+}
@@ -0,0 +1,226 @@
+package roc
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Maximum number of tracks in multitrack channel layout.
+const maxTracks = 1024
+
+// Maximum total number of packets in Reed-Solomon (m=8) FEC block.
+const maxRs8mBlockPackets = 255
+
+// FieldError describes a single invalid field of a configuration struct.
+type FieldError struct {
+	// Path to the field, e.g. "FrameEncoding.Rate".
+	Field string
+
+	// Human-readable description of the problem.
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("invalid config.%s: %s", e.Field, e.Reason)
+}
+
+// ConfigError is returned by SenderConfig.Validate() and ReceiverConfig.Validate()
+// and lists every invalid field of the configuration.
+//
+// errors.Is(err, ErrInvalidConfig) reports true for ConfigError.
+type ConfigError []FieldError
+
+func (e ConfigError) Error() string {
+	texts := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		texts = append(texts, fieldErr.Error())
+	}
+	return strings.Join(texts, "; ")
+}
+
+func (e ConfigError) Is(target error) bool {
+	return target == ErrInvalidConfig
+}
+
+// Validate checks sender configuration.
+//
+// Performs checks that don't require native library: frame encoding, FEC block
+// sizes, packet encoding compatibility, latency parameters, and enum values.
+// Passing validation doesn't guarantee that OpenSender() will succeed.
+//
+// Returns nil or ConfigError. Called automatically by OpenSender() and
+// OpenSenderEncoder().
+func (config SenderConfig) Validate() error {
+	var v configValidator
+
+	v.checkEncoding("FrameEncoding", config.FrameEncoding)
+
+	if config.PacketEncoding < 0 {
+		v.fail("PacketEncoding", "unsupported value %v", config.PacketEncoding)
+	}
+	if isBuiltinPacketEncoding(config.PacketEncoding) &&
+		config.FrameEncoding.Channels == ChannelLayoutMultitrack {
+		v.fail("PacketEncoding", "%v can't be used with multitrack FrameEncoding",
+			config.PacketEncoding)
+	}
+
+	v.checkDuration("PacketLength", config.PacketLength)
+
+	switch config.FecEncoding {
+	case FecEncodingDefault, FecEncodingRs8m:
+		total := uint64(config.FecBlockSourcePackets) + uint64(config.FecBlockRepairPackets)
+		if total > maxRs8mBlockPackets {
+			v.fail("FecBlockSourcePackets",
+				"source and repair packets should not exceed %d in total for Rs8m, got %d",
+				maxRs8mBlockPackets, total)
+		}
+	case FecEncodingDisable, FecEncodingLdpcStaircase:
+	default:
+		v.fail("FecEncoding", "unsupported value %v", config.FecEncoding)
+	}
+
+	v.checkClockSource(config.ClockSource)
+	v.checkLatency(config.LatencyTunerBackend, config.LatencyTunerProfile,
+		config.TargetLatency, config.LatencyTolerance)
+	v.checkResampler(config.ResamplerBackend, config.ResamplerProfile)
+
+	if config.LatencyTunerProfile != LatencyTunerProfileDefault &&
+		config.LatencyTunerProfile != LatencyTunerProfileIntact &&
+		config.TargetLatency == 0 {
+		v.fail("TargetLatency", "should be set when latency tuning is enabled on sender")
+	}
+
+	return v.result()
+}
+
+// Validate checks receiver configuration.
+//
+// Performs checks that don't require native library: frame encoding, latency
+// parameters, and enum values. Passing validation doesn't guarantee that
+// OpenReceiver() will succeed.
+//
+// Returns nil or ConfigError. Called automatically by OpenReceiver() and
+// OpenReceiverDecoder().
+func (config ReceiverConfig) Validate() error {
+	var v configValidator
+
+	v.checkEncoding("FrameEncoding", config.FrameEncoding)
+
+	v.checkClockSource(config.ClockSource)
+	v.checkLatency(config.LatencyTunerBackend, config.LatencyTunerProfile,
+		config.TargetLatency, config.LatencyTolerance)
+	v.checkResampler(config.ResamplerBackend, config.ResamplerProfile)
+
+	switch {
+	case config.PlcBackend == PlcBackendDefault, config.PlcBackend == PlcBackendNone:
+	case config.PlcBackend >= plcPluginMinID && config.PlcBackend <= plcPluginMaxID:
+	default:
+		v.fail("PlcBackend", "unsupported value %v", config.PlcBackend)
+	}
+
+	return v.result()
+}
+
+// accumulates field errors
+type configValidator struct {
+	errs ConfigError
+}
+
+func (v *configValidator) fail(field string, reason string, args ...interface{}) {
+	v.errs = append(v.errs, FieldError{
+		Field:  field,
+		Reason: fmt.Sprintf(reason, args...),
+	})
+}
+
+func (v *configValidator) result() error {
+	if len(v.errs) == 0 {
+		return nil
+	}
+	return v.errs
+}
+
+func (v *configValidator) checkEncoding(field string, encoding MediaEncoding) {
+	if encoding.Rate == 0 {
+		v.fail(field+".Rate", "should be non-zero")
+	}
+
+	if encoding.Format != FormatPcmFloat32 {
+		v.fail(field+".Format", "unsupported value %v", encoding.Format)
+	}
+
+	switch encoding.Channels {
+	case ChannelLayoutMono, ChannelLayoutStereo:
+		if encoding.Tracks != 0 {
+			v.fail(field+".Tracks", "should be zero unless Channels is Multitrack")
+		}
+	case ChannelLayoutMultitrack:
+		if encoding.Tracks == 0 || encoding.Tracks > maxTracks {
+			v.fail(field+".Tracks", "should be in range [1; %d] for Multitrack, got %d",
+				maxTracks, encoding.Tracks)
+		}
+	default:
+		v.fail(field+".Channels", "unsupported value %v", encoding.Channels)
+	}
+}
+
+func (v *configValidator) checkDuration(field string, d time.Duration) {
+	if d < 0 {
+		v.fail(field, "should be non-negative, got %v", d)
+	}
+}
+
+func (v *configValidator) checkClockSource(clockSource ClockSource) {
+	switch clockSource {
+	case ClockSourceDefault, ClockSourceExternal, ClockSourceInternal:
+	default:
+		v.fail("ClockSource", "unsupported value %v", clockSource)
+	}
+}
+
+func (v *configValidator) checkLatency(
+	backend LatencyTunerBackend, profile LatencyTunerProfile,
+	target time.Duration, tolerance time.Duration,
+) {
+	switch backend {
+	case LatencyTunerBackendDefault, LatencyTunerBackendNiq:
+	default:
+		v.fail("LatencyTunerBackend", "unsupported value %v", backend)
+	}
+
+	switch profile {
+	case LatencyTunerProfileDefault, LatencyTunerProfileIntact,
+		LatencyTunerProfileResponsive, LatencyTunerProfileGradual:
+	default:
+		v.fail("LatencyTunerProfile", "unsupported value %v", profile)
+	}
+
+	v.checkDuration("TargetLatency", target)
+	v.checkDuration("LatencyTolerance", tolerance)
+
+	if target > 0 && tolerance > target {
+		v.fail("LatencyTolerance", "should not exceed TargetLatency (%v), got %v",
+			target, tolerance)
+	}
+}
+
+func (v *configValidator) checkResampler(backend ResamplerBackend, profile ResamplerProfile) {
+	switch backend {
+	case ResamplerBackendDefault, ResamplerBackendBuiltin,
+		ResamplerBackendSpeex, ResamplerBackendSpeexdec:
+	default:
+		v.fail("ResamplerBackend", "unsupported value %v", backend)
+	}
+
+	switch profile {
+	case ResamplerProfileDefault, ResamplerProfileHigh,
+		ResamplerProfileMedium, ResamplerProfileLow:
+	default:
+		v.fail("ResamplerProfile", "unsupported value %v", profile)
+	}
+}
+
+func isBuiltinPacketEncoding(encoding PacketEncoding) bool {
+	return encoding == PacketEncodingAvpL16Mono || encoding == PacketEncodingAvpL16Stereo
+}
@@ -0,0 +1,270 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigValidate_Sender(t *testing.T) {
+	tests := []struct {
+		name       string
+		configFunc func() SenderConfig
+		wantFields []string
+	}{
+		{
+			name:       "valid",
+			configFunc: makeSenderConfig,
+			wantFields: nil,
+		},
+		{
+			name: "zero",
+			configFunc: func() SenderConfig {
+				return SenderConfig{}
+			},
+			wantFields: []string{
+				"FrameEncoding.Rate", "FrameEncoding.Format", "FrameEncoding.Channels",
+			},
+		},
+		{
+			name: "multitrack",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FrameEncoding.Channels = ChannelLayoutMultitrack
+				sc.FrameEncoding.Tracks = 4
+				sc.PacketEncoding = 0
+				return sc
+			},
+			wantFields: nil,
+		},
+		{
+			name: "tracks without multitrack",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FrameEncoding.Tracks = 4
+				return sc
+			},
+			wantFields: []string{"FrameEncoding.Tracks"},
+		},
+		{
+			name: "multitrack without tracks",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FrameEncoding.Channels = ChannelLayoutMultitrack
+				sc.PacketEncoding = 0
+				return sc
+			},
+			wantFields: []string{"FrameEncoding.Tracks"},
+		},
+		{
+			name: "builtin packet encoding with multitrack",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FrameEncoding.Channels = ChannelLayoutMultitrack
+				sc.FrameEncoding.Tracks = 2
+				return sc
+			},
+			wantFields: []string{"PacketEncoding"},
+		},
+		{
+			name: "fec block with disabled fec",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FecEncoding = FecEncodingDisable
+				sc.FecBlockSourcePackets = 10
+				sc.FecBlockRepairPackets = 5
+				return sc
+			},
+			wantFields: nil,
+		},
+		{
+			name: "rs8m block too large",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FecEncoding = FecEncodingRs8m
+				sc.FecBlockSourcePackets = 200
+				sc.FecBlockRepairPackets = 100
+				return sc
+			},
+			wantFields: []string{"FecBlockSourcePackets"},
+		},
+		{
+			name: "ldpc large block",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FecEncoding = FecEncodingLdpcStaircase
+				sc.FecBlockSourcePackets = 2000
+				sc.FecBlockRepairPackets = 1000
+				return sc
+			},
+			wantFields: nil,
+		},
+		{
+			name: "negative durations",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.PacketLength = -1
+				sc.TargetLatency = -1
+				sc.LatencyTolerance = -1
+				return sc
+			},
+			wantFields: []string{"PacketLength", "TargetLatency", "LatencyTolerance"},
+		},
+		{
+			name: "tolerance exceeds target",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.TargetLatency = 100 * time.Millisecond
+				sc.LatencyTolerance = 200 * time.Millisecond
+				return sc
+			},
+			wantFields: []string{"LatencyTolerance"},
+		},
+		{
+			name: "tuning without target",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.LatencyTunerProfile = LatencyTunerProfileResponsive
+				return sc
+			},
+			wantFields: []string{"TargetLatency"},
+		},
+		{
+			name: "unknown enums",
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FecEncoding = 100
+				sc.ClockSource = 100
+				sc.LatencyTunerBackend = 100
+				sc.LatencyTunerProfile = 100
+				sc.ResamplerBackend = 100
+				sc.ResamplerProfile = 100
+				sc.TargetLatency = time.Second
+				return sc
+			},
+			wantFields: []string{
+				"FecEncoding", "ClockSource", "LatencyTunerBackend",
+				"LatencyTunerProfile", "ResamplerBackend", "ResamplerProfile",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkConfigError(t, tt.configFunc().Validate(), tt.wantFields)
+		})
+	}
+}
+
+func TestConfigValidate_Receiver(t *testing.T) {
+	tests := []struct {
+		name       string
+		configFunc func() ReceiverConfig
+		wantFields []string
+	}{
+		{
+			name:       "valid",
+			configFunc: makeReceiverConfig,
+			wantFields: nil,
+		},
+		{
+			name: "zero",
+			configFunc: func() ReceiverConfig {
+				return ReceiverConfig{}
+			},
+			wantFields: []string{
+				"FrameEncoding.Rate", "FrameEncoding.Format", "FrameEncoding.Channels",
+			},
+		},
+		{
+			name: "negative timeouts",
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.NoPlaybackTimeout = -1
+				rc.ChoppyPlaybackTimeout = -1
+				return rc
+			},
+			wantFields: nil,
+		},
+		{
+			name: "negative latency",
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.TargetLatency = -1
+				rc.LatencyTolerance = -1
+				return rc
+			},
+			wantFields: []string{"TargetLatency", "LatencyTolerance"},
+		},
+		{
+			name: "tolerance exceeds target",
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.TargetLatency = 100 * time.Millisecond
+				rc.LatencyTolerance = 200 * time.Millisecond
+				return rc
+			},
+			wantFields: []string{"LatencyTolerance"},
+		},
+		{
+			name: "plc plugin",
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.PlcBackend = 1000
+				return rc
+			},
+			wantFields: nil,
+		},
+		{
+			name: "unknown plc",
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.PlcBackend = 100
+				return rc
+			},
+			wantFields: []string{"PlcBackend"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checkConfigError(t, tt.configFunc().Validate(), tt.wantFields)
+		})
+	}
+}
+
+func TestConfigValidate_Error(t *testing.T) {
+	err := ConfigError{
+		{Field: "FrameEncoding.Rate", Reason: "should be non-zero"},
+		{Field: "PacketLength", Reason: "should be non-negative, got -1ns"},
+	}
+
+	assert.Equal(t,
+		"invalid config.FrameEncoding.Rate: should be non-zero; "+
+			"invalid config.PacketLength: should be non-negative, got -1ns",
+		err.Error())
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+	assert.False(t, errors.Is(err, ErrBadEndpoint))
+}
+
+func checkConfigError(t *testing.T, err error, wantFields []string) {
+	if wantFields == nil {
+		require.NoError(t, err)
+		return
+	}
+
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrInvalidConfig))
+
+	var configErr ConfigError
+	require.True(t, errors.As(err, &configErr))
+
+	var fields []string
+	for _, fieldErr := range configErr {
+		fields = append(fields, fieldErr.Field)
+	}
+	assert.Equal(t, wantFields, fields)
+}
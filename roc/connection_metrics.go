@@ -0,0 +1,59 @@
+package roc
+
+/*
+#include <roc/metrics.h>
+*/
+import "C"
+
+import (
+	"time"
+)
+
+// Metrics for a single connection between sender and receiver.
+//
+// On receiver, represents one connected sender. Similarly, on sender
+// represents one connected receiver.
+//
+// See Sender.Query(), Receiver.Query().
+type ConnectionMetrics struct {
+	// Estimated end-to-end latency.
+	//
+	// Defines how much time passed after a frame was written to sender and
+	// before it was read from receiver. Consists of sender latency, network
+	// latency, and receiver latency.
+	//
+	// Computations are based on RTCP and system clock. If RTCP is not used, or
+	// there is no synchronization between sender and receiver clocks, latency
+	// can not be computed and is zero.
+	E2eLatency time.Duration
+
+	// Estimated interarrival jitter.
+	//
+	// Determines expected variance of inter-packet arrival period.
+	//
+	// Estimation is based on RTP timestamps and arrival time of packets.
+	MeanJitter time.Duration
+
+	// Total amount of packets that receiver expects to be delivered.
+	//
+	// Calculated based on sequence numbers of the oldest and the latest
+	// received packets.
+	ExpectedPackets uint64
+
+	// Cumulative count of lost packets.
+	//
+	// The total number of packets that have been lost since the beginning of
+	// reception. Packets that were lost and then recovered with FEC are not
+	// counted as lost.
+	//
+	// May be negative if duplicate packets were received.
+	LostPackets int64
+}
+
+// fills metrics from cMetrics
+func (metrics *ConnectionMetrics) fromC(cMetrics *C.struct_roc_connection_metrics) {
+	metrics.E2eLatency = time.Duration(cMetrics.e2e_latency)
+	metrics.MeanJitter = time.Duration(cMetrics.mean_jitter)
+	metrics.ExpectedPackets = uint64(cMetrics.expected_packets)
+	metrics.LostPackets = int64(cMetrics.lost_packets)
+}
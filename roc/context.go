@@ -6,7 +6,6 @@ package roc
 import "C"
 
 import (
-	"errors"
 	"sync"
 )
 
@@ -32,10 +31,17 @@ import (
 //
 // See also Sender, Receiver
 type Context struct {
-	mu   sync.RWMutex
-	cPtr *C.roc_context
+	mu         sync.RWMutex
+	cPtr       *C.roc_context
+	plcPlugins []plcPlugin
+
+	// ContextConfig.MaxPacketSize with default applied
+	maxPacketSize int
 }
 
+// Default of ContextConfig.MaxPacketSize used by native library.
+const defaultMaxPacketSize = 2048
+
 // Open a new context.
 //
 // Allocates and initializes a new context. May start some background threads.
@@ -54,16 +60,24 @@ func OpenContext(config ContextConfig) (ctx *Context, err error) {
 	}
 
 	var cCtx *C.roc_context
-	errCode := C.roc_context_open(&cConfig, &cCtx)
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_context_open(&cConfig, &cCtx)
+	})
 	if errCode != 0 {
-		return nil, newNativeErr("roc_context_open()", errCode)
+		return nil, newNativeErrLog("roc_context_open()", errCode, messages)
 	}
 	if cCtx == nil {
 		panic("roc_context_open() returned nil")
 	}
 
+	maxPacketSize := int(config.MaxPacketSize)
+	if maxPacketSize == 0 {
+		maxPacketSize = defaultMaxPacketSize
+	}
+
 	ctx = &Context{
-		cPtr: cCtx,
+		cPtr:          cCtx,
+		maxPacketSize: maxPacketSize,
 	}
 
 	return ctx, nil
@@ -98,7 +112,7 @@ func (c *Context) RegisterEncoding(encodingID int, encoding MediaEncoding) (err
 	defer c.mu.RUnlock()
 
 	if c.cPtr == nil {
-		return errors.New("context is closed")
+		return newErr(ErrClosed, "context is closed")
 	}
 
 	cEncoding := C.struct_roc_media_encoding{
@@ -108,16 +122,69 @@ func (c *Context) RegisterEncoding(encodingID int, encoding MediaEncoding) (err
 		tracks:   C.uint(encoding.Tracks),
 	}
 
-	var errCode C.int
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_context_register_encoding(
+			c.cPtr,
+			C.int(encodingID),
+			&cEncoding)
+	})
+	if errCode != 0 {
+		return newNativeErrLog("roc_context_register_encoding()", errCode, messages)
+	}
+
+	return nil
+}
 
-	errCode = C.roc_context_register_encoding(
-		c.cPtr,
-		C.int(encodingID),
-		&cEncoding)
+// Register custom PLC backend.
+//
+// Registers Go implementation of packet loss concealment (PLC) with given
+// PluginID. Registered plugins complement built-in backends defined by
+// PlcBackend enum. To use registered plugin on receiver, set PlcBackend field
+// of ReceiverConfig to the plugin identifier.
+//
+// PluginID should be in range [1000; 9999].
+//
+// The factory is invoked every time when receiver creates a new connection,
+// and should return a new Plc instance for that connection.
+//
+// Registered plugin remains registered until the context is closed.
+func (c *Context) RegisterPlc(pluginID int, factory PlcFactory) (err error) {
+	logWrite(LogDebug,
+		"entering Context.RegisterPlc(): context=%p id=%+v", c, pluginID,
+	)
+	defer func() {
+		logWrite(LogDebug, "leaving Context.RegisterPlc(): context=%p err=%#v", c, err)
+	}()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cPtr == nil {
+		return newErr(ErrClosed, "context is closed")
+	}
+
+	if factory == nil {
+		return newErr(ErrInvalidArgument, "factory is nil")
+	}
+
+	plugin, ok := plcAllocate(factory)
+	if !ok {
+		return newNativeErr("calloc()", -1)
+	}
+
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_context_register_plc(
+			c.cPtr,
+			C.int(pluginID),
+			plugin.cPtr)
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_context_register_encoding()", errCode)
+		plcDeallocate(plugin)
+		return newNativeErrLog("roc_context_register_plc()", errCode, messages)
 	}
 
+	c.plcPlugins = append(c.plcPlugins, plugin)
+
 	return nil
 }
 
@@ -144,6 +211,11 @@ func (c *Context) Close() (err error) {
 		}
 
 		c.cPtr = nil
+
+		for _, plugin := range c.plcPlugins {
+			plcDeallocate(plugin)
+		}
+		c.plcPlugins = nil
 	}
 
 	return nil
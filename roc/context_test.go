@@ -1,7 +1,6 @@
 package roc
 
 import (
-	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -36,7 +35,7 @@ func TestContext_Open(t *testing.T) {
 				err = ctx.Close()
 				require.NoError(t, err)
 			} else {
-				require.Equal(t, tt.wantErr, err)
+				require.Equal(t, tt.wantErr, stripNativeLog(err))
 				require.Nil(t, ctx)
 			}
 		})
@@ -81,7 +80,59 @@ func TestContext_RegisterEncoding(t *testing.T) {
 			require.NotNil(t, ctx)
 
 			err = ctx.RegisterEncoding(tt.encodingID, tt.encodingFn())
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+type testPlc struct{}
+
+func (testPlc) HistoryFrame(frame []float32) {}
+
+func (testPlc) LostFrame(lostFrame []float32, nextFrame []float32) {}
+
+func TestContext_RegisterPlc(t *testing.T) {
+	tests := []struct {
+		name     string
+		pluginID int
+		factory  PlcFactory
+		wantErr  error
+	}{
+		{
+			name:     "ok",
+			pluginID: 1000,
+			factory: func(encoding MediaEncoding) Plc {
+				return testPlc{}
+			},
+			wantErr: nil,
+		},
+		{
+			name:     "bad id",
+			pluginID: 1,
+			factory: func(encoding MediaEncoding) Plc {
+				return testPlc{}
+			},
+			wantErr: newNativeErr("roc_context_register_plc()", -1),
+		},
+		{
+			name:     "nil factory",
+			pluginID: 1000,
+			factory:  nil,
+			wantErr:  newErr(ErrInvalidArgument, "factory is nil"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+			require.NotNil(t, ctx)
+
+			err = ctx.RegisterPlc(tt.pluginID, tt.factory)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
 
 			err = ctx.Close()
 			require.NoError(t, err)
@@ -146,7 +197,7 @@ func TestContext_ReferenceCounter(t *testing.T) {
 
 			err = ctx.Close()
 			if tt.wantErr != nil {
-				require.Equal(t, tt.wantErr.Error(), err.Error())
+				require.Equal(t, tt.wantErr.Error(), stripNativeLog(err).Error())
 			} else {
 				require.NoError(t, err)
 			}
@@ -179,6 +230,14 @@ func TestContext_Close(t *testing.T) {
 				return context.RegisterEncoding(50, makeMediaEncoding())
 			},
 		},
+		{
+			name: "RegisterPlc after close",
+			operation: func(context *Context) error {
+				return context.RegisterPlc(1000, func(encoding MediaEncoding) Plc {
+					return testPlc{}
+				})
+			},
+		},
 	}
 	for _, tt := range cases {
 		ctx, err := OpenContext(makeContextConfig())
@@ -188,6 +247,6 @@ func TestContext_Close(t *testing.T) {
 		err = ctx.Close()
 		require.NoError(t, err)
 
-		require.Equal(t, errors.New("context is closed"), tt.operation(ctx))
+		require.Equal(t, newErr(ErrClosed, "context is closed"), tt.operation(ctx))
 	}
 }
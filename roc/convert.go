@@ -4,6 +4,7 @@ import (
 	"C"
 	"fmt"
 	"time"
+	"unsafe"
 )
 
 type (
@@ -14,8 +15,8 @@ type (
 
 func go2cStr(str string) ([]char, error) {
 	charArray := make([]char, len(str)+1)
-	for ind, r := range str {
-		c := (char)(r)
+	for ind := 0; ind < len(str); ind++ {
+		c := (char)(str[ind])
 		if c == '\x00' {
 			return nil, fmt.Errorf("unexpected zero byte in the string: %q", str)
 		}
@@ -36,6 +37,15 @@ func c2goStr(charArray []char) string {
 	return string(byteArray)
 }
 
+// makes slice pointing to C array, without copying
+// slice is valid only while C array is alive
+func c2goFloats(samples *C.float, samplesSize C.ulong) []float32 {
+	if samples == nil || samplesSize == 0 {
+		return nil
+	}
+	return (*[1 << 28]float32)(unsafe.Pointer(samples))[:samplesSize:samplesSize]
+}
+
 func go2cBool(b bool) C.uint {
 	if b {
 		return 1
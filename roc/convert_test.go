@@ -68,6 +68,19 @@ func TestConvert_go2cStr(t *testing.T) {
 	}
 }
 
+func TestConvert_go2cStrUTF8(t *testing.T) {
+	str := "пример.рф"
+
+	got, err := go2cStr(str)
+	assert.NoError(t, err)
+	assert.Len(t, got, len(str)+1)
+
+	for i := 0; i < len(str); i++ {
+		assert.Equal(t, str[i], byte(got[i]))
+	}
+	assert.Equal(t, str, c2goStr(got))
+}
+
 func TestConvert_c2goStr(t *testing.T) {
 	tests := []struct {
 		name   string
@@ -0,0 +1,349 @@
+package roc
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+// Option configures Dial() and Listen().
+type Option func(*dialOptions)
+
+type dialOptions struct {
+	context         *Context
+	contextConfig   *ContextConfig
+	senderConfig    *SenderConfig
+	receiverConfig  *ReceiverConfig
+	interfaceConfig *InterfaceConfig
+	slot            Slot
+}
+
+// WithContext makes Dial() or Listen() attach to the given context instead of
+// the shared one. The context is not closed when the returned object is closed.
+// Can't be combined with WithContextConfig().
+func WithContext(ctx *Context) Option {
+	return func(o *dialOptions) {
+		o.context = ctx
+	}
+}
+
+// WithContextConfig makes Dial() or Listen() open a dedicated context with the
+// given config. The context is closed when the returned object is closed.
+// Can't be combined with WithContext().
+func WithContextConfig(config ContextConfig) Option {
+	return func(o *dialOptions) {
+		o.contextConfig = &config
+	}
+}
+
+// WithSenderConfig overrides default sender config used by Dial().
+func WithSenderConfig(config SenderConfig) Option {
+	return func(o *dialOptions) {
+		o.senderConfig = &config
+	}
+}
+
+// WithReceiverConfig overrides default receiver config used by Listen().
+func WithReceiverConfig(config ReceiverConfig) Option {
+	return func(o *dialOptions) {
+		o.receiverConfig = &config
+	}
+}
+
+// WithInterfaceConfig makes Dial() or Listen() configure every interface with
+// the given config before connecting or binding it.
+func WithInterfaceConfig(config InterfaceConfig) Option {
+	return func(o *dialOptions) {
+		o.interfaceConfig = &config
+	}
+}
+
+// WithSlot makes Dial() or Listen() use given slot instead of SlotDefault.
+func WithSlot(slot Slot) Option {
+	return func(o *dialOptions) {
+		o.slot = slot
+	}
+}
+
+// Default frame encoding used by Dial() and Listen().
+var defaultDialFrameEncoding = MediaEncoding{
+	Rate:     44100,
+	Format:   FormatPcmFloat32,
+	Channels: ChannelLayoutStereo,
+}
+
+// Dial opens a sender and connects it to remote receiver.
+//
+// uri is either compact "roc://" URI of the whole stream (see
+// ParseEndpointSet), or URI of a single endpoint (see ParseEndpoint), which is
+// connected to the interface of its protocol (see Protocol.Interface).
+//
+// By default, sender is attached to a context shared by all objects created
+// by Dial() and Listen(), which is closed when the last of them is closed. The
+// sender uses 44100 Hz stereo frames, PacketEncodingAvpL16Stereo, internal
+// clock, and FEC encoding matching the URI. Use options to override defaults.
+//
+// ctx is checked before and after opening; it doesn't affect the returned
+// sender after Dial() returns. Closing the sender releases all resources
+// acquired by Dial().
+func Dial(ctx context.Context, uri string, opts ...Option) (sender *Sender, err error) {
+	logWrite(LogDebug, "entering Dial(): uri=%q", uri)
+	defer func() {
+		logWrite(LogDebug, "leaving Dial(): sender=%p err=%#v", sender, err)
+	}()
+
+	options := makeDialOptions(opts)
+
+	entries, err := dialParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	config := dialSenderConfig(options, entries)
+
+	rocCtx, release, err := dialAcquireContext(options)
+	if err != nil {
+		return nil, err
+	}
+
+	sender, err = OpenSender(rocCtx, config)
+	if err != nil {
+		_ = release()
+		return nil, err
+	}
+	sender.release = release
+
+	err = dialApply(options, entries,
+		func(iface Interface, config InterfaceConfig) error {
+			return sender.Configure(options.slot, iface, config)
+		},
+		func(iface Interface, endpoint *Endpoint) error {
+			return sender.Connect(options.slot, iface, endpoint)
+		})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = sender.Close()
+		return nil, err
+	}
+
+	return sender, nil
+}
+
+// Listen opens a receiver and binds it to local endpoints.
+//
+// uri has the same format as for Dial(). If ports are zero, receiver is bound
+// to randomly chosen ports.
+//
+// By default, receiver is attached to a context shared by all objects created
+// by Dial() and Listen(), which is closed when the last of them is closed. The
+// receiver produces 44100 Hz stereo frames and uses internal clock. Use options
+// to override defaults.
+//
+// ctx is checked before and after opening; it doesn't affect the returned
+// receiver after Listen() returns. Closing the receiver releases all resources
+// acquired by Listen().
+func Listen(ctx context.Context, uri string, opts ...Option) (receiver *Receiver, err error) {
+	logWrite(LogDebug, "entering Listen(): uri=%q", uri)
+	defer func() {
+		logWrite(LogDebug, "leaving Listen(): receiver=%p err=%#v", receiver, err)
+	}()
+
+	options := makeDialOptions(opts)
+
+	entries, err := dialParseURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	config := ReceiverConfig{
+		FrameEncoding: defaultDialFrameEncoding,
+		ClockSource:   ClockSourceInternal,
+	}
+	if options.receiverConfig != nil {
+		config = *options.receiverConfig
+	}
+
+	rocCtx, release, err := dialAcquireContext(options)
+	if err != nil {
+		return nil, err
+	}
+
+	receiver, err = OpenReceiver(rocCtx, config)
+	if err != nil {
+		_ = release()
+		return nil, err
+	}
+	receiver.release = release
+
+	err = dialApply(options, entries,
+		func(iface Interface, config InterfaceConfig) error {
+			return receiver.Configure(options.slot, iface, config)
+		},
+		func(iface Interface, endpoint *Endpoint) error {
+			return receiver.Bind(options.slot, iface, endpoint)
+		})
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		_ = receiver.Close()
+		return nil, err
+	}
+
+	return receiver, nil
+}
+
+func makeDialOptions(opts []Option) dialOptions {
+	options := dialOptions{
+		slot: SlotDefault,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+// parses either "roc://" URI into multiple endpoints, or single endpoint URI
+func dialParseURI(uri string) ([]ifaceEndpoint, error) {
+	if strings.HasPrefix(uri, endpointSetScheme+"://") {
+		set, err := ParseEndpointSet(uri)
+		if err != nil {
+			return nil, err
+		}
+		return set.entries()
+	}
+
+	endpoint, err := ParseEndpoint(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	iface, _ := endpoint.Protocol.Interface()
+
+	return []ifaceEndpoint{{iface, endpoint}}, nil
+}
+
+func dialSenderConfig(options dialOptions, entries []ifaceEndpoint) SenderConfig {
+	if options.senderConfig != nil {
+		return *options.senderConfig
+	}
+
+	config := SenderConfig{
+		FrameEncoding:  defaultDialFrameEncoding,
+		PacketEncoding: PacketEncodingAvpL16Stereo,
+		ClockSource:    ClockSourceInternal,
+	}
+
+	for _, entry := range entries {
+		if fecEncoding, ok := entry.endpoint.Protocol.FecEncoding(); ok {
+			config.FecEncoding = fecEncoding
+			break
+		}
+	}
+
+	return config
+}
+
+// configures interfaces if needed and connects or binds endpoints; on
+// failure, the caller closes the object, so no rollback is needed
+func dialApply(
+	options dialOptions,
+	entries []ifaceEndpoint,
+	configure func(iface Interface, config InterfaceConfig) error,
+	apply func(iface Interface, endpoint *Endpoint) error,
+) error {
+	for _, entry := range entries {
+		if options.interfaceConfig != nil {
+			if err := configure(entry.iface, *options.interfaceConfig); err != nil {
+				return err
+			}
+		}
+		if err := apply(entry.iface, entry.endpoint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns context for Dial() or Listen(), and function that releases it
+func dialAcquireContext(options dialOptions) (*Context, func() error, error) {
+	if options.context != nil && options.contextConfig != nil {
+		return nil, nil, newErr(ErrInvalidArgument,
+			"WithContext() and WithContextConfig() can't be used together")
+	}
+
+	if options.context != nil {
+		return options.context, func() error { return nil }, nil
+	}
+
+	if options.contextConfig != nil {
+		ctx, err := OpenContext(*options.contextConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		return ctx, ctx.Close, nil
+	}
+
+	ctx, err := sharedContext.acquire()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ctx, sharedContext.release, nil
+}
+
+// context shared by Dial() and Listen(), reference-counted
+var sharedContext refContext
+
+type refContext struct {
+	mu   sync.Mutex
+	ctx  *Context
+	refs int
+}
+
+func (rc *refContext) acquire() (*Context, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.ctx == nil {
+		ctx, err := OpenContext(ContextConfig{})
+		if err != nil {
+			return nil, err
+		}
+		rc.ctx = ctx
+	}
+
+	rc.refs++
+
+	return rc.ctx, nil
+}
+
+func (rc *refContext) release() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	rc.refs--
+	if rc.refs != 0 {
+		return nil
+	}
+
+	// if close fails, keep context, so that it's reused by next acquire and
+	// closed again by next release
+	if err := rc.ctx.Close(); err != nil {
+		return err
+	}
+
+	rc.ctx = nil
+
+	return nil
+}
@@ -0,0 +1,287 @@
+package roc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDial(t *testing.T) {
+	cases := []struct {
+		name string
+		uri  string
+		opts []Option
+	}{
+		{
+			name: "compact uri",
+			uri:  "roc://127.0.0.1:10001",
+		},
+		{
+			name: "compact uri without fec",
+			uri:  "roc://127.0.0.1:10001?fec=disable",
+		},
+		{
+			name: "single endpoint",
+			uri:  "rtp://127.0.0.1:10001",
+		},
+		{
+			name: "options",
+			uri:  "roc://127.0.0.1:10001?fec=disable&control=none",
+			opts: []Option{
+				WithContextConfig(makeContextConfig()),
+				WithSenderConfig(SenderConfig{
+					FrameEncoding:  makeMediaEncoding(),
+					PacketEncoding: PacketEncodingAvpL16Stereo,
+					FecEncoding:    FecEncodingDisable,
+				}),
+				WithInterfaceConfig(makeInterfaceConfig()),
+				WithSlot(1),
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := Dial(context.Background(), tt.uri, tt.opts...)
+			require.NoError(t, err)
+			require.NotNil(t, sender)
+
+			require.NoError(t, sender.WriteFloats(make([]float32, 100)))
+
+			require.NoError(t, sender.Close())
+			require.Equal(t, 0, sharedContext.refs)
+		})
+	}
+}
+
+func TestListen(t *testing.T) {
+	cases := []struct {
+		name string
+		uri  string
+		opts []Option
+	}{
+		{
+			name: "compact uri",
+			uri:  "roc://127.0.0.1:0",
+		},
+		{
+			name: "compact uri with ldpc",
+			uri:  "roc://127.0.0.1:0?fec=ldpc",
+		},
+		{
+			name: "single endpoint",
+			uri:  "rtp://127.0.0.1:0",
+		},
+		{
+			name: "options",
+			uri:  "roc://127.0.0.1:0",
+			opts: []Option{
+				WithContextConfig(makeContextConfig()),
+				WithReceiverConfig(makeReceiverConfig()),
+				WithInterfaceConfig(InterfaceConfig{ReuseAddress: true}),
+				WithSlot(1),
+			},
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, err := Listen(context.Background(), tt.uri, tt.opts...)
+			require.NoError(t, err)
+			require.NotNil(t, receiver)
+
+			require.NoError(t, receiver.Close())
+			require.Equal(t, 0, sharedContext.refs)
+		})
+	}
+}
+
+func TestDial_SharedContext(t *testing.T) {
+	receiver, err := Listen(context.Background(), "roc://127.0.0.1:0")
+	require.NoError(t, err)
+
+	sender, err := Dial(context.Background(), "roc://127.0.0.1:10001")
+	require.NoError(t, err)
+
+	require.Equal(t, 2, sharedContext.refs)
+	require.NotNil(t, sharedContext.ctx)
+
+	require.NoError(t, receiver.Close())
+	require.Equal(t, 1, sharedContext.refs)
+	require.NotNil(t, sharedContext.ctx)
+
+	require.NoError(t, sender.Close())
+	require.Equal(t, 0, sharedContext.refs)
+	require.Nil(t, sharedContext.ctx)
+
+	// closing again doesn't release twice
+	require.NoError(t, sender.Close())
+	require.Equal(t, 0, sharedContext.refs)
+}
+
+func TestDial_UserContext(t *testing.T) {
+	rocCtx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	sender, err := Dial(context.Background(), "roc://127.0.0.1:10001", WithContext(rocCtx))
+	require.NoError(t, err)
+
+	receiver, err := Listen(context.Background(), "roc://127.0.0.1:0", WithContext(rocCtx))
+	require.NoError(t, err)
+
+	require.Equal(t, 0, sharedContext.refs)
+
+	require.NoError(t, sender.Close())
+	require.NoError(t, receiver.Close())
+
+	// context is still open and not used
+	require.NoError(t, rocCtx.Close())
+}
+
+func TestDial_ReleaseError(t *testing.T) {
+	var rc refContext
+
+	rocCtx, err := rc.acquire()
+	require.NoError(t, err)
+
+	sender, err := OpenSender(rocCtx, makeSenderConfig())
+	require.NoError(t, err)
+
+	// context can't be closed while sender is attached
+	require.Error(t, rc.release())
+	require.Equal(t, 0, rc.refs)
+	require.Equal(t, rocCtx, rc.ctx)
+
+	require.NoError(t, sender.Close())
+
+	// same context is reused and finally closed
+	reusedCtx, err := rc.acquire()
+	require.NoError(t, err)
+	require.Equal(t, rocCtx, reusedCtx)
+
+	require.NoError(t, rc.release())
+	require.Nil(t, rc.ctx)
+}
+
+func TestDial_Errors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rocCtx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+	defer rocCtx.Close()
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		uri     string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "bad uri",
+			ctx:     context.Background(),
+			uri:     "rtp://127.0.0.1",
+			wantErr: ErrBadEndpoint,
+		},
+		{
+			name:    "bad compact uri",
+			ctx:     context.Background(),
+			uri:     "roc://127.0.0.1:10001?fec=foo",
+			wantErr: ErrBadEndpoint,
+		},
+		{
+			name:    "cancelled",
+			ctx:     cancelled,
+			uri:     "roc://127.0.0.1:10001",
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "bad config",
+			ctx:     context.Background(),
+			uri:     "roc://127.0.0.1:10001",
+			opts:    []Option{WithSenderConfig(SenderConfig{})},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name: "fec mismatch",
+			ctx:  context.Background(),
+			uri:  "roc://127.0.0.1:10001?fec=ldpc",
+			opts: []Option{WithSenderConfig(SenderConfig{
+				FrameEncoding:  makeMediaEncoding(),
+				PacketEncoding: PacketEncodingAvpL16Stereo,
+				FecEncoding:    FecEncodingRs8m,
+			})},
+			wantErr: ErrBadEndpoint,
+		},
+		{
+			name: "context and context config",
+			ctx:  context.Background(),
+			uri:  "roc://127.0.0.1:10001",
+			opts: []Option{
+				WithContext(rocCtx),
+				WithContextConfig(makeContextConfig()),
+			},
+			wantErr: ErrInvalidArgument,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			sender, err := Dial(tt.ctx, tt.uri, tt.opts...)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.wantErr))
+			require.Nil(t, sender)
+
+			require.Equal(t, 0, sharedContext.refs)
+			require.Nil(t, sharedContext.ctx)
+		})
+	}
+}
+
+func TestListen_Errors(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		uri     string
+		opts    []Option
+		wantErr error
+	}{
+		{
+			name:    "bad uri",
+			ctx:     context.Background(),
+			uri:     "rtp://127.0.0.1",
+			wantErr: ErrBadEndpoint,
+		},
+		{
+			name:    "cancelled",
+			ctx:     cancelled,
+			uri:     "roc://127.0.0.1:0",
+			wantErr: context.Canceled,
+		},
+		{
+			name:    "bad config",
+			ctx:     context.Background(),
+			uri:     "roc://127.0.0.1:0",
+			opts:    []Option{WithReceiverConfig(ReceiverConfig{})},
+			wantErr: ErrInvalidConfig,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver, err := Listen(tt.ctx, tt.uri, tt.opts...)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.wantErr))
+			require.Nil(t, receiver)
+
+			require.Equal(t, 0, sharedContext.refs)
+			require.Nil(t, sharedContext.ctx)
+		})
+	}
+}
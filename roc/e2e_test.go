@@ -1,10 +1,12 @@
 package roc
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -181,6 +183,12 @@ func TestEnd2End_Default(t *testing.T) {
 				}
 			}
 
+			slotMetrics, connMetrics, err := e.Receiver.Query(SlotDefault)
+			require.NoError(t, err)
+			require.Equal(t, uint32(1), slotMetrics.ConnectionCount)
+			require.Len(t, connMetrics, 1)
+			require.NotZero(t, connMetrics[0].ExpectedPackets)
+
 			endChan <- struct{}{}
 			wait.Wait()
 		})
@@ -253,6 +261,189 @@ func TestEnd2End_Blocking(t *testing.T) {
 
 }
 
+func TestEnd2End_EncoderDecoder(t *testing.T) {
+	tests := []struct {
+		name        string
+		fecEncoding FecEncoding
+		sourceProto Protocol
+		repairProto Protocol
+	}{
+		{
+			name:        "default",
+			fecEncoding: FecEncodingDisable,
+			sourceProto: ProtoRtp,
+		},
+		{
+			name:        "fec",
+			fecEncoding: FecEncodingRs8m,
+			sourceProto: ProtoRtpRs8mSource,
+			repairProto: ProtoRs8mRepair,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+			defer ctx.Close()
+
+			decoderConfig := makeReceiverConfig()
+			decoderConfig.ClockSource = ClockSourceExternal
+			decoderConfig.LatencyTunerProfile = LatencyTunerProfileIntact
+			decoderConfig.TargetLatency = TargetLatency
+
+			decoder, err := OpenReceiverDecoder(ctx, decoderConfig)
+			require.NoError(t, err)
+			defer decoder.Close()
+
+			encoderConfig := makeSenderConfig()
+			encoderConfig.ClockSource = ClockSourceExternal
+			encoderConfig.FecEncoding = tt.fecEncoding
+			encoderConfig.LatencyTunerProfile = LatencyTunerProfileIntact
+
+			encoder, err := OpenSenderEncoder(ctx, encoderConfig)
+			require.NoError(t, err)
+			defer encoder.Close()
+
+			ifaces := []Interface{InterfaceAudioSource}
+
+			require.NoError(t, encoder.Activate(InterfaceAudioSource, tt.sourceProto))
+			require.NoError(t, decoder.Activate(InterfaceAudioSource, tt.sourceProto))
+
+			if tt.repairProto != 0 {
+				ifaces = append(ifaces, InterfaceAudioRepair)
+
+				require.NoError(t, encoder.Activate(InterfaceAudioRepair, tt.repairProto))
+				require.NoError(t, decoder.Activate(InterfaceAudioRepair, tt.repairProto))
+			}
+
+			samplesCnt := 100
+			testSamples := generateTestSamples(samplesCnt)
+
+			packet := make([]byte, makeContextConfig().MaxPacketSize)
+
+			validationState := validationState{}
+			samples := make([]float32, samplesCnt)
+			for validationState.nonZeroSamplesCount < 10000 {
+				err = encoder.PushFrame(testSamples)
+				require.NoError(t, err)
+
+				for _, iface := range ifaces {
+					for {
+						n, err := encoder.PopPacket(iface, packet)
+						if errors.Is(err, ErrNoPacket) {
+							break
+						}
+						require.NoError(t, err)
+						err = decoder.PushPacket(iface, packet[:n])
+						require.NoError(t, err)
+					}
+				}
+
+				err = decoder.PopFrame(samples)
+				require.NoError(t, err)
+
+				validateSamples(t, &validationState, samples, samplesCnt)
+			}
+		})
+	}
+}
+
+type e2ePlc struct {
+	historyFrames *int64
+	lostFrames    *int64
+}
+
+func (p e2ePlc) HistoryFrame(frame []float32) {
+	atomic.AddInt64(p.historyFrames, 1)
+}
+
+func (p e2ePlc) LostFrame(lostFrame []float32, nextFrame []float32) {
+	atomic.AddInt64(p.lostFrames, 1)
+	for i := range lostFrame {
+		lostFrame[i] = 0.75
+	}
+}
+
+func TestEnd2End_Plc(t *testing.T) {
+	const pluginID = 1000
+
+	var historyFrames, lostFrames int64
+
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	err = ctx.RegisterPlc(pluginID, func(encoding MediaEncoding) Plc {
+		return e2ePlc{
+			historyFrames: &historyFrames,
+			lostFrames:    &lostFrames,
+		}
+	})
+	require.NoError(t, err)
+
+	decoderConfig := makeReceiverConfig()
+	decoderConfig.ClockSource = ClockSourceExternal
+	decoderConfig.LatencyTunerProfile = LatencyTunerProfileIntact
+	decoderConfig.TargetLatency = TargetLatency
+	decoderConfig.PlcBackend = pluginID
+
+	decoder, err := OpenReceiverDecoder(ctx, decoderConfig)
+	require.NoError(t, err)
+	defer decoder.Close()
+
+	encoderConfig := makeSenderConfig()
+	encoderConfig.ClockSource = ClockSourceExternal
+	encoderConfig.FecEncoding = FecEncodingDisable
+
+	encoder, err := OpenSenderEncoder(ctx, encoderConfig)
+	require.NoError(t, err)
+	defer encoder.Close()
+
+	require.NoError(t, encoder.Activate(InterfaceAudioSource, ProtoRtp))
+	require.NoError(t, decoder.Activate(InterfaceAudioSource, ProtoRtp))
+
+	samplesCnt := 100
+	testSamples := generateTestSamples(samplesCnt)
+
+	packet := make([]byte, makeContextConfig().MaxPacketSize)
+	packetCnt := 0
+
+	concealedSamples := 0
+	samples := make([]float32, samplesCnt)
+	for concealedSamples < 1000 {
+		err = encoder.PushFrame(testSamples)
+		require.NoError(t, err)
+
+		for {
+			n, err := encoder.PopPacket(InterfaceAudioSource, packet)
+			if errors.Is(err, ErrNoPacket) {
+				break
+			}
+			require.NoError(t, err)
+			packetCnt++
+			// drop every 5th packet
+			if packetCnt%5 == 0 {
+				continue
+			}
+			err = decoder.PushPacket(InterfaceAudioSource, packet[:n])
+			require.NoError(t, err)
+		}
+
+		err = decoder.PopFrame(samples)
+		require.NoError(t, err)
+
+		for _, s := range samples {
+			if s == 0.75 {
+				concealedSamples++
+			}
+		}
+	}
+
+	require.NotZero(t, atomic.LoadInt64(&historyFrames))
+	require.NotZero(t, atomic.LoadInt64(&lostFrames))
+}
+
 func generateTestSamples(samplesCnt int) []float32 {
 	testSamples := make([]float32, samplesCnt)
 	for i := 0; i < samplesCnt/NumChannels; i++ {
@@ -73,9 +73,26 @@ type Endpoint struct {
 }
 
 // ParseEndpoint decomposes URI string into Endpoint instance.
+//
+// Parsing is implemented in Go and doesn't call native library. Returned
+// error matches ErrBadEndpoint.
 func ParseEndpoint(uri string) (*Endpoint, error) {
 	checkVersionFn()
 
+	return parseEndpointURI(uri)
+}
+
+// URI composes Endpoint instance into URI string.
+//
+// Composing is implemented in Go and doesn't call native library. Returned
+// error matches ErrBadEndpoint.
+func (endp *Endpoint) URI() (string, error) {
+	return endp.formatURI()
+}
+
+// parses URI using native library
+// used to check that Go parser behaves identically
+func parseEndpointNative(uri string) (*Endpoint, error) {
 	var errCode C.int
 
 	var cEndp *C.roc_endpoint
@@ -96,11 +113,14 @@ func ParseEndpoint(uri string) (*Endpoint, error) {
 
 	cURI, err := go2cStr(uri)
 	if err != nil {
-		return nil, fmt.Errorf("invalid uri: %w", err)
+		return nil, wrapErr(ErrBadEndpoint, fmt.Errorf("invalid uri: %w", err))
 	}
-	errCode = C.roc_endpoint_set_uri(cEndp, (*C.char)(&cURI[0]))
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_endpoint_set_uri(cEndp, (*C.char)(&cURI[0]))
+	})
 	if errCode != 0 {
-		return nil, newNativeErr("roc_endpoint_set_uri()", errCode)
+		return nil, wrapErr(ErrBadEndpoint,
+			newNativeErrLog("roc_endpoint_set_uri()", errCode, messages))
 	}
 
 	endp := new(Endpoint)
@@ -112,8 +132,9 @@ func ParseEndpoint(uri string) (*Endpoint, error) {
 	return endp, nil
 }
 
-// URI composes Endpoint instance into URI string.
-func (endp *Endpoint) URI() (string, error) {
+// composes URI using native library
+// used to check that Go formatter behaves identically
+func (endp *Endpoint) uriNative() (string, error) {
 	var errCode C.int
 
 	var cEndp *C.roc_endpoint
@@ -136,13 +157,13 @@ func (endp *Endpoint) URI() (string, error) {
 	var cURISize C.size_t
 	errCode = C.roc_endpoint_get_uri(cEndp, nil, &cURISize)
 	if errCode != 0 {
-		return "", newNativeErr("roc_endpoint_get_uri()", errCode)
+		return "", wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_get_uri()", errCode))
 	}
 
 	cURI := make([]C.char, cURISize)
 	errCode = C.roc_endpoint_get_uri(cEndp, (*C.char)(&cURI[0]), &cURISize)
 	if errCode != 0 {
-		return "", newNativeErr("roc_endpoint_get_uri()", errCode)
+		return "", wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_get_uri()", errCode))
 	}
 
 	uri := c2goStr(cURI)
@@ -157,20 +178,20 @@ func (endp *Endpoint) fromC(cEndp *C.roc_endpoint) error {
 	var cProto C.roc_protocol
 	errCode = C.roc_endpoint_get_protocol(cEndp, &cProto)
 	if errCode != 0 {
-		return newNativeErr("roc_endpoint_get_protocol()", errCode)
+		return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_get_protocol()", errCode))
 	}
 	endp.Protocol = Protocol(cProto)
 
 	var cHostSize C.size_t
 	errCode = C.roc_endpoint_get_host(cEndp, nil, &cHostSize)
 	if errCode != 0 {
-		return newNativeErr("roc_endpoint_get_host()", errCode)
+		return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_get_host()", errCode))
 	}
 
 	cHost := make([]C.char, cHostSize)
 	errCode = C.roc_endpoint_get_host(cEndp, (*C.char)(&cHost[0]), &cHostSize)
 	if errCode != 0 {
-		return newNativeErr("roc_endpoint_get_host()", errCode)
+		return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_get_host()", errCode))
 	}
 	endp.Host = c2goStr(cHost)
 
@@ -188,7 +209,7 @@ func (endp *Endpoint) fromC(cEndp *C.roc_endpoint) error {
 		cResource := make([]C.char, cResourceSize)
 		errCode = C.roc_endpoint_get_resource(cEndp, (*C.char)(&cResource[0]), &cResourceSize)
 		if errCode != 0 {
-			return newNativeErr("roc_endpoint_get_resource()", errCode)
+			return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_get_resource()", errCode))
 		}
 		endp.Resource = c2goStr(cResource)
 	}
@@ -202,35 +223,35 @@ func (endp *Endpoint) toC(cEndp *C.roc_endpoint) error {
 
 	errCode = C.roc_endpoint_set_protocol(cEndp, C.roc_protocol(endp.Protocol))
 	if errCode != 0 {
-		return newNativeErr("roc_endpoint_set_protocol()", errCode)
+		return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_protocol()", errCode))
 	}
 
 	if endp.Host != "" {
 		cHost, err := go2cStr(endp.Host)
 		if err != nil {
-			return fmt.Errorf("invalid host: %w", err)
+			return wrapErr(ErrBadEndpoint, fmt.Errorf("invalid host: %w", err))
 		}
 		errCode = C.roc_endpoint_set_host(cEndp, (*C.char)(&cHost[0]))
 		if errCode != 0 {
-			return newNativeErr("roc_endpoint_set_host()", errCode)
+			return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_host()", errCode))
 		}
 	}
 
 	if endp.Port != -1 {
 		errCode = C.roc_endpoint_set_port(cEndp, C.int(endp.Port))
 		if errCode != 0 {
-			return newNativeErr("roc_endpoint_set_port()", errCode)
+			return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_port()", errCode))
 		}
 	}
 
 	if endp.Resource != "" {
 		cResource, err := go2cStr(endp.Resource)
 		if err != nil {
-			return fmt.Errorf("invalid resource: %w", err)
+			return wrapErr(ErrBadEndpoint, fmt.Errorf("invalid resource: %w", err))
 		}
 		errCode = C.roc_endpoint_set_resource(cEndp, (*C.char)(&cResource[0]))
 		if errCode != 0 {
-			return newNativeErr("roc_endpoint_set_resource()", errCode)
+			return wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_resource()", errCode))
 		}
 	}
 
@@ -0,0 +1,168 @@
+package roc
+
+import (
+	"errors"
+	"fmt"
+)
+
+// EndpointSet is a group of endpoints for one slot: source, repair, and control.
+//
+// Can be created manually or derived from a single address using
+// NewEndpointSet(), and then passed to Sender.ConnectSet() or
+// Receiver.BindSet().
+type EndpointSet struct {
+	// Endpoint for InterfaceAudioSource. Required.
+	Source *Endpoint
+
+	// Endpoint for InterfaceAudioRepair. Nil if FEC is disabled.
+	Repair *Endpoint
+
+	// Endpoint for InterfaceAudioControl. Optional.
+	Control *Endpoint
+}
+
+// NewEndpointSet derives source, repair, and control endpoints from one address.
+//
+// Protocols are selected according to fecEncoding (see
+// FecEncoding.SourceProtocol() and FecEncoding.RepairProtocol()). Ports are
+// assigned as follows:
+//   - source: basePort
+//   - repair: basePort + 1 (omitted if FEC is disabled)
+//   - control: basePort + 2
+//
+// If basePort is zero, all endpoints get zero port, which can be used to bind
+// receiver to randomly chosen ports.
+//
+// For example, host "192.168.0.1", basePort 10001, and FecEncodingRs8m produce
+// "rtp+rs8m://192.168.0.1:10001", "rs8m://192.168.0.1:10002", and
+// "rtcp://192.168.0.1:10003".
+func NewEndpointSet(host string, basePort int, fecEncoding FecEncoding) (*EndpointSet, error) {
+	if err := endpointValidateHost(host); err != nil {
+		return nil, err
+	}
+
+	if basePort < 0 || basePort > maxPort-2 {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid port: base port should be in range [0; %d], got %d",
+				maxPort-2, basePort))
+	}
+
+	sourceProto, ok := fecEncoding.SourceProtocol()
+	if !ok {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid protocol: unsupported fec encoding %v", fecEncoding))
+	}
+
+	set := &EndpointSet{
+		Source: &Endpoint{
+			Protocol: sourceProto,
+			Host:     host,
+			Port:     basePort,
+		},
+		Control: &Endpoint{
+			Protocol: ProtoRtcp,
+			Host:     host,
+			Port:     endpointSetPort(basePort, 2),
+		},
+	}
+
+	if repairProto, ok := fecEncoding.RepairProtocol(); ok {
+		set.Repair = &Endpoint{
+			Protocol: repairProto,
+			Host:     host,
+			Port:     endpointSetPort(basePort, 1),
+		}
+	}
+
+	return set, nil
+}
+
+// returns deep copy of set
+func (set *EndpointSet) clone() *EndpointSet {
+	if set == nil {
+		return nil
+	}
+
+	cloneEndpoint := func(endp *Endpoint) *Endpoint {
+		if endp == nil {
+			return nil
+		}
+		endpCopy := *endp
+		return &endpCopy
+	}
+
+	return &EndpointSet{
+		Source:  cloneEndpoint(set.Source),
+		Repair:  cloneEndpoint(set.Repair),
+		Control: cloneEndpoint(set.Control),
+	}
+}
+
+// returns port derived from base port, see NewEndpointSet
+func endpointSetPort(basePort int, offset int) int {
+	if basePort == 0 {
+		return 0
+	}
+	return basePort + offset
+}
+
+// endpoint paired with interface
+type ifaceEndpoint struct {
+	iface    Interface
+	endpoint *Endpoint
+}
+
+// returns non-nil endpoints of set in order in which they should be applied
+func (set *EndpointSet) entries() ([]ifaceEndpoint, error) {
+	if set == nil {
+		return nil, newErr(ErrBadEndpoint, "endpoint set is nil")
+	}
+	if set.Source == nil {
+		return nil, newErr(ErrBadEndpoint, "endpoint set has nil source endpoint")
+	}
+
+	entries := []ifaceEndpoint{
+		{InterfaceAudioSource, set.Source},
+	}
+	if set.Repair != nil {
+		entries = append(entries, ifaceEndpoint{InterfaceAudioRepair, set.Repair})
+	}
+	if set.Control != nil {
+		entries = append(entries, ifaceEndpoint{InterfaceAudioControl, set.Control})
+	}
+
+	return entries, nil
+}
+
+// applies fn to every endpoint of set; on failure, unlinks slot using unlink
+// if slot was modified, and returns the original error
+func applyEndpointSet(
+	set *EndpointSet,
+	fn func(iface Interface, endpoint *Endpoint) error,
+	unlink func() error,
+) error {
+	entries, err := set.entries()
+	if err != nil {
+		return err
+	}
+
+	for n, entry := range entries {
+		err := fn(entry.iface, entry.endpoint)
+		if err == nil {
+			continue
+		}
+
+		// failed native call marks slot broken, and previous calls modified it;
+		// otherwise the error was reported before touching the slot
+		var nativeErr NativeError
+		if n != 0 || errors.As(err, &nativeErr) {
+			if unlinkErr := unlink(); unlinkErr != nil {
+				logWrite(LogError, "can't roll back slot: %v", unlinkErr)
+			}
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,235 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointSet_New(t *testing.T) {
+	tests := []struct {
+		name        string
+		host        string
+		basePort    int
+		fecEncoding FecEncoding
+		source      string
+		repair      string
+		control     string
+	}{
+		{
+			name:        "rs8m",
+			host:        "192.168.0.1",
+			basePort:    10001,
+			fecEncoding: FecEncodingRs8m,
+			source:      "rtp+rs8m://192.168.0.1:10001",
+			repair:      "rs8m://192.168.0.1:10002",
+			control:     "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:        "default fec",
+			host:        "192.168.0.1",
+			basePort:    10001,
+			fecEncoding: FecEncodingDefault,
+			source:      "rtp+rs8m://192.168.0.1:10001",
+			repair:      "rs8m://192.168.0.1:10002",
+			control:     "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:        "ldpc",
+			host:        "[::1]",
+			basePort:    20000,
+			fecEncoding: FecEncodingLdpcStaircase,
+			source:      "rtp+ldpc://[::1]:20000",
+			repair:      "ldpc://[::1]:20001",
+			control:     "rtcp://[::1]:20002",
+		},
+		{
+			name:        "no fec",
+			host:        "example.com",
+			basePort:    10001,
+			fecEncoding: FecEncodingDisable,
+			source:      "rtp://example.com:10001",
+			control:     "rtcp://example.com:10003",
+		},
+		{
+			name:        "zero port",
+			host:        "0.0.0.0",
+			basePort:    0,
+			fecEncoding: FecEncodingRs8m,
+			source:      "rtp+rs8m://0.0.0.0:0",
+			repair:      "rs8m://0.0.0.0:0",
+			control:     "rtcp://0.0.0.0:0",
+		},
+		{
+			name:        "max port",
+			host:        "0.0.0.0",
+			basePort:    65533,
+			fecEncoding: FecEncodingRs8m,
+			source:      "rtp+rs8m://0.0.0.0:65533",
+			repair:      "rs8m://0.0.0.0:65534",
+			control:     "rtcp://0.0.0.0:65535",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewEndpointSet(tt.host, tt.basePort, tt.fecEncoding)
+			require.NoError(t, err)
+			require.NotNil(t, set)
+
+			uri, err := set.Source.URI()
+			require.NoError(t, err)
+			require.Equal(t, tt.source, uri)
+
+			if tt.repair == "" {
+				require.Nil(t, set.Repair)
+			} else {
+				uri, err = set.Repair.URI()
+				require.NoError(t, err)
+				require.Equal(t, tt.repair, uri)
+			}
+
+			uri, err = set.Control.URI()
+			require.NoError(t, err)
+			require.Equal(t, tt.control, uri)
+		})
+	}
+
+	errTests := []struct {
+		name        string
+		host        string
+		basePort    int
+		fecEncoding FecEncoding
+	}{
+		{
+			name:        "empty host",
+			host:        "",
+			basePort:    10001,
+			fecEncoding: FecEncodingRs8m,
+		},
+		{
+			name:        "negative port",
+			host:        "0.0.0.0",
+			basePort:    -1,
+			fecEncoding: FecEncodingRs8m,
+		},
+		{
+			name:        "port out of range",
+			host:        "0.0.0.0",
+			basePort:    65534,
+			fecEncoding: FecEncodingRs8m,
+		},
+		{
+			name:        "bad fec encoding",
+			host:        "0.0.0.0",
+			basePort:    10001,
+			fecEncoding: FecEncoding(100),
+		},
+	}
+
+	for _, tt := range errTests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := NewEndpointSet(tt.host, tt.basePort, tt.fecEncoding)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrBadEndpoint))
+			require.Nil(t, set)
+		})
+	}
+}
+
+func TestEndpointSet_Apply(t *testing.T) {
+	set, err := NewEndpointSet("127.0.0.1", 10001, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	nativeErr := newNativeErr("roc_sender_connect()", -1)
+	checkErr := newErr(ErrBadEndpoint, "bad endpoint")
+
+	tests := []struct {
+		name       string
+		set        *EndpointSet
+		failAt     int
+		failErr    error
+		wantCalls  []Interface
+		wantUnlink bool
+	}{
+		{
+			name:      "ok",
+			set:       set,
+			failAt:    -1,
+			wantCalls: []Interface{InterfaceAudioSource, InterfaceAudioRepair, InterfaceAudioControl},
+		},
+		{
+			name:      "source only",
+			set:       &EndpointSet{Source: set.Source},
+			failAt:    -1,
+			wantCalls: []Interface{InterfaceAudioSource},
+		},
+		{
+			name:       "first fails in native",
+			set:        set,
+			failAt:     0,
+			failErr:    nativeErr,
+			wantCalls:  []Interface{InterfaceAudioSource},
+			wantUnlink: true,
+		},
+		{
+			name:       "first fails in check",
+			set:        set,
+			failAt:     0,
+			failErr:    checkErr,
+			wantCalls:  []Interface{InterfaceAudioSource},
+			wantUnlink: false,
+		},
+		{
+			name:       "second fails",
+			set:        set,
+			failAt:     1,
+			failErr:    checkErr,
+			wantCalls:  []Interface{InterfaceAudioSource, InterfaceAudioRepair},
+			wantUnlink: true,
+		},
+		{
+			name:       "last fails",
+			set:        set,
+			failAt:     2,
+			failErr:    nativeErr,
+			wantCalls:  []Interface{InterfaceAudioSource, InterfaceAudioRepair, InterfaceAudioControl},
+			wantUnlink: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []Interface
+			unlinked := false
+
+			err := applyEndpointSet(tt.set,
+				func(iface Interface, endpoint *Endpoint) error {
+					calls = append(calls, iface)
+					if len(calls)-1 == tt.failAt {
+						return tt.failErr
+					}
+					return nil
+				},
+				func() error {
+					unlinked = true
+					return nil
+				})
+
+			require.Equal(t, tt.failErr, err)
+			require.Equal(t, tt.wantCalls, calls)
+			require.Equal(t, tt.wantUnlink, unlinked)
+		})
+	}
+
+	t.Run("nil set", func(t *testing.T) {
+		err := applyEndpointSet(nil, nil, nil)
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+	})
+
+	t.Run("nil source", func(t *testing.T) {
+		err := applyEndpointSet(&EndpointSet{Control: set.Control}, nil, nil)
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+	})
+}
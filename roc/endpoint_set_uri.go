@@ -0,0 +1,267 @@
+package roc
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// URI scheme of compact endpoint set form.
+const endpointSetScheme = "roc"
+
+// Source port used when compact URI doesn't specify port.
+const endpointSetDefaultPort = 10001
+
+// Compact URI form of EndpointSet:
+//
+//	roc://HOST[:PORT][?fec=ENCODING][&repair=PORT][&control=PORT|none]
+//
+// PORT is the source port and defaults to 10001. ENCODING is one of FecEncoding
+// text names ("rs8m", "ldpc", "disable", "default") and defaults to "rs8m".
+// Repair and control ports default to PORT+1 and PORT+2, or to zero if PORT is
+// zero (see NewEndpointSet). "control=none" omits control endpoint; repair
+// endpoint is omitted when FEC is disabled.
+//
+// For example, "roc://192.168.0.1:10001" is expanded to
+// "rtp+rs8m://192.168.0.1:10001", "rs8m://192.168.0.1:10002", and
+// "rtcp://192.168.0.1:10003".
+
+// ParseEndpointSet parses compact "roc://" URI into EndpointSet.
+//
+// Returned error matches ErrBadEndpoint.
+func ParseEndpointSet(uri string) (*EndpointSet, error) {
+	if strings.IndexByte(uri, 0) >= 0 {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: unexpected zero byte in %q", uri))
+	}
+
+	prefix := endpointSetScheme + "://"
+	if !strings.HasPrefix(uri, prefix) {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: expected %q scheme in %q", endpointSetScheme, uri))
+	}
+
+	authority, query := uri[len(prefix):], ""
+	if i := strings.IndexByte(authority, '?'); i >= 0 {
+		authority, query = authority[:i], authority[i+1:]
+	}
+	if strings.ContainsAny(authority, "/#") {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: unexpected path or fragment in %q", uri))
+	}
+
+	host, basePort, err := endpointSplitHostPort(authority)
+	if err != nil {
+		return nil, err
+	}
+	if err := endpointValidateHost(host); err != nil {
+		return nil, err
+	}
+	if basePort == -1 {
+		basePort = endpointSetDefaultPort
+	}
+
+	params, err := url.ParseQuery(query)
+	if err != nil {
+		return nil, newErr(ErrBadEndpoint, fmt.Sprintf("invalid uri: %v", err))
+	}
+
+	fecEncoding := FecEncodingDefault
+	repairPort := endpointSetPort(basePort, 1)
+	controlPort := endpointSetPort(basePort, 2)
+	hasControl := true
+
+	for key, values := range params {
+		if len(values) != 1 {
+			return nil, newErr(ErrBadEndpoint,
+				fmt.Sprintf("invalid uri: parameter %q should be specified once", key))
+		}
+		value := values[0]
+
+		switch key {
+		case "fec":
+			if err := fecEncoding.UnmarshalText([]byte(value)); err != nil {
+				return nil, newErr(ErrBadEndpoint, fmt.Sprintf("invalid uri: %v", err))
+			}
+		case "repair":
+			if repairPort, err = endpointParsePort(value); err != nil {
+				return nil, err
+			}
+		case "control":
+			if value == "none" {
+				hasControl = false
+			} else if controlPort, err = endpointParsePort(value); err != nil {
+				return nil, err
+			}
+		default:
+			return nil, newErr(ErrBadEndpoint,
+				fmt.Sprintf("invalid uri: unknown parameter %q", key))
+		}
+	}
+
+	sourceProto, ok := fecEncoding.SourceProtocol()
+	if !ok {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid protocol: unsupported fec encoding %v", fecEncoding))
+	}
+
+	set := &EndpointSet{
+		Source: &Endpoint{
+			Protocol: sourceProto,
+			Host:     host,
+			Port:     basePort,
+		},
+	}
+
+	if repairProto, ok := fecEncoding.RepairProtocol(); ok {
+		set.Repair = &Endpoint{
+			Protocol: repairProto,
+			Host:     host,
+			Port:     repairPort,
+		}
+	} else if _, ok := params["repair"]; ok {
+		return nil, newErr(ErrBadEndpoint,
+			"invalid uri: parameter \"repair\" can't be used when fec is disabled")
+	}
+
+	if hasControl {
+		set.Control = &Endpoint{
+			Protocol: ProtoRtcp,
+			Host:     host,
+			Port:     controlPort,
+		}
+	}
+
+	for _, endp := range []*Endpoint{set.Source, set.Repair, set.Control} {
+		if endp == nil {
+			continue
+		}
+		if err := endp.validate(); err != nil {
+			return nil, err
+		}
+	}
+
+	return set, nil
+}
+
+// URI formats EndpointSet into compact "roc://" URI.
+//
+// Fails if endpoints can't be represented in compact form, e.g. if they have
+// different hosts, or their protocols don't correspond to the same FEC
+// encoding. Parameters that match defaults are omitted.
+func (set *EndpointSet) URI() (string, error) {
+	if set == nil {
+		return "", newErr(ErrBadEndpoint, "endpoint set is nil")
+	}
+	if set.Source == nil {
+		return "", newErr(ErrBadEndpoint, "endpoint set has nil source endpoint")
+	}
+
+	source := set.Source
+	if err := source.validate(); err != nil {
+		return "", err
+	}
+
+	fecEncoding, ok := source.Protocol.FecEncoding()
+	if iface, _ := source.Protocol.Interface(); !ok || iface != InterfaceAudioSource {
+		return "", newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid protocol: %v can't be used for source endpoint",
+				source.Protocol))
+	}
+
+	var params []string
+
+	if fecEncoding != FecEncodingRs8m {
+		name, err := fecEncoding.MarshalText()
+		if err != nil {
+			return "", newErr(ErrBadEndpoint, fmt.Sprintf("invalid protocol: %v", err))
+		}
+		params = append(params, "fec="+string(name))
+	}
+
+	repairProto, hasRepair := fecEncoding.RepairProtocol()
+	switch {
+	case hasRepair && set.Repair == nil:
+		return "", newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: repair endpoint is required for %v", source.Protocol))
+	case !hasRepair && set.Repair != nil:
+		return "", newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: repair endpoint can't be used with %v", source.Protocol))
+	case hasRepair:
+		if err := endpointSetCheck(set.Repair, repairProto, source.Host); err != nil {
+			return "", err
+		}
+		if set.Repair.Port != endpointSetPort(source.Port, 1) {
+			params = append(params, "repair="+strconv.Itoa(set.Repair.Port))
+		}
+	}
+
+	if set.Control == nil {
+		params = append(params, "control=none")
+	} else {
+		if err := endpointSetCheck(set.Control, ProtoRtcp, source.Host); err != nil {
+			return "", err
+		}
+		if set.Control.Port != endpointSetPort(source.Port, 2) {
+			params = append(params, "control="+strconv.Itoa(set.Control.Port))
+		}
+	}
+
+	uri := endpointSetScheme + "://" + source.Host + ":" + strconv.Itoa(source.Port)
+	if len(params) != 0 {
+		uri += "?" + strings.Join(params, "&")
+	}
+
+	return uri, nil
+}
+
+// String returns compact URI of endpoint set.
+//
+// If endpoint set can't be represented as compact URI, returns a string
+// describing the error instead.
+func (set *EndpointSet) String() string {
+	uri, err := set.URI()
+	if err != nil {
+		return fmt.Sprintf("<bad endpoint set: %v>", err)
+	}
+	return uri
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// Endpoint set is marshaled to its compact URI.
+func (set *EndpointSet) MarshalText() ([]byte, error) {
+	uri, err := set.URI()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(uri), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// Endpoint set is parsed from its compact URI.
+func (set *EndpointSet) UnmarshalText(text []byte) error {
+	parsed, err := ParseEndpointSet(string(text))
+	if err != nil {
+		return err
+	}
+	*set = *parsed
+	return nil
+}
+
+// checks that endpoint can be represented in compact form
+func endpointSetCheck(endp *Endpoint, proto Protocol, host string) error {
+	if err := endp.validate(); err != nil {
+		return err
+	}
+	if endp.Protocol != proto {
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid protocol: expected %v, got %v", proto, endp.Protocol))
+	}
+	if endp.Host != host {
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid host: all endpoints should have host %q, got %q",
+				host, endp.Host))
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package roc
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEndpointSet_URI(t *testing.T) {
+	tests := []struct {
+		name      string
+		uri       string
+		canonical string
+		source    string
+		repair    string
+		control   string
+	}{
+		{
+			name:    "defaults",
+			uri:     "roc://192.168.0.1:10001",
+			source:  "rtp+rs8m://192.168.0.1:10001",
+			repair:  "rs8m://192.168.0.1:10002",
+			control: "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:      "default port",
+			uri:       "roc://192.168.0.1",
+			canonical: "roc://192.168.0.1:10001",
+			source:    "rtp+rs8m://192.168.0.1:10001",
+			repair:    "rs8m://192.168.0.1:10002",
+			control:   "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:      "explicit defaults",
+			uri:       "roc://192.168.0.1:10001?fec=rs8m&repair=10002&control=10003",
+			canonical: "roc://192.168.0.1:10001",
+			source:    "rtp+rs8m://192.168.0.1:10001",
+			repair:    "rs8m://192.168.0.1:10002",
+			control:   "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:      "default fec",
+			uri:       "roc://192.168.0.1:10001?fec=default",
+			canonical: "roc://192.168.0.1:10001",
+			source:    "rtp+rs8m://192.168.0.1:10001",
+			repair:    "rs8m://192.168.0.1:10002",
+			control:   "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:    "ldpc",
+			uri:     "roc://192.168.0.1:20000?fec=ldpc",
+			source:  "rtp+ldpc://192.168.0.1:20000",
+			repair:  "ldpc://192.168.0.1:20001",
+			control: "rtcp://192.168.0.1:20002",
+		},
+		{
+			name:    "no fec",
+			uri:     "roc://192.168.0.1:10001?fec=disable",
+			source:  "rtp://192.168.0.1:10001",
+			control: "rtcp://192.168.0.1:10003",
+		},
+		{
+			name:    "custom ports",
+			uri:     "roc://192.168.0.1:10001?repair=20000&control=30000",
+			source:  "rtp+rs8m://192.168.0.1:10001",
+			repair:  "rs8m://192.168.0.1:20000",
+			control: "rtcp://192.168.0.1:30000",
+		},
+		{
+			name:      "custom ports reordered",
+			uri:       "roc://192.168.0.1:10001?control=30000&fec=ldpc&repair=20000",
+			canonical: "roc://192.168.0.1:10001?fec=ldpc&repair=20000&control=30000",
+			source:    "rtp+ldpc://192.168.0.1:10001",
+			repair:    "ldpc://192.168.0.1:20000",
+			control:   "rtcp://192.168.0.1:30000",
+		},
+		{
+			name:   "no control",
+			uri:    "roc://192.168.0.1:10001?fec=disable&control=none",
+			source: "rtp://192.168.0.1:10001",
+		},
+		{
+			name:    "zero port",
+			uri:     "roc://0.0.0.0:0",
+			source:  "rtp+rs8m://0.0.0.0:0",
+			repair:  "rs8m://0.0.0.0:0",
+			control: "rtcp://0.0.0.0:0",
+		},
+		{
+			name:    "ipv6",
+			uri:     "roc://[::1]:10001",
+			source:  "rtp+rs8m://[::1]:10001",
+			repair:  "rs8m://[::1]:10002",
+			control: "rtcp://[::1]:10003",
+		},
+		{
+			name:    "hostname",
+			uri:     "roc://example.com:10001?fec=disable",
+			source:  "rtp://example.com:10001",
+			control: "rtcp://example.com:10003",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, err := ParseEndpointSet(tt.uri)
+			require.NoError(t, err)
+			require.NotNil(t, set)
+
+			checkEndpoint := func(endp *Endpoint, uri string) {
+				if uri == "" {
+					require.Nil(t, endp)
+					return
+				}
+				require.NotNil(t, endp)
+				endpURI, err := endp.URI()
+				require.NoError(t, err)
+				require.Equal(t, uri, endpURI)
+			}
+
+			checkEndpoint(set.Source, tt.source)
+			checkEndpoint(set.Repair, tt.repair)
+			checkEndpoint(set.Control, tt.control)
+
+			canonical := tt.canonical
+			if canonical == "" {
+				canonical = tt.uri
+			}
+
+			uri, err := set.URI()
+			require.NoError(t, err)
+			require.Equal(t, canonical, uri)
+			require.Equal(t, canonical, set.String())
+
+			reparsed, err := ParseEndpointSet(uri)
+			require.NoError(t, err)
+			require.Equal(t, set, reparsed)
+		})
+	}
+}
+
+func TestEndpointSet_URIErrors(t *testing.T) {
+	parseTests := []struct {
+		name string
+		uri  string
+	}{
+		{"empty", ""},
+		{"other scheme", "rtp://192.168.0.1:10001"},
+		{"missing host", "roc://:10001"},
+		{"bad port", "roc://192.168.0.1:port"},
+		{"port out of range", "roc://192.168.0.1:65535"},
+		{"path", "roc://192.168.0.1:10001/path"},
+		{"fragment", "roc://192.168.0.1:10001#frag"},
+		{"zero byte", "roc://192.168.0.1:10001\x00"},
+		{"bad fec", "roc://192.168.0.1:10001?fec=foo"},
+		{"bad repair", "roc://192.168.0.1:10001?repair=foo"},
+		{"bad control", "roc://192.168.0.1:10001?control=-1"},
+		{"repair without fec", "roc://192.168.0.1:10001?fec=disable&repair=10002"},
+		{"unknown parameter", "roc://192.168.0.1:10001?foo=bar"},
+		{"duplicate parameter", "roc://192.168.0.1:10001?fec=rs8m&fec=ldpc"},
+	}
+
+	for _, tt := range parseTests {
+		t.Run("parse/"+tt.name, func(t *testing.T) {
+			set, err := ParseEndpointSet(tt.uri)
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrBadEndpoint))
+			require.Nil(t, set)
+		})
+	}
+
+	base, err := NewEndpointSet("192.168.0.1", 10001, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	formatTests := []struct {
+		name string
+		set  *EndpointSet
+	}{
+		{"nil set", nil},
+		{"nil source", &EndpointSet{Control: base.Control}},
+		{"missing repair", &EndpointSet{Source: base.Source, Control: base.Control}},
+		{
+			"unexpected repair",
+			&EndpointSet{
+				Source: &Endpoint{Protocol: ProtoRtp, Host: "192.168.0.1", Port: 10001},
+				Repair: base.Repair,
+			},
+		},
+		{
+			"source protocol",
+			&EndpointSet{
+				Source: &Endpoint{Protocol: ProtoRtcp, Host: "192.168.0.1", Port: 10001},
+			},
+		},
+		{
+			"repair protocol",
+			&EndpointSet{
+				Source: base.Source,
+				Repair: &Endpoint{Protocol: ProtoLdpcRepair, Host: "192.168.0.1", Port: 10002},
+			},
+		},
+		{
+			"different hosts",
+			&EndpointSet{
+				Source:  base.Source,
+				Repair:  base.Repair,
+				Control: &Endpoint{Protocol: ProtoRtcp, Host: "192.168.0.2", Port: 10003},
+			},
+		},
+	}
+
+	for _, tt := range formatTests {
+		t.Run("format/"+tt.name, func(t *testing.T) {
+			uri, err := tt.set.URI()
+			require.Error(t, err)
+			require.True(t, errors.Is(err, ErrBadEndpoint))
+			require.Empty(t, uri)
+			require.Contains(t, tt.set.String(), "<bad endpoint set: ")
+		})
+	}
+}
+
+func TestEndpointSet_Text(t *testing.T) {
+	type config struct {
+		Stream *EndpointSet `json:"stream"`
+	}
+
+	var c config
+	err := json.Unmarshal([]byte(`{"stream":"roc://192.168.0.1?fec=ldpc"}`), &c)
+	require.NoError(t, err)
+	require.NotNil(t, c.Stream)
+	require.Equal(t, ProtoRtpLdpcSource, c.Stream.Source.Protocol)
+	require.Equal(t, 10001, c.Stream.Source.Port)
+
+	data, err := json.Marshal(c)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"stream":"roc://192.168.0.1:10001?fec=ldpc"}`, string(data))
+
+	err = json.Unmarshal([]byte(`{"stream":"roc://192.168.0.1?fec=foo"}`), &c)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrBadEndpoint))
+}
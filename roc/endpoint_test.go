@@ -126,27 +126,84 @@ func TestEndpoint(t *testing.T) {
 			parseErr:   nil,
 			composeErr: nil,
 		},
+		{
+			name:       "ipv6 host",
+			uri:        "rtp://[::1]:12345",
+			protocol:   ProtoRtp,
+			host:       "[::1]",
+			port:       12345,
+			parseErr:   nil,
+			composeErr: nil,
+		},
+		{
+			name:       "ipv6 host with zone",
+			uri:        "rtp://[fe80::1%25eth0]:12345",
+			protocol:   ProtoRtp,
+			host:       "[fe80::1%25eth0]",
+			port:       12345,
+			parseErr:   nil,
+			composeErr: nil,
+		},
+		{
+			name:       "ipv6 host with default rtsp port",
+			uri:        "rtsp://[2001:db8::1]/path",
+			protocol:   ProtoRtsp,
+			host:       "[2001:db8::1]",
+			port:       -1,
+			resource:   "/path",
+			parseErr:   nil,
+			composeErr: nil,
+		},
+		{
+			name:       "hostname",
+			uri:        "rtsp://example.com:12345/path",
+			protocol:   ProtoRtsp,
+			host:       "example.com",
+			port:       12345,
+			resource:   "/path",
+			parseErr:   nil,
+			composeErr: nil,
+		},
+		{
+			name:       "non-ascii hostname",
+			uri:        "rtsp://пример.рф:12345",
+			protocol:   ProtoRtsp,
+			host:       "пример.рф",
+			port:       12345,
+			parseErr:   nil,
+			composeErr: nil,
+		},
+		{
+			name:       "percent-encoded resource",
+			uri:        "rtsp://192.168.0.1:12345/my%20path?key=a%2Fb",
+			protocol:   ProtoRtsp,
+			host:       "192.168.0.1",
+			port:       12345,
+			resource:   "/my%20path?key=a%2Fb",
+			parseErr:   nil,
+			composeErr: nil,
+		},
 		// errors
 		{
 			name:       "empty uri",
 			uri:        "",
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_set_protocol()", -1),
+			parseErr:   errors.New("invalid uri: "),
+			composeErr: errors.New("invalid protocol: "),
 		},
 		{
 			name:       "missing host and port",
 			uri:        "rtsp://",
 			protocol:   ProtoRtsp,
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_get_uri()", -1),
+			parseErr:   errors.New("invalid host: "),
+			composeErr: errors.New("invalid host: "),
 		},
 		{
 			name:       "missing host",
 			uri:        "rtsp://:12345",
 			protocol:   ProtoRtsp,
 			port:       12345,
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_get_uri()", -1),
+			parseErr:   errors.New("invalid host: "),
+			composeErr: errors.New("invalid host: "),
 		},
 		{
 			name:       "port out of range",
@@ -154,8 +211,8 @@ func TestEndpoint(t *testing.T) {
 			protocol:   ProtoRtsp,
 			host:       "192.168.0.1",
 			port:       655356,
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_set_port()", -1),
+			parseErr:   errors.New("invalid port: "),
+			composeErr: errors.New("invalid port: "),
 		},
 		{
 			name:       "port out of range - negative",
@@ -163,8 +220,8 @@ func TestEndpoint(t *testing.T) {
 			protocol:   ProtoRtsp,
 			host:       "192.168.0.1",
 			port:       -2,
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_set_port()", -1),
+			parseErr:   errors.New("invalid port: "),
+			composeErr: errors.New("invalid port: "),
 		},
 		{
 			name:       "invalid resource",
@@ -173,14 +230,14 @@ func TestEndpoint(t *testing.T) {
 			host:       "192.168.0.1",
 			port:       -1,
 			resource:   "??",
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_set_resource()", -1),
+			parseErr:   errors.New("invalid resource: "),
+			composeErr: errors.New("invalid resource: "),
 		},
 		{
 			name:       "invalid protocol",
 			protocol:   Protocol(1),
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_set_protocol()", -1),
+			parseErr:   errors.New("invalid uri: "),
+			composeErr: errors.New("invalid protocol: "),
 		},
 		{
 			name:       "resource not allowed for protocol",
@@ -189,8 +246,8 @@ func TestEndpoint(t *testing.T) {
 			host:       "192.168.0.1",
 			port:       12345,
 			resource:   "/path",
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_get_uri()", -1),
+			parseErr:   errors.New("invalid resource: "),
+			composeErr: errors.New("invalid resource: "),
 		},
 		{
 			name:       "default port not defined for protocol",
@@ -198,14 +255,51 @@ func TestEndpoint(t *testing.T) {
 			protocol:   ProtoRtp,
 			host:       "192.168.0.1",
 			port:       -1,
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
-			composeErr: newNativeErr("roc_endpoint_get_uri()", -1),
+			parseErr:   errors.New("invalid port: "),
+			composeErr: errors.New("invalid port: "),
+		},
+		{
+			name:       "unknown scheme",
+			uri:        "http://192.168.0.1:12345",
+			protocol:   Protocol(1),
+			host:       "192.168.0.1",
+			port:       12345,
+			parseErr:   errors.New("invalid protocol: "),
+			composeErr: errors.New("invalid protocol: "),
+		},
+		{
+			name:       "unterminated ipv6 host",
+			uri:        "rtp://[::1:12345",
+			protocol:   ProtoRtp,
+			host:       "[::1",
+			port:       12345,
+			parseErr:   errors.New("invalid host: "),
+			composeErr: errors.New("invalid host: "),
+		},
+		{
+			name:       "bad ipv6 address",
+			uri:        "rtp://[1.2.3.4]:12345",
+			protocol:   ProtoRtp,
+			host:       "[1.2.3.4]",
+			port:       12345,
+			parseErr:   errors.New("invalid host: "),
+			composeErr: errors.New("invalid host: "),
+		},
+		{
+			name:       "bad percent-encoding in resource",
+			uri:        "rtsp://192.168.0.1:12345/path%2",
+			protocol:   ProtoRtsp,
+			host:       "192.168.0.1",
+			port:       12345,
+			resource:   "/path%2",
+			parseErr:   errors.New("invalid resource: "),
+			composeErr: errors.New("invalid resource: "),
 		},
 		{
 			name:       "zero byte in uri",
 			uri:        "rtsp://192.168.0.1:12345\x00",
 			parseErr:   errors.New("invalid uri: "),
-			composeErr: newNativeErr("roc_endpoint_set_protocol()", -1),
+			composeErr: errors.New("invalid protocol: "),
 		},
 		{
 			name:       "zero byte in host",
@@ -213,7 +307,7 @@ func TestEndpoint(t *testing.T) {
 			host:       "192.168.0.1\x00",
 			port:       12345,
 			resource:   "",
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
+			parseErr:   errors.New("invalid uri: "),
 			composeErr: errors.New("invalid host: "),
 		},
 		{
@@ -222,7 +316,7 @@ func TestEndpoint(t *testing.T) {
 			host:       "192.168.0.1",
 			port:       12345,
 			resource:   "/path\x00",
-			parseErr:   newNativeErr("roc_endpoint_set_uri()", -1),
+			parseErr:   errors.New("invalid uri: "),
 			composeErr: errors.New("invalid resource: "),
 		},
 	}
@@ -241,6 +335,7 @@ func TestEndpoint(t *testing.T) {
 				assert.Equal(t, tt.resource, endp.Resource)
 			} else {
 				require.Contains(t, err.Error(), tt.parseErr.Error())
+				require.True(t, errors.Is(err, ErrBadEndpoint))
 				require.Nil(t, endp)
 			}
 		})
@@ -260,6 +355,7 @@ func TestEndpoint(t *testing.T) {
 				require.Equal(t, tt.uri, uri)
 			} else {
 				require.Contains(t, err.Error(), tt.composeErr.Error())
+				require.True(t, errors.Is(err, ErrBadEndpoint))
 				require.Empty(t, uri)
 			}
 		})
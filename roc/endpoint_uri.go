@@ -0,0 +1,402 @@
+package roc
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Maximum port number.
+const maxPort = 65535
+
+// String returns endpoint URI.
+//
+// If endpoint is invalid and can't be composed into URI, returns a string
+// describing the error instead.
+func (endp *Endpoint) String() string {
+	uri, err := endp.formatURI()
+	if err != nil {
+		return fmt.Sprintf("<bad endpoint: %v>", err)
+	}
+	return uri
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// Endpoint is marshaled to its URI.
+func (endp *Endpoint) MarshalText() ([]byte, error) {
+	uri, err := endp.formatURI()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(uri), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// Endpoint is parsed from its URI.
+func (endp *Endpoint) UnmarshalText(text []byte) error {
+	parsed, err := parseEndpointURI(string(text))
+	if err != nil {
+		return err
+	}
+	*endp = *parsed
+	return nil
+}
+
+// URL converts endpoint to url.URL.
+//
+// IPv6 zone in host is converted to decoded form expected by url.URL.Host
+// (e.g. "[fe80::1%eth0]"), and resource is split into path and query.
+func (endp *Endpoint) URL() (*url.URL, error) {
+	if _, err := endp.formatURI(); err != nil {
+		return nil, err
+	}
+
+	u := &url.URL{
+		Scheme: endp.Protocol.Scheme(),
+		Host:   endpointUnescapeZone(endp.Host),
+	}
+	if endp.Port != -1 {
+		u.Host += ":" + strconv.Itoa(endp.Port)
+	}
+
+	path, query := endp.Resource, ""
+	if i := strings.IndexByte(path, '?'); i >= 0 {
+		path, query = path[:i], path[i+1:]
+	}
+
+	decodedPath, err := url.PathUnescape(path)
+	if err != nil {
+		return nil, newErr(ErrBadEndpoint, fmt.Sprintf("invalid resource: %v", err))
+	}
+	u.Path = decodedPath
+	if decodedPath != path {
+		u.RawPath = path
+	}
+	u.RawQuery = query
+
+	return u, nil
+}
+
+// EndpointFromURL converts url.URL to endpoint.
+//
+// URL should have the same form as accepted by ParseEndpoint(). User info and
+// fragment are not allowed.
+func EndpointFromURL(u *url.URL) (*Endpoint, error) {
+	if u == nil {
+		return nil, newErr(ErrBadEndpoint, "url is nil")
+	}
+	if u.Opaque != "" || u.User != nil || u.Fragment != "" {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: unexpected components in %q", u.String()))
+	}
+
+	uri := u.Scheme + "://" + endpointEscapeZone(u.Host) + u.EscapedPath()
+	if u.RawQuery != "" || u.ForceQuery {
+		uri += "?" + u.RawQuery
+	}
+
+	return parseEndpointURI(uri)
+}
+
+// parses endpoint URI in the same way as native roc_endpoint_set_uri()
+func parseEndpointURI(uri string) (*Endpoint, error) {
+	if strings.IndexByte(uri, 0) >= 0 {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid uri: unexpected zero byte in %q", uri))
+	}
+
+	sep := strings.Index(uri, "://")
+	if sep < 0 {
+		return nil, newErr(ErrBadEndpoint, fmt.Sprintf("invalid uri: missing scheme in %q", uri))
+	}
+
+	endp := &Endpoint{
+		Port: -1,
+	}
+
+	proto, ok := endpointProtocol(uri[:sep])
+	if !ok {
+		return nil, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid protocol: unknown scheme %q", uri[:sep]))
+	}
+	endp.Protocol = proto
+
+	rest := uri[sep+3:]
+
+	authority := rest
+	if i := strings.IndexAny(rest, "/?"); i >= 0 {
+		authority, endp.Resource = rest[:i], rest[i:]
+	}
+
+	host, port, err := endpointSplitHostPort(authority)
+	if err != nil {
+		return nil, err
+	}
+	endp.Host, endp.Port = host, port
+
+	if err := endp.validate(); err != nil {
+		return nil, err
+	}
+
+	return endp, nil
+}
+
+// composes endpoint URI in the same way as native roc_endpoint_get_uri()
+func (endp *Endpoint) formatURI() (string, error) {
+	if err := endp.validate(); err != nil {
+		return "", err
+	}
+
+	var b strings.Builder
+
+	b.WriteString(endp.Protocol.Scheme())
+	b.WriteString("://")
+	b.WriteString(endp.Host)
+	if endp.Port != -1 {
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(endp.Port))
+	}
+	b.WriteString(endp.Resource)
+
+	return b.String(), nil
+}
+
+// checks that all endpoint components are valid and compatible with protocol
+func (endp *Endpoint) validate() error {
+	if endp.Protocol.Scheme() == "" {
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid protocol: unknown value %d", int(endp.Protocol)))
+	}
+
+	if err := endpointValidateHost(endp.Host); err != nil {
+		return err
+	}
+
+	switch {
+	case endp.Port == -1:
+		if _, ok := endp.Protocol.DefaultPort(); !ok {
+			return newErr(ErrBadEndpoint,
+				fmt.Sprintf("invalid port: protocol %s requires port",
+					endp.Protocol.Scheme()))
+		}
+	case endp.Port < 0 || endp.Port > maxPort:
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid port: %d is out of range", endp.Port))
+	}
+
+	if endp.Resource != "" {
+		if !endp.Protocol.SupportsResource() {
+			return newErr(ErrBadEndpoint,
+				fmt.Sprintf("invalid resource: not supported by protocol %s",
+					endp.Protocol.Scheme()))
+		}
+		if err := endpointValidateResource(endp.Resource); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns protocol for URI scheme; unlike UnmarshalText, case-sensitive
+func endpointProtocol(scheme string) (Protocol, bool) {
+	for _, n := range protocolNames {
+		if n.name == scheme {
+			return Protocol(n.value), true
+		}
+	}
+	return 0, false
+}
+
+// splits "host[:port]" into host and port; port is -1 if omitted
+func endpointSplitHostPort(authority string) (string, int, error) {
+	host, port := authority, ""
+	if strings.HasPrefix(authority, "[") {
+		if i := strings.IndexByte(authority, ']'); i >= 0 {
+			host, port = authority[:i+1], authority[i+1:]
+		}
+	} else if i := strings.IndexByte(authority, ':'); i >= 0 {
+		host, port = authority[:i], authority[i:]
+	}
+
+	if port == "" {
+		return host, -1, nil
+	}
+
+	if port[0] != ':' {
+		return "", 0, newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid host: unexpected characters after %q", host))
+	}
+
+	n, err := endpointParsePort(port[1:])
+	if err != nil {
+		return "", 0, err
+	}
+
+	return host, n, nil
+}
+
+func endpointParsePort(port string) (int, error) {
+	if port == "" || len(port) > len(strconv.Itoa(maxPort)) {
+		return 0, newErr(ErrBadEndpoint, fmt.Sprintf("invalid port: %q", port))
+	}
+	for i := 0; i < len(port); i++ {
+		if port[i] < '0' || port[i] > '9' {
+			return 0, newErr(ErrBadEndpoint, fmt.Sprintf("invalid port: %q", port))
+		}
+	}
+
+	n, _ := strconv.Atoi(port)
+	if n > maxPort {
+		return 0, newErr(ErrBadEndpoint, fmt.Sprintf("invalid port: %d is out of range", n))
+	}
+
+	return n, nil
+}
+
+// host is either IPv6 address in brackets, with optional zone, or IPv4
+// address or domain name
+//
+// like in native parser, zone should be percent-encoded as "%25" (RFC 6874),
+// and domain name should consist of RFC 3986 unreserved and sub-delims
+// characters; non-ASCII domain names should be converted to punycode
+func endpointValidateHost(host string) error {
+	if host == "" {
+		return newErr(ErrBadEndpoint, "invalid host: empty")
+	}
+
+	if strings.HasPrefix(host, "[") {
+		if !strings.HasSuffix(host, "]") {
+			return newErr(ErrBadEndpoint, fmt.Sprintf("invalid host: unterminated %q", host))
+		}
+
+		addr, zone := host[1:len(host)-1], ""
+		if i := strings.IndexByte(addr, '%'); i >= 0 {
+			addr, zone = addr[:i], addr[i:]
+			if !strings.HasPrefix(zone, "%25") || !endpointValidZone(zone[3:]) {
+				return newErr(ErrBadEndpoint,
+					fmt.Sprintf("invalid host: bad ipv6 zone in %q", host))
+			}
+		}
+		if ip := net.ParseIP(addr); ip == nil || !strings.Contains(addr, ":") {
+			return newErr(ErrBadEndpoint,
+				fmt.Sprintf("invalid host: bad ipv6 address in %q", host))
+		}
+
+		return nil
+	}
+
+	for i := 0; i < len(host); i++ {
+		if c := host[i]; !isUnreservedChar(c) && !isSubDelimChar(c) {
+			return newErr(ErrBadEndpoint,
+				fmt.Sprintf("invalid host: unexpected character %q in %q", host[i:i+1], host))
+		}
+	}
+
+	return nil
+}
+
+// zone is RFC 6874 ZoneID: unreserved characters or percent-encoded bytes
+func endpointValidZone(zone string) bool {
+	if zone == "" {
+		return false
+	}
+	for i := 0; i < len(zone); i++ {
+		c := zone[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(zone) || !isHexDigit(zone[i+1]) || !isHexDigit(zone[i+2]) {
+				return false
+			}
+			i += 2
+		case !isUnreservedChar(c):
+			return false
+		}
+	}
+	return true
+}
+
+// resource is "/path", "/path?query", or "?query", where path and query
+// consist of RFC 3986 pchar and '/', and may be percent-encoded
+func endpointValidateResource(resource string) error {
+	path, query := resource, ""
+	hasQuery := false
+	if i := strings.IndexByte(resource, '?'); i >= 0 {
+		path, query, hasQuery = resource[:i], resource[i+1:], true
+	}
+
+	if path != "" && path[0] != '/' {
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("invalid resource: path should start with '/' in %q", resource))
+	}
+	if path == "" && !hasQuery {
+		return newErr(ErrBadEndpoint, "invalid resource: empty")
+	}
+
+	for _, part := range []string{path, query} {
+		for i := 0; i < len(part); i++ {
+			c := part[i]
+			switch {
+			case c == '%':
+				if i+2 >= len(part) || !isHexDigit(part[i+1]) || !isHexDigit(part[i+2]) {
+					return newErr(ErrBadEndpoint,
+						fmt.Sprintf("invalid resource: bad percent-encoding in %q", resource))
+				}
+				i += 2
+			case !isResourceChar(c):
+				return newErr(ErrBadEndpoint,
+					fmt.Sprintf("invalid resource: unexpected character %q in %q",
+						part[i:i+1], resource))
+			}
+		}
+	}
+
+	return nil
+}
+
+// RFC 3986 pchar (except pct-encoded) and '/'
+func isResourceChar(c byte) bool {
+	return isUnreservedChar(c) || isSubDelimChar(c) || c == ':' || c == '@' || c == '/'
+}
+
+// RFC 3986 unreserved
+func isUnreservedChar(c byte) bool {
+	switch {
+	case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		return true
+	}
+	return strings.IndexByte("-._~", c) >= 0
+}
+
+// RFC 3986 sub-delims
+func isSubDelimChar(c byte) bool {
+	return strings.IndexByte("!$&'()*+,;=", c) >= 0
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+// converts "[fe80::1%25eth0]" to "[fe80::1%eth0]"
+func endpointUnescapeZone(host string) string {
+	if !strings.HasPrefix(host, "[") {
+		return host
+	}
+	if i := strings.Index(host, "%25"); i >= 0 {
+		return host[:i] + "%" + host[i+3:]
+	}
+	return host
+}
+
+// converts "[fe80::1%eth0]" to "[fe80::1%25eth0]"
+func endpointEscapeZone(host string) string {
+	if !strings.HasPrefix(host, "[") {
+		return host
+	}
+	if i := strings.IndexByte(host, '%'); i >= 0 {
+		return host[:i] + "%25" + host[i+1:]
+	}
+	return host
+}
@@ -0,0 +1,206 @@
+package roc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Checks that Go parser agrees with native parser.
+func TestEndpoint_Native(t *testing.T) {
+	uris := []string{
+		"rtsp://192.168.0.1:12345/path?query1=query1&query2=query2",
+		"rtsp://192.168.0.1",
+		"rtsp://192.168.0.1:0",
+		"rtsp://192.168.0.1/path",
+		"rtsp://192.168.0.1/path%20with%20spaces",
+		"rtsp://example.com:554/a/b/c",
+		"rtp://192.168.0.1:12345",
+		"rtp+rs8m://192.168.0.1:12345",
+		"rs8m://192.168.0.1:12345",
+		"rtp+ldpc://192.168.0.1:12345",
+		"ldpc://192.168.0.1:12345",
+		"rtcp://192.168.0.1:12345",
+		"rtp://[::1]:12345",
+		"rtp://[2001:db8::1]:12345",
+		"rtsp://[::1]/path",
+		"rtp://[fe80::1%25eth0]:1",
+		"rtp://[::ffff:192.168.0.1]:1",
+		"rtp://my-host_1.example.com:1",
+		"rtsp://h/p?a=%20",
+		"rtsp://h?a=1",
+		// invalid
+		"",
+		"rtsp://",
+		"rtsp://:12345",
+		"rtsp://192.168.0.1:65536",
+		"rtsp://192.168.0.1:-2",
+		"rtsp://192.168.0.1:port",
+		"rtsp://192.168.0.1/??",
+		"rtp://192.168.0.1",
+		"rtp://192.168.0.1:12345/path",
+		"http://192.168.0.1:12345",
+		"rtp://[::1:12345",
+		"192.168.0.1:12345",
+		"rtp://[fe80::1%eth0]:1",
+		"rtp://[fe80::1%25]:1",
+		"rtp://[fe80::1%25eth 0]:1",
+		"rtp://\u00e9xample.com:1",
+		"rtp://\xff:1",
+		"rtp://a\"b:1",
+		"rtsp://h/p?a=%2",
+		"rtsp://h/p?a=%zz",
+		"rtsp://h/p?a b",
+		"rtsp://h/\u00e9",
+	}
+
+	for _, uri := range uris {
+		t.Run(uri, func(t *testing.T) {
+			goEndp, goErr := parseEndpointURI(uri)
+			nativeEndp, nativeErr := parseEndpointNative(uri)
+
+			if nativeErr != nil {
+				require.Error(t, goErr)
+				require.True(t, errors.Is(goErr, ErrBadEndpoint))
+				return
+			}
+
+			require.NoError(t, goErr)
+			require.Equal(t, nativeEndp, goEndp)
+
+			goURI, goErr := goEndp.formatURI()
+			nativeURI, nativeErr := nativeEndp.uriNative()
+
+			require.NoError(t, nativeErr)
+			require.NoError(t, goErr)
+			require.Equal(t, nativeURI, goURI)
+		})
+	}
+}
+
+func TestEndpoint_String(t *testing.T) {
+	endp := Endpoint{
+		Protocol: ProtoRtp,
+		Host:     "[::1]",
+		Port:     12345,
+	}
+	assert.Equal(t, "rtp://[::1]:12345", endp.String())
+
+	badEndp := Endpoint{
+		Protocol: ProtoRtp,
+		Host:     "192.168.0.1",
+		Port:     -1,
+	}
+	assert.Contains(t, badEndp.String(), "<bad endpoint: invalid port: ")
+}
+
+func TestEndpoint_Text(t *testing.T) {
+	type wrapper struct {
+		Endpoint *Endpoint `json:"endpoint"`
+	}
+
+	w := wrapper{
+		Endpoint: &Endpoint{
+			Protocol: ProtoRtsp,
+			Host:     "[fe80::1%25eth0]",
+			Port:     12345,
+			Resource: "/path",
+		},
+	}
+
+	data, err := json.Marshal(w)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"endpoint":"rtsp://[fe80::1%25eth0]:12345/path"}`, string(data))
+
+	var decoded wrapper
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, w, decoded)
+
+	err = json.Unmarshal([]byte(`{"endpoint":"rtp://192.168.0.1"}`), &decoded)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrBadEndpoint))
+
+	_, err = json.Marshal(wrapper{Endpoint: &Endpoint{}})
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrBadEndpoint))
+}
+
+func TestEndpoint_URL(t *testing.T) {
+	tests := []struct {
+		name string
+		endp Endpoint
+		url  url.URL
+	}{
+		{
+			name: "rtp",
+			endp: Endpoint{Protocol: ProtoRtp, Host: "192.168.0.1", Port: 12345},
+			url:  url.URL{Scheme: "rtp", Host: "192.168.0.1:12345"},
+		},
+		{
+			name: "rtsp default port",
+			endp: Endpoint{Protocol: ProtoRtsp, Host: "example.com", Port: -1},
+			url:  url.URL{Scheme: "rtsp", Host: "example.com"},
+		},
+		{
+			name: "rtsp resource",
+			endp: Endpoint{
+				Protocol: ProtoRtsp, Host: "192.168.0.1", Port: 554,
+				Resource: "/my%20path?key=value",
+			},
+			url: url.URL{
+				Scheme: "rtsp", Host: "192.168.0.1:554",
+				Path: "/my path", RawPath: "/my%20path", RawQuery: "key=value",
+			},
+		},
+		{
+			name: "ipv6 zone",
+			endp: Endpoint{Protocol: ProtoRtp, Host: "[fe80::1%25eth0]", Port: 12345},
+			url:  url.URL{Scheme: "rtp", Host: "[fe80::1%eth0]:12345"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u, err := tt.endp.URL()
+			require.NoError(t, err)
+			require.Equal(t, tt.url, *u)
+
+			uri, err := tt.endp.URI()
+			require.NoError(t, err)
+			require.Equal(t, uri, u.String())
+
+			endp, err := EndpointFromURL(u)
+			require.NoError(t, err)
+			require.Equal(t, tt.endp, *endp)
+
+			parsedURL, err := url.Parse(uri)
+			require.NoError(t, err)
+
+			endp, err = EndpointFromURL(parsedURL)
+			require.NoError(t, err)
+			require.Equal(t, tt.endp, *endp)
+		})
+	}
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := EndpointFromURL(nil)
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+
+		_, err = EndpointFromURL(&url.URL{
+			Scheme: "rtsp", Host: "192.168.0.1", User: url.User("user"),
+		})
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+
+		_, err = EndpointFromURL(&url.URL{
+			Scheme: "rtsp", Host: "192.168.0.1", Fragment: "frag",
+		})
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+
+		_, err = (&Endpoint{Protocol: ProtoRtp, Host: "192.168.0.1", Port: -1}).URL()
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+	})
+}
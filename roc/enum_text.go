@@ -0,0 +1,338 @@
+package roc
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Enum types implement encoding.TextMarshaler and encoding.TextUnmarshaler
+// using stable lowercase names defined below. Names don't depend on String()
+// output and are safe to be stored in configuration files.
+//
+// Unmarshaling is case-insensitive. Enums that may hold user-registered values
+// (PacketEncoding and PlcBackend) additionally accept and produce decimal
+// numbers for values without a name.
+
+type enumName struct {
+	value int
+	name  string
+}
+
+var protocolNames = []enumName{
+	{int(ProtoRtsp), "rtsp"},
+	{int(ProtoRtp), "rtp"},
+	{int(ProtoRtpRs8mSource), "rtp+rs8m"},
+	{int(ProtoRs8mRepair), "rs8m"},
+	{int(ProtoRtpLdpcSource), "rtp+ldpc"},
+	{int(ProtoLdpcRepair), "ldpc"},
+	{int(ProtoRtcp), "rtcp"},
+}
+
+var interfaceNames = []enumName{
+	{int(InterfaceConsolidated), "consolidated"},
+	{int(InterfaceAudioSource), "audio_source"},
+	{int(InterfaceAudioRepair), "audio_repair"},
+	{int(InterfaceAudioControl), "audio_control"},
+}
+
+var formatNames = []enumName{
+	{int(FormatPcmFloat32), "pcm_float32"},
+}
+
+var channelLayoutNames = []enumName{
+	{int(ChannelLayoutMultitrack), "multitrack"},
+	{int(ChannelLayoutMono), "mono"},
+	{int(ChannelLayoutStereo), "stereo"},
+}
+
+var packetEncodingNames = []enumName{
+	{int(PacketEncodingAvpL16Mono), "avp_l16_mono"},
+	{int(PacketEncodingAvpL16Stereo), "avp_l16_stereo"},
+}
+
+var fecEncodingNames = []enumName{
+	{int(FecEncodingDisable), "disable"},
+	{int(FecEncodingDefault), "default"},
+	{int(FecEncodingRs8m), "rs8m"},
+	{int(FecEncodingLdpcStaircase), "ldpc"},
+}
+
+var clockSourceNames = []enumName{
+	{int(ClockSourceDefault), "default"},
+	{int(ClockSourceExternal), "external"},
+	{int(ClockSourceInternal), "internal"},
+}
+
+var latencyTunerBackendNames = []enumName{
+	{int(LatencyTunerBackendDefault), "default"},
+	{int(LatencyTunerBackendNiq), "niq"},
+}
+
+var latencyTunerProfileNames = []enumName{
+	{int(LatencyTunerProfileDefault), "default"},
+	{int(LatencyTunerProfileIntact), "intact"},
+	{int(LatencyTunerProfileResponsive), "responsive"},
+	{int(LatencyTunerProfileGradual), "gradual"},
+}
+
+var resamplerBackendNames = []enumName{
+	{int(ResamplerBackendDefault), "default"},
+	{int(ResamplerBackendBuiltin), "builtin"},
+	{int(ResamplerBackendSpeex), "speex"},
+	{int(ResamplerBackendSpeexdec), "speexdec"},
+}
+
+var resamplerProfileNames = []enumName{
+	{int(ResamplerProfileDefault), "default"},
+	{int(ResamplerProfileHigh), "high"},
+	{int(ResamplerProfileMedium), "medium"},
+	{int(ResamplerProfileLow), "low"},
+}
+
+var plcBackendNames = []enumName{
+	{int(PlcBackendDefault), "default"},
+	{int(PlcBackendNone), "none"},
+}
+
+var pcmFormatNames = []enumName{
+	{int(PcmFormatS16LE), "s16le"},
+	{int(PcmFormatS24LE), "s24le"},
+	{int(PcmFormatS32LE), "s32le"},
+	{int(PcmFormatF32LE), "f32le"},
+}
+
+var logLevelNames = []enumName{
+	{int(LogNone), "none"},
+	{int(LogError), "error"},
+	{int(LogInfo), "info"},
+	{int(LogDebug), "debug"},
+	{int(LogTrace), "trace"},
+}
+
+func enumMarshal(typeName string, names []enumName, numeric bool, value int) ([]byte, error) {
+	for _, n := range names {
+		if n.value == value {
+			return []byte(n.name), nil
+		}
+	}
+	if numeric {
+		return []byte(strconv.Itoa(value)), nil
+	}
+	return nil, fmt.Errorf("can't marshal %s: unknown value %d", typeName, value)
+}
+
+func enumUnmarshal(typeName string, names []enumName, numeric bool, text []byte) (int, error) {
+	name := strings.ToLower(string(text))
+	for _, n := range names {
+		if n.name == name {
+			return n.value, nil
+		}
+	}
+	if numeric {
+		if value, err := strconv.Atoi(name); err == nil {
+			return value, nil
+		}
+	}
+	return 0, fmt.Errorf("can't unmarshal %s: unknown name %q", typeName, name)
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p Protocol) MarshalText() ([]byte, error) {
+	return enumMarshal("Protocol", protocolNames, false, int(p))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *Protocol) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("Protocol", protocolNames, false, text)
+	if err == nil {
+		*p = Protocol(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (i Interface) MarshalText() ([]byte, error) {
+	return enumMarshal("Interface", interfaceNames, false, int(i))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (i *Interface) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("Interface", interfaceNames, false, text)
+	if err == nil {
+		*i = Interface(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (f Format) MarshalText() ([]byte, error) {
+	return enumMarshal("Format", formatNames, false, int(f))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *Format) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("Format", formatNames, false, text)
+	if err == nil {
+		*f = Format(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (cl ChannelLayout) MarshalText() ([]byte, error) {
+	return enumMarshal("ChannelLayout", channelLayoutNames, false, int(cl))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (cl *ChannelLayout) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("ChannelLayout", channelLayoutNames, false, text)
+	if err == nil {
+		*cl = ChannelLayout(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// Registered encodings without a name are marshaled as decimal numbers.
+func (pe PacketEncoding) MarshalText() ([]byte, error) {
+	return enumMarshal("PacketEncoding", packetEncodingNames, true, int(pe))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// Accepts both names and decimal numbers of registered encodings.
+func (pe *PacketEncoding) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("PacketEncoding", packetEncodingNames, true, text)
+	if err == nil {
+		*pe = PacketEncoding(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (fe FecEncoding) MarshalText() ([]byte, error) {
+	return enumMarshal("FecEncoding", fecEncodingNames, false, int(fe))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (fe *FecEncoding) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("FecEncoding", fecEncodingNames, false, text)
+	if err == nil {
+		*fe = FecEncoding(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (cs ClockSource) MarshalText() ([]byte, error) {
+	return enumMarshal("ClockSource", clockSourceNames, false, int(cs))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (cs *ClockSource) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("ClockSource", clockSourceNames, false, text)
+	if err == nil {
+		*cs = ClockSource(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b LatencyTunerBackend) MarshalText() ([]byte, error) {
+	return enumMarshal("LatencyTunerBackend", latencyTunerBackendNames, false, int(b))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *LatencyTunerBackend) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("LatencyTunerBackend", latencyTunerBackendNames, false, text)
+	if err == nil {
+		*b = LatencyTunerBackend(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p LatencyTunerProfile) MarshalText() ([]byte, error) {
+	return enumMarshal("LatencyTunerProfile", latencyTunerProfileNames, false, int(p))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *LatencyTunerProfile) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("LatencyTunerProfile", latencyTunerProfileNames, false, text)
+	if err == nil {
+		*p = LatencyTunerProfile(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ResamplerBackend) MarshalText() ([]byte, error) {
+	return enumMarshal("ResamplerBackend", resamplerBackendNames, false, int(b))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ResamplerBackend) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("ResamplerBackend", resamplerBackendNames, false, text)
+	if err == nil {
+		*b = ResamplerBackend(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (p ResamplerProfile) MarshalText() ([]byte, error) {
+	return enumMarshal("ResamplerProfile", resamplerProfileNames, false, int(p))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (p *ResamplerProfile) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("ResamplerProfile", resamplerProfileNames, false, text)
+	if err == nil {
+		*p = ResamplerProfile(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+// Registered plugins are marshaled as decimal numbers.
+func (b PlcBackend) MarshalText() ([]byte, error) {
+	return enumMarshal("PlcBackend", plcBackendNames, true, int(b))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+// Accepts both names and decimal numbers of registered plugins.
+func (b *PlcBackend) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("PlcBackend", plcBackendNames, true, text)
+	if err == nil {
+		*b = PlcBackend(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (f PcmFormat) MarshalText() ([]byte, error) {
+	return enumMarshal("PcmFormat", pcmFormatNames, false, int(f))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (f *PcmFormat) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("PcmFormat", pcmFormatNames, false, text)
+	if err == nil {
+		*f = PcmFormat(value)
+	}
+	return err
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (l LogLevel) MarshalText() ([]byte, error) {
+	return enumMarshal("LogLevel", logLevelNames, false, int(l))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (l *LogLevel) UnmarshalText(text []byte) error {
+	value, err := enumUnmarshal("LogLevel", logLevelNames, false, text)
+	if err == nil {
+		*l = LogLevel(value)
+	}
+	return err
+}
@@ -0,0 +1,108 @@
+package roc
+
+import (
+	"encoding"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnumText_RoundTrip(t *testing.T) {
+	tests := []struct {
+		value encoding.TextMarshaler
+		name  string
+		newFn func() encoding.TextUnmarshaler
+	}{
+		{ProtoRtpRs8mSource, "rtp+rs8m", func() encoding.TextUnmarshaler { return new(Protocol) }},
+		{ProtoLdpcRepair, "ldpc", func() encoding.TextUnmarshaler { return new(Protocol) }},
+		{InterfaceAudioSource, "audio_source", func() encoding.TextUnmarshaler { return new(Interface) }},
+		{FormatPcmFloat32, "pcm_float32", func() encoding.TextUnmarshaler { return new(Format) }},
+		{ChannelLayoutStereo, "stereo", func() encoding.TextUnmarshaler { return new(ChannelLayout) }},
+		{PacketEncodingAvpL16Mono, "avp_l16_mono",
+			func() encoding.TextUnmarshaler { return new(PacketEncoding) }},
+		{PacketEncoding(100), "100", func() encoding.TextUnmarshaler { return new(PacketEncoding) }},
+		{FecEncodingDisable, "disable", func() encoding.TextUnmarshaler { return new(FecEncoding) }},
+		{FecEncodingRs8m, "rs8m", func() encoding.TextUnmarshaler { return new(FecEncoding) }},
+		{FecEncodingLdpcStaircase, "ldpc", func() encoding.TextUnmarshaler { return new(FecEncoding) }},
+		{ClockSourceInternal, "internal", func() encoding.TextUnmarshaler { return new(ClockSource) }},
+		{LatencyTunerBackendNiq, "niq",
+			func() encoding.TextUnmarshaler { return new(LatencyTunerBackend) }},
+		{LatencyTunerProfileIntact, "intact",
+			func() encoding.TextUnmarshaler { return new(LatencyTunerProfile) }},
+		{ResamplerBackendSpeexdec, "speexdec",
+			func() encoding.TextUnmarshaler { return new(ResamplerBackend) }},
+		{ResamplerProfileLow, "low", func() encoding.TextUnmarshaler { return new(ResamplerProfile) }},
+		{PlcBackendNone, "none", func() encoding.TextUnmarshaler { return new(PlcBackend) }},
+		{PlcBackend(1000), "1000", func() encoding.TextUnmarshaler { return new(PlcBackend) }},
+		{PcmFormatS24LE, "s24le", func() encoding.TextUnmarshaler { return new(PcmFormat) }},
+		{LogDebug, "debug", func() encoding.TextUnmarshaler { return new(LogLevel) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := tt.value.MarshalText()
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, string(text))
+
+			ptr := tt.newFn()
+			require.NoError(t, ptr.UnmarshalText([]byte(tt.name)))
+			assert.Equal(t, tt.value, derefEnum(ptr))
+		})
+	}
+}
+
+func TestEnumText_CaseInsensitive(t *testing.T) {
+	var profile LatencyTunerProfile
+	require.NoError(t, profile.UnmarshalText([]byte("Gradual")))
+	assert.Equal(t, LatencyTunerProfileGradual, profile)
+}
+
+func TestEnumText_Errors(t *testing.T) {
+	_, err := FecEncoding(100).MarshalText()
+	assert.EqualError(t, err, "can't marshal FecEncoding: unknown value 100")
+
+	fec := FecEncodingRs8m
+	err = fec.UnmarshalText([]byte("ldpcstaircase"))
+	assert.EqualError(t, err, `can't unmarshal FecEncoding: unknown name "ldpcstaircase"`)
+	assert.Equal(t, FecEncodingRs8m, fec)
+
+	var proto Protocol
+	err = proto.UnmarshalText([]byte("20"))
+	assert.EqualError(t, err, `can't unmarshal Protocol: unknown name "20"`)
+}
+
+// returns value pointed by enum pointer
+func derefEnum(ptr encoding.TextUnmarshaler) interface{} {
+	switch p := ptr.(type) {
+	case *Protocol:
+		return *p
+	case *Interface:
+		return *p
+	case *Format:
+		return *p
+	case *ChannelLayout:
+		return *p
+	case *PacketEncoding:
+		return *p
+	case *FecEncoding:
+		return *p
+	case *ClockSource:
+		return *p
+	case *LatencyTunerBackend:
+		return *p
+	case *LatencyTunerProfile:
+		return *p
+	case *ResamplerBackend:
+		return *p
+	case *ResamplerProfile:
+		return *p
+	case *PlcBackend:
+		return *p
+	case *PcmFormat:
+		return *p
+	case *LogLevel:
+		return *p
+	}
+	panic("unknown enum type")
+}
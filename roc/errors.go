@@ -2,21 +2,155 @@ package roc
 
 import (
 	"C"
+	"errors"
 	"fmt"
+	"strings"
 )
 
-type nativeErr struct {
-	op   string
-	code int
+var (
+	// ErrClosed is returned when an operation is invoked on an object that is
+	// already closed, or when a blocking operation was interrupted because the
+	// object was closed concurrently by another goroutine.
+	ErrClosed = errors.New("object is closed")
+
+	// ErrNilContext is returned when a nil Context is passed to a function
+	// that requires one.
+	ErrNilContext = errors.New("context is nil")
+
+	// ErrInvalidConfig is returned when a configuration struct contains
+	// a value that can't be passed to the native library.
+	ErrInvalidConfig = errors.New("invalid config")
+
+	// ErrBadEndpoint is returned when an endpoint is nil, or can't be parsed
+	// or composed.
+	ErrBadEndpoint = errors.New("bad endpoint")
+
+	// ErrNotSupported is returned when requested format or encoding is not
+	// supported by the bindings.
+	ErrNotSupported = errors.New("not supported")
+
+	// ErrInvalidArgument is returned when an argument passed to a function is
+	// invalid, e.g. a nil frame or an empty packet.
+	ErrInvalidArgument = errors.New("invalid argument")
+
+	// ErrExists is returned when an object with the same name is already
+	// registered, e.g. a slot name passed to Sender.NewSlot().
+	ErrExists = errors.New("already exists")
+
+	// ErrNotFound is returned when an object with given name or handle is not
+	// registered, e.g. a name passed to Broadcaster.RemoveDestination().
+	ErrNotFound = errors.New("not found")
+
+	// ErrNoPacket is returned by SenderEncoder.PopPacket() and
+	// ReceiverDecoder.PopFeedbackPacket() when the interface is activated,
+	// but its queue has no more packets.
+	ErrNoPacket = errors.New("no packet")
+)
+
+// NativeError is returned when a call to the native library fails.
+//
+// It may be wrapped together with one of the sentinel errors defined in this
+// package; use errors.As to extract it.
+//
+// NativeError is comparable, like other errors returned by this package.
+type NativeError struct {
+	// Name of the native function that failed, e.g. "roc_sender_open()".
+	Op string
+
+	// Error code returned by the native function.
+	Code int
+
+	// captured messages, stored by pointer to keep the struct comparable
+	log *[]LogMessage
+}
+
+func newNativeErr(op string, code C.int) NativeError {
+	return NativeError{
+		Op:   op,
+		Code: int(code),
+	}
+}
+
+func newNativeErrLog(op string, code C.int, log []LogMessage) NativeError {
+	err := NativeError{
+		Op:   op,
+		Code: int(code),
+	}
+	if len(log) != 0 {
+		err.log = &log
+	}
+	return err
 }
 
-func newNativeErr(op string, code C.int) nativeErr {
-	return nativeErr{
-		op:   op,
-		code: int(code),
+// Log returns messages of info level and above, emitted by the native library
+// on the calling thread during the failed call, independently of the level
+// set by SetLogLevel. Only filled for calls that open and configure objects.
+// May be empty.
+//
+// Messages from other threads are not attached, even if they're related to
+// the failure. In particular, the "address already in use" reason of a failed
+// Receiver.Bind() or Sender.Connect() is never attached: it is reported by the
+// network thread of the context and is only passed to the logger (if enabled
+// by SetLogLevel), while Log returns only the generic failure message of the
+// calling thread. Failures detected by the calling thread itself, such as
+// invalid interface config passed to Receiver.Configure(), are attached with
+// their reason.
+func (e NativeError) Log() []LogMessage {
+	if e.log == nil {
+		return nil
 	}
+	return *e.log
+}
+
+// Error returns error code and text of captured error-level messages, if any.
+func (e NativeError) Error() string {
+	text := fmt.Sprintf("%s failed with code %d", e.Op, e.Code)
+
+	var reasons []string
+	for _, message := range e.Log() {
+		if message.Level == LogError {
+			reasons = append(reasons, message.Text)
+		}
+	}
+	if len(reasons) != 0 {
+		text += ": " + strings.Join(reasons, "; ")
+	}
+
+	return text
+}
+
+// kindErr associates an error with one of the sentinel errors, so that
+// errors.Is reports true for the sentinel, while Error() and Unwrap() are
+// forwarded to the original error.
+type kindErr struct {
+	kind error
+	err  error
+}
+
+// returns error with given text that matches kind
+func newErr(kind error, text string) error {
+	return kindErr{
+		kind: kind,
+		err:  errors.New(text),
+	}
+}
+
+// returns err that additionally matches kind
+func wrapErr(kind error, err error) error {
+	return kindErr{
+		kind: kind,
+		err:  err,
+	}
+}
+
+func (e kindErr) Error() string {
+	return e.err.Error()
+}
+
+func (e kindErr) Unwrap() error {
+	return e.err
 }
 
-func (e nativeErr) Error() string {
-	return fmt.Sprintf("%s failed with code %d", e.op, e.code)
+func (e kindErr) Is(target error) bool {
+	return target == e.kind
 }
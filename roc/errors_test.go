@@ -0,0 +1,125 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrors_Is(t *testing.T) {
+	sentinels := []error{
+		ErrClosed,
+		ErrNilContext,
+		ErrInvalidConfig,
+		ErrBadEndpoint,
+		ErrNotSupported,
+		ErrInvalidArgument,
+		ErrExists,
+		ErrNotFound,
+		ErrNoPacket,
+	}
+
+	for _, kind := range sentinels {
+		t.Run(kind.Error(), func(t *testing.T) {
+			err := newErr(kind, "some text")
+
+			assert.Equal(t, "some text", err.Error())
+			assert.True(t, errors.Is(err, kind))
+
+			for _, other := range sentinels {
+				if other != kind {
+					assert.False(t, errors.Is(err, other))
+				}
+			}
+		})
+	}
+}
+
+func TestErrors_Wrap(t *testing.T) {
+	cause := errors.New("cause")
+
+	err := wrapErr(ErrInvalidConfig, cause)
+
+	assert.Equal(t, "cause", err.Error())
+	assert.True(t, errors.Is(err, ErrInvalidConfig))
+	assert.True(t, errors.Is(err, cause))
+	assert.False(t, errors.Is(err, ErrBadEndpoint))
+}
+
+func TestErrors_Native(t *testing.T) {
+	err := wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_uri()", -1))
+
+	assert.Equal(t, "roc_endpoint_set_uri() failed with code -1", err.Error())
+	assert.True(t, errors.Is(err, ErrBadEndpoint))
+
+	var nativeErr NativeError
+	require.True(t, errors.As(err, &nativeErr))
+	assert.Equal(t, "roc_endpoint_set_uri()", nativeErr.Op)
+	assert.Equal(t, -1, nativeErr.Code)
+}
+
+func TestErrors_Closed(t *testing.T) {
+	ctx, err := OpenContext(ContextConfig{})
+	require.NoError(t, err)
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+	require.NoError(t, sender.Close())
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	require.NoError(t, receiver.Close())
+
+	require.NoError(t, ctx.Close())
+
+	assert.True(t, errors.Is(sender.Unlink(0), ErrClosed))
+	assert.True(t, errors.Is(receiver.Unlink(0), ErrClosed))
+	assert.True(t, errors.Is(ctx.RegisterEncoding(100, MediaEncoding{}), ErrClosed))
+
+	_, err = OpenSender(nil, makeSenderConfig())
+	assert.True(t, errors.Is(err, ErrNilContext))
+}
+
+// returns err with NativeError.Log() cleared, for comparing with expected errors
+func stripNativeLog(err error) error {
+	switch e := err.(type) {
+	case NativeError:
+		e.log = nil
+		return e
+	case kindErr:
+		e.err = stripNativeLog(e.err)
+		return e
+	}
+	return err
+}
+
+func TestErrors_NativeLog(t *testing.T) {
+	err := newNativeErrLog("roc_sender_connect()", -1, []LogMessage{
+		{Level: LogDebug, Text: "debug text"},
+		{Level: LogError, Text: "bad interface"},
+		{Level: LogError, Text: "address already in use"},
+	})
+
+	assert.Equal(t,
+		"roc_sender_connect() failed with code -1: bad interface; address already in use",
+		err.Error())
+	assert.Equal(t, "roc_sender_connect() failed with code -1",
+		stripNativeLog(err).Error())
+}
+
+func TestErrors_Comparable(t *testing.T) {
+	log := []LogMessage{{Level: LogError, Text: "bad interface"}}
+
+	var a error = newNativeErrLog("roc_sender_connect()", -1, log)
+	var b error = wrapErr(ErrBadEndpoint, newNativeErrLog("roc_sender_connect()", -1, log))
+
+	assert.NotPanics(t, func() {
+		assert.True(t, a == a)
+		assert.False(t, a == b)
+		assert.True(t, b == b)
+	})
+
+	assert.True(t, newNativeErr("roc_sender_open()", -1) == newNativeErr("roc_sender_open()", -1))
+}
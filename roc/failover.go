@@ -0,0 +1,463 @@
+package roc
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Default hold-off used by FailoverReceiver.
+const defaultFailoverHoldOff = 500 * time.Millisecond
+
+// Default crossfade used by FailoverReceiver.
+const defaultFailoverCrossfade = 50 * time.Millisecond
+
+// How often FailoverReceiver queries connection metrics of paths.
+const failoverQueryInterval = 50 * time.Millisecond
+
+// Failover configuration.
+//
+// See OpenFailoverReceiver().
+type FailoverConfig struct {
+	// How long a path should be dead before switching away from it, and how
+	// long a higher-priority path should be alive before switching back to it.
+	//
+	// Protects from switching back and forth when a path is unstable.
+	//
+	// If zero, default value is used. If negative, switching happens
+	// immediately.
+	HoldOff time.Duration
+
+	// Duration of crossfade between old and new path when switching.
+	//
+	// If zero, default value is used. If negative, crossfade is disabled and
+	// paths are switched abruptly.
+	Crossfade time.Duration
+
+	// If true, path is also considered dead when its frame is completely
+	// silent, even if it has connections.
+	//
+	// Useful when a sender may stay connected but stop producing signal.
+	// Disabled by default, because genuine silence in the stream would also
+	// cause a switch.
+	SilenceDetection bool
+}
+
+// Status of a path of FailoverReceiver.
+//
+// See FailoverReceiver.Paths().
+type FailoverPathStatus struct {
+	// Endpoints passed to OpenFailoverReceiver().
+	Endpoints *EndpointSet
+
+	// True if path had at least one connection that received packets since
+	// previous query of metrics, and (if FailoverConfig.SilenceDetection is
+	// enabled) non-silent signal during the last read.
+	Alive bool
+
+	// True if path is currently audible.
+	Selected bool
+
+	// Number of connections of path receiver, as of the last query of its
+	// metrics.
+	ConnectionCount uint32
+}
+
+// Receiver with automatic failover between redundant senders.
+//
+// FailoverReceiver receives the same stream over multiple paths (e.g. from two
+// senders on separate networks), and outputs stream from one of them. When the
+// selected path dies, it switches to another one, and when a path with higher
+// priority becomes alive again, it switches back.
+//
+// Paths are passed to OpenFailoverReceiver() in priority order, the first one
+// being the primary. Each path has its own Receiver bound to the endpoints of
+// the path. Separate receivers are used instead of multiple slots of a single
+// receiver, because the receiver mixes streams from all of its slots, while
+// here only one stream should be audible at a time.
+//
+// # Path selection
+//
+// On every read, FailoverReceiver reads a frame from every path and checks
+// whether the path is alive, i.e. its receiver has at least one connection
+// (see Receiver.Query()), and ConnectionMetrics.ExpectedPackets of its
+// connections advanced since previous query. If FailoverConfig.SilenceDetection
+// is enabled, the frame also should not be completely silent.
+//
+// Connection metrics are queried once per 50ms of samples read, not on every
+// read. When a sender dies, receiver keeps its connection until
+// ReceiverConfig.NoPlaybackTimeout expires, but the connection stops receiving
+// packets, so the path is considered dead after one or two queries. Hence,
+// detection time is about 100ms, and NoPlaybackTimeout is its upper bound.
+//
+// Selected path is switched when it was dead for FailoverConfig.HoldOff, and
+// there is another alive path. Higher-priority path is selected again when it
+// was alive for FailoverConfig.HoldOff. To avoid clicks, the old and the new
+// paths are crossfaded during FailoverConfig.Crossfade.
+//
+// Durations are measured in samples read, so they don't depend on the clock
+// source.
+//
+// # Thread safety
+//
+// Can be used concurrently. Reads are serialized.
+type FailoverReceiver struct {
+	readMu   sync.Mutex
+	mu       sync.Mutex
+	paths    []*failoverPath
+	selector failoverSelector
+
+	silenceDetection bool
+
+	// samples per channel between metrics queries, and until the next query
+	queryLen   int
+	untilQuery int
+
+	// reused on every read, guarded by readMu
+	alive []bool
+	bufs  [][]float32
+}
+
+type failoverPath struct {
+	receiver *Receiver
+	set      *EndpointSet
+	buf      []float32
+	liveness failoverLiveness
+
+	// copies for Paths(), guarded by mu
+	alive     bool
+	connCount uint32
+}
+
+// Open a new failover receiver.
+//
+// Opens one receiver per path with the given config, attaches it to the
+// context, and binds endpoints of the path to SlotDefault using
+// Receiver.BindSet(). Paths are listed in priority order. At least one path
+// is required.
+//
+// Frame encoding should have known sample rate and channel layout, since they
+// are used to compute hold-off and crossfade lengths.
+func OpenFailoverReceiver(
+	context *Context,
+	config ReceiverConfig,
+	failoverConfig FailoverConfig,
+	paths ...*EndpointSet,
+) (receiver *FailoverReceiver, err error) {
+	logWrite(LogDebug,
+		"entering OpenFailoverReceiver(): context=%p config=%+v failoverConfig=%+v paths=%v",
+		context, config, failoverConfig, paths,
+	)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving OpenFailoverReceiver(): context=%p receiver=%p err=%#v",
+			context, receiver, err,
+		)
+	}()
+
+	if len(paths) == 0 {
+		return nil, newErr(ErrBadEndpoint, "no paths specified")
+	}
+
+	numChans := config.FrameEncoding.channelCount()
+	if numChans == 0 || config.FrameEncoding.Rate == 0 {
+		return nil, newErr(ErrInvalidConfig,
+			fmt.Sprintf("frame encoding should have known rate and channels, got %+v",
+				config.FrameEncoding))
+	}
+
+	receiver = &FailoverReceiver{
+		selector:         makeFailoverSelector(config.FrameEncoding, failoverConfig, len(paths)),
+		silenceDetection: failoverConfig.SilenceDetection,
+		queryLen:         config.FrameEncoding.durationLen(failoverQueryInterval),
+		alive:            make([]bool, len(paths)),
+		bufs:             make([][]float32, len(paths)),
+	}
+
+	for _, set := range paths {
+		pathReceiver, err := OpenReceiver(context, config)
+		if err != nil {
+			_ = receiver.Close()
+			return nil, err
+		}
+
+		receiver.paths = append(receiver.paths, &failoverPath{
+			receiver: pathReceiver,
+			set:      set.clone(),
+		})
+
+		if err := pathReceiver.BindSet(SlotDefault, set); err != nil {
+			_ = receiver.Close()
+			return nil, err
+		}
+	}
+
+	return receiver, nil
+}
+
+// Read samples from the selected path.
+//
+// Reads a frame from every path, updates path selection, and stores samples of
+// the selected path (or crossfade of two paths) into the provided frame. See
+// Receiver.ReadFloats() for details.
+func (f *FailoverReceiver) ReadFloats(frame []float32) (err error) {
+	return f.ReadFloatsContext(context.Background(), frame)
+}
+
+// Read samples from the selected path, with cancellation.
+//
+// Same as FailoverReceiver.ReadFloats(), but the blocking can be cancelled
+// using ctx. See Receiver.ReadFloatsContext() for details.
+func (f *FailoverReceiver) ReadFloatsContext(ctx context.Context, frame []float32) (err error) {
+	if frame == nil {
+		return newErr(ErrInvalidArgument, "frame is nil")
+	}
+
+	f.readMu.Lock()
+	defer f.readMu.Unlock()
+
+	query := f.untilQuery <= 0
+	if query {
+		f.untilQuery = f.queryLen
+	}
+	f.untilQuery -= len(frame) / f.selector.numChans
+
+	for n, path := range f.paths {
+		if cap(path.buf) < len(frame) {
+			path.buf = make([]float32, len(frame))
+		}
+		f.bufs[n] = path.buf[:len(frame)]
+
+		if err := path.receiver.ReadFloatsContext(ctx, f.bufs[n]); err != nil {
+			// query again on next read
+			f.untilQuery = 0
+			return err
+		}
+
+		if query {
+			// on error, metrics are zero and path is dead
+			metrics, connMetrics, _ := path.receiver.Query(SlotDefault)
+			path.liveness.update(metrics.ConnectionCount, connMetrics)
+		}
+
+		f.alive[n] = path.liveness.alive &&
+			!(f.silenceDetection && failoverIsSilent(f.bufs[n]))
+
+		f.mu.Lock()
+		path.alive = f.alive[n]
+		path.connCount = path.liveness.connCount
+		f.mu.Unlock()
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.selector.update(f.alive, len(frame))
+	f.selector.mix(f.bufs, frame)
+
+	return nil
+}
+
+// Get index of currently selected path.
+func (f *FailoverReceiver) Selected() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.selector.current
+}
+
+// Get status of all paths.
+//
+// Returns paths in priority order. Status is updated on every read, while
+// connection count is updated once per 50ms of samples read.
+//
+// Returned values are copies and can be freely modified.
+func (f *FailoverReceiver) Paths() []FailoverPathStatus {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	statuses := make([]FailoverPathStatus, len(f.paths))
+	for n, path := range f.paths {
+		statuses[n] = FailoverPathStatus{
+			Endpoints:       path.set.clone(),
+			Alive:           path.alive,
+			Selected:        n == f.selector.current,
+			ConnectionCount: path.connCount,
+		}
+	}
+
+	return statuses
+}
+
+// Close the failover receiver.
+//
+// Closes receivers of all paths. Blocking reads that are in progress in other
+// goroutines are interrupted and return ErrClosed. If closing some receiver
+// fails, other receivers are still closed, and the first error is returned.
+func (f *FailoverReceiver) Close() (err error) {
+	logWrite(LogDebug, "entering FailoverReceiver.Close(): receiver=%p", f)
+	defer func() {
+		logWrite(LogDebug, "leaving FailoverReceiver.Close(): receiver=%p err=%#v", f, err)
+	}()
+
+	for _, path := range f.paths {
+		if closeErr := path.receiver.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+
+	return err
+}
+
+// tracks whether path receives packets, from its connection metrics
+type failoverLiveness struct {
+	alive     bool
+	connCount uint32
+	expected  uint64
+}
+
+// updates liveness after querying metrics; path is alive if it has
+// connections and they expect more packets than during previous query,
+// i.e. packets are still arriving
+//
+// connections don't have stable identity, so packets are summed; when number
+// of connections changes, the sum isn't comparable, and path is considered
+// alive until next query
+func (l *failoverLiveness) update(connCount uint32, connMetrics []ConnectionMetrics) {
+	var expected uint64
+	for _, m := range connMetrics {
+		expected += m.ExpectedPackets
+	}
+
+	l.alive = connCount != 0 && (connCount != l.connCount || expected > l.expected)
+	l.connCount = connCount
+	l.expected = expected
+}
+
+// returns true if all samples are zero
+func failoverIsSilent(frame []float32) bool {
+	for _, s := range frame {
+		if s != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// selects audible path and crossfades between paths;
+// all lengths are in samples per channel
+type failoverSelector struct {
+	numChans  int
+	holdOff   int
+	crossfade int
+
+	// selected path, and path being faded out or -1
+	current  int
+	previous int
+	fadePos  int
+
+	// how long each path is continuously alive or dead
+	aliveLen []int
+	deadLen  []int
+}
+
+func makeFailoverSelector(
+	encoding MediaEncoding, config FailoverConfig, numPaths int,
+) failoverSelector {
+	holdOff := config.HoldOff
+	if holdOff == 0 {
+		holdOff = defaultFailoverHoldOff
+	}
+	crossfade := config.Crossfade
+	if crossfade == 0 {
+		crossfade = defaultFailoverCrossfade
+	}
+
+	return failoverSelector{
+		numChans:  encoding.channelCount(),
+		holdOff:   encoding.durationLen(holdOff),
+		crossfade: encoding.durationLen(crossfade),
+		previous:  -1,
+		aliveLen:  make([]int, numPaths),
+		deadLen:   make([]int, numPaths),
+	}
+}
+
+// updates liveness of paths after reading frameLen interleaved samples from
+// each of them, and switches selected path if needed
+func (s *failoverSelector) update(alive []bool, frameLen int) {
+	numFrames := frameLen / s.numChans
+
+	for n := range alive {
+		if alive[n] {
+			s.aliveLen[n] += numFrames
+			s.deadLen[n] = 0
+		} else {
+			s.deadLen[n] += numFrames
+			s.aliveLen[n] = 0
+		}
+	}
+
+	target := s.current
+
+	// switch away from dead path to the first alive one
+	if !alive[s.current] && s.deadLen[s.current] >= s.holdOff {
+		for n := range alive {
+			if alive[n] {
+				target = n
+				break
+			}
+		}
+	}
+
+	// switch back to path with higher priority
+	for n := 0; n < target; n++ {
+		if alive[n] && s.aliveLen[n] >= s.holdOff {
+			target = n
+			break
+		}
+	}
+
+	if target == s.current {
+		return
+	}
+
+	if s.crossfade != 0 {
+		s.previous = s.current
+		s.fadePos = 0
+	}
+	s.current = target
+}
+
+// writes samples of selected path, or crossfade of previous and selected
+// paths, to out
+func (s *failoverSelector) mix(bufs [][]float32, out []float32) {
+	cur := bufs[s.current]
+
+	if s.previous < 0 {
+		copy(out, cur)
+		return
+	}
+
+	prev := bufs[s.previous]
+
+	for i := range out {
+		if s.fadePos >= s.crossfade {
+			copy(out[i:], cur[i:])
+			s.previous = -1
+			break
+		}
+
+		gain := float32(s.fadePos) / float32(s.crossfade)
+		out[i] = prev[i]*(1-gain) + cur[i]*gain
+
+		if (i+1)%s.numChans == 0 {
+			s.fadePos++
+		}
+	}
+
+	if s.fadePos >= s.crossfade {
+		s.previous = -1
+	}
+}
@@ -0,0 +1,275 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFailoverSelector(t *testing.T) {
+	encoding := MediaEncoding{
+		Rate:     1000,
+		Format:   FormatPcmFloat32,
+		Channels: ChannelLayoutStereo,
+	}
+
+	// 1 sample per channel = 1ms
+	config := FailoverConfig{
+		HoldOff:   4 * time.Millisecond,
+		Crossfade: 4 * time.Millisecond,
+	}
+
+	sel := makeFailoverSelector(encoding, config, 2)
+	require.Equal(t, 4, sel.holdOff)
+	require.Equal(t, 4, sel.crossfade)
+
+	bufs := [][]float32{
+		{1, 1, 1, 1},
+		{-1, -1, -1, -1},
+	}
+	out := make([]float32, 4)
+
+	// both dead, primary is selected
+	sel.update([]bool{false, false}, 4)
+	sel.mix(bufs, out)
+	require.Equal(t, 0, sel.current)
+	require.Equal(t, []float32{1, 1, 1, 1}, out)
+
+	// primary alive
+	sel.update([]bool{true, true}, 4)
+	require.Equal(t, 0, sel.current)
+
+	// primary dead, but not for hold-off yet
+	sel.update([]bool{false, true}, 4)
+	require.Equal(t, 0, sel.current)
+	sel.update([]bool{false, true}, 2)
+	require.Equal(t, 0, sel.current)
+
+	// primary dead for hold-off, switch with crossfade
+	sel.update([]bool{false, true}, 4)
+	require.Equal(t, 1, sel.current)
+	require.Equal(t, 0, sel.previous)
+
+	sel.mix(bufs, out)
+	require.Equal(t, []float32{1, 1, 0.5, 0.5}, out)
+	require.Equal(t, 0, sel.previous)
+
+	sel.mix(bufs, out)
+	require.Equal(t, []float32{0, 0, -0.5, -0.5}, out)
+	require.Equal(t, -1, sel.previous)
+
+	sel.mix(bufs, out)
+	require.Equal(t, []float32{-1, -1, -1, -1}, out)
+
+	// primary alive again, but not for hold-off yet
+	sel.update([]bool{true, true}, 6)
+	require.Equal(t, 1, sel.current)
+
+	// primary alive for hold-off, switch back
+	sel.update([]bool{true, true}, 2)
+	require.Equal(t, 0, sel.current)
+	require.Equal(t, 1, sel.previous)
+
+	// both dead, keep selected path
+	sel.update([]bool{false, false}, 100)
+	require.Equal(t, 0, sel.current)
+}
+
+func TestFailoverSelector_NoHoldOff(t *testing.T) {
+	encoding := MediaEncoding{
+		Rate:     1000,
+		Format:   FormatPcmFloat32,
+		Channels: ChannelLayoutMono,
+	}
+
+	config := FailoverConfig{
+		HoldOff:   -1,
+		Crossfade: -1,
+	}
+
+	sel := makeFailoverSelector(encoding, config, 3)
+	require.Equal(t, 0, sel.holdOff)
+	require.Equal(t, 0, sel.crossfade)
+
+	bufs := [][]float32{{1}, {2}, {3}}
+	out := make([]float32, 1)
+
+	sel.update([]bool{false, false, true}, 1)
+	require.Equal(t, 2, sel.current)
+	require.Equal(t, -1, sel.previous)
+
+	sel.mix(bufs, out)
+	require.Equal(t, []float32{3}, out)
+
+	sel.update([]bool{false, true, true}, 1)
+	require.Equal(t, 1, sel.current)
+
+	sel.mix(bufs, out)
+	require.Equal(t, []float32{2}, out)
+}
+
+func TestFailoverSelector_StalledConnection(t *testing.T) {
+	encoding := MediaEncoding{
+		Rate:     1000,
+		Format:   FormatPcmFloat32,
+		Channels: ChannelLayoutMono,
+	}
+
+	config := FailoverConfig{
+		HoldOff:   4 * time.Millisecond,
+		Crossfade: -1,
+	}
+
+	sel := makeFailoverSelector(encoding, config, 2)
+
+	var primary, backup failoverLiveness
+
+	// query metrics of both paths, then read 2ms frame
+	step := func(primaryExpected, backupExpected uint64) {
+		primary.update(1, []ConnectionMetrics{{ExpectedPackets: primaryExpected}})
+		backup.update(1, []ConnectionMetrics{{ExpectedPackets: backupExpected}})
+		sel.update([]bool{primary.alive, backup.alive}, 2)
+	}
+
+	step(10, 10)
+	require.True(t, primary.alive)
+	require.True(t, backup.alive)
+
+	step(20, 20)
+	require.Equal(t, 0, sel.current)
+
+	// primary sender died, but connection is not dropped yet
+	step(20, 30)
+	require.False(t, primary.alive)
+	require.Equal(t, uint32(1), primary.connCount)
+	require.Equal(t, 0, sel.current)
+
+	// stalled for hold-off, switch to backup
+	step(20, 40)
+	require.Equal(t, 1, sel.current)
+
+	// primary is back, switch after hold-off
+	step(30, 50)
+	require.True(t, primary.alive)
+	require.Equal(t, 1, sel.current)
+
+	step(40, 60)
+	require.Equal(t, 0, sel.current)
+}
+
+func TestFailoverLiveness(t *testing.T) {
+	var l failoverLiveness
+
+	l.update(0, nil)
+	require.False(t, l.alive)
+
+	l.update(1, []ConnectionMetrics{{ExpectedPackets: 10}})
+	require.True(t, l.alive)
+
+	l.update(1, []ConnectionMetrics{{ExpectedPackets: 10}})
+	require.False(t, l.alive)
+
+	// number of connections changed, sums aren't comparable
+	l.update(2, []ConnectionMetrics{{ExpectedPackets: 3}, {ExpectedPackets: 5}})
+	require.True(t, l.alive)
+
+	l.update(2, []ConnectionMetrics{{ExpectedPackets: 3}, {ExpectedPackets: 6}})
+	require.True(t, l.alive)
+
+	l.update(2, []ConnectionMetrics{{ExpectedPackets: 3}, {ExpectedPackets: 6}})
+	require.False(t, l.alive)
+
+	l.update(0, nil)
+	require.False(t, l.alive)
+}
+
+func TestFailoverSelector_Defaults(t *testing.T) {
+	encoding := MediaEncoding{
+		Rate:     44100,
+		Format:   FormatPcmFloat32,
+		Channels: ChannelLayoutStereo,
+	}
+
+	sel := makeFailoverSelector(encoding, FailoverConfig{}, 2)
+	require.Equal(t, 22050, sel.holdOff)
+	require.Equal(t, 2205, sel.crossfade)
+}
+
+func TestFailoverReceiver(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	primary, err := NewEndpointSet("127.0.0.1", 0, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	backup, err := NewEndpointSet("127.0.0.1", 0, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	receiver, err := OpenFailoverReceiver(ctx, makeReceiverConfig(), FailoverConfig{},
+		primary, backup)
+	require.NoError(t, err)
+	require.NotNil(t, receiver)
+
+	// metrics are queried on first read and then once per interval
+	for n := 0; n < 10; n++ {
+		require.NoError(t, receiver.ReadFloats(make([]float32, 100)))
+	}
+
+	err = receiver.ReadFloats(nil)
+	require.True(t, errors.Is(err, ErrInvalidArgument))
+
+	require.Equal(t, 0, receiver.Selected())
+
+	paths := receiver.Paths()
+	require.Len(t, paths, 2)
+	require.True(t, paths[0].Selected)
+	require.False(t, paths[0].Alive)
+	require.False(t, paths[1].Selected)
+	require.False(t, paths[1].Alive)
+
+	err = receiver.Close()
+	require.NoError(t, err)
+
+	err = receiver.ReadFloats(make([]float32, 100))
+	require.True(t, errors.Is(err, ErrClosed))
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestFailoverReceiver_Errors(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	path, err := NewEndpointSet("127.0.0.1", 0, FecEncodingRs8m)
+	require.NoError(t, err)
+
+	badPath, err := NewEndpointSet("127.0.0.1", 0, FecEncodingDisable)
+	require.NoError(t, err)
+	badPath.Source.Protocol = ProtoRs8mRepair
+
+	t.Run("no paths", func(t *testing.T) {
+		receiver, err := OpenFailoverReceiver(ctx, makeReceiverConfig(), FailoverConfig{})
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+		require.Nil(t, receiver)
+	})
+
+	t.Run("bad config", func(t *testing.T) {
+		receiver, err := OpenFailoverReceiver(ctx, ReceiverConfig{}, FailoverConfig{}, path)
+		require.True(t, errors.Is(err, ErrInvalidConfig))
+		require.Nil(t, receiver)
+	})
+
+	t.Run("bad path", func(t *testing.T) {
+		receiver, err := OpenFailoverReceiver(ctx, makeReceiverConfig(), FailoverConfig{},
+			path, badPath)
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+		require.Nil(t, receiver)
+	})
+
+	// all receivers were closed
+	err = ctx.Close()
+	require.NoError(t, err)
+}
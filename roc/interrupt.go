@@ -0,0 +1,30 @@
+package roc
+
+import (
+	"math"
+	"time"
+)
+
+// Maximum duration of samples passed to a single native read or write call.
+//
+// Native calls can't be interrupted, so blocking reads and writes split
+// frames into chunks of this duration, and check for cancellation and
+// closing between chunks. This bounds the time after which Close() or
+// context cancellation takes effect.
+const interruptInterval = 10 * time.Millisecond
+
+// returns number of interleaved samples in a chunk of interruptInterval
+// returns math.MaxInt32 (no chunking) for unknown encodings
+func interruptChunkLen(encoding MediaEncoding) int {
+	numChans := encoding.channelCount()
+	if numChans == 0 || encoding.Rate == 0 {
+		return math.MaxInt32
+	}
+
+	chunkLen := int(uint64(encoding.Rate) * uint64(interruptInterval) / uint64(time.Second))
+	if chunkLen == 0 {
+		chunkLen = 1
+	}
+
+	return chunkLen * numChans
+}
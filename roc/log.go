@@ -92,7 +92,7 @@ func SetLogLevel(level LogLevel) {
 	checkVersionFn()
 
 	atomic.StoreInt32(&loggerLevel, int32(level))
-	C.roc_log_set_level(C.roc_log_level(level))
+	logUpdateNativeLevel()
 }
 
 // SetLoggerFunc sets the handler for log messages.
@@ -182,6 +182,13 @@ func rocGoLogHandler(cMessage *C.roc_log_message) {
 		message.Text = C.GoString(cMessage.text)
 	}
 
+	logCaptureAppend(message)
+
+	// native level may be raised above user level by logCapture
+	if message.Level > logLevel() {
+		return
+	}
+
 	loggerChan <- message
 }
 
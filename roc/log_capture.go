@@ -0,0 +1,100 @@
+package roc
+
+/*
+#include <roc/log.h>
+
+unsigned long long rocGoThreadID();
+*/
+import "C"
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// Native log level used while at least one capture is active.
+//
+// Native library reports the actual reason of a failure only via log, at
+// error or info level, while the default level is LogError. While capturing,
+// native level is raised to this value and messages are filtered on Go side
+// instead. Debug level is not used, because native level is process-wide,
+// and debug messages of all threads would be formatted and passed to Go.
+const logCaptureLevel = LogInfo
+
+// Maximum number of messages kept by a single capture.
+const logCaptureMaxMessages = 64
+
+var (
+	logCaptureMu    sync.Mutex
+	logCaptureCount int32
+	logCaptureBufs  = make(map[uint64]*[]LogMessage)
+
+	// Serializes updates of native log level. Must not be held together with
+	// logCaptureMu, because native library may invoke log handler (which
+	// locks logCaptureMu) while holding its internal lock.
+	logNativeLevelMu sync.Mutex
+)
+
+// Invoke native function fn and capture log messages that it produced.
+//
+// The calling goroutine is locked to its OS thread during the call, and all
+// native messages with matching thread ID are collected, independently of
+// the level set by SetLogLevel.
+func logCapture(fn func() C.int) (C.int, []LogMessage) {
+	runtime.LockOSThread()
+	defer runtime.UnlockOSThread()
+
+	tid := uint64(C.rocGoThreadID())
+
+	var messages []LogMessage
+
+	logCaptureMu.Lock()
+	logCaptureBufs[tid] = &messages
+	atomic.AddInt32(&logCaptureCount, 1)
+	logCaptureMu.Unlock()
+
+	logUpdateNativeLevel()
+
+	errCode := fn()
+
+	logCaptureMu.Lock()
+	delete(logCaptureBufs, tid)
+	atomic.AddInt32(&logCaptureCount, -1)
+	logCaptureMu.Unlock()
+
+	logUpdateNativeLevel()
+
+	return errCode, messages
+}
+
+// Append message to the capture of its thread, if any.
+// Invoked from native log handler.
+func logCaptureAppend(message LogMessage) {
+	if atomic.LoadInt32(&logCaptureCount) == 0 {
+		return
+	}
+
+	logCaptureMu.Lock()
+	defer logCaptureMu.Unlock()
+
+	if buf := logCaptureBufs[message.Tid]; buf != nil {
+		if len(*buf) < logCaptureMaxMessages {
+			*buf = append(*buf, message)
+		}
+	}
+}
+
+// Set native log level to user level, raised to logCaptureLevel if there
+// are active captures.
+func logUpdateNativeLevel() {
+	logNativeLevelMu.Lock()
+	defer logNativeLevelMu.Unlock()
+
+	level := logLevel()
+	if atomic.LoadInt32(&logCaptureCount) != 0 && level < logCaptureLevel {
+		level = logCaptureLevel
+	}
+
+	C.roc_log_set_level(C.roc_log_level(level))
+}
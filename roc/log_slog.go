@@ -0,0 +1,79 @@
+//go:build go1.21
+// +build go1.21
+
+package roc
+
+import (
+	"context"
+	"log/slog"
+)
+
+// LevelTrace is the slog level used for LogTrace messages.
+// It is one step more verbose than slog.LevelDebug.
+const LevelTrace = slog.LevelDebug - 4
+
+// SetSlogHandler is like SetLoggerFunc, but passes log messages to slog.Handler.
+//
+// Each message is converted to slog.Record with LogMessage.Text as message and
+// LogMessage.Time as timestamp. Other fields are added as attributes: "module",
+// "file", "line", "pid", and "tid". "file" and "line" are omitted if the source
+// location is unknown. LogLevel is mapped to slog.LevelError, slog.LevelInfo,
+// slog.LevelDebug, and LevelTrace.
+//
+// Messages for which handler's Enabled method returns false are dropped. Note
+// that SetLogLevel still controls which messages are produced in the first place.
+//
+// If a nil handler is passed, default logger is used, which passes all messages to
+// the standard logger using log.Print.
+//
+// This function is thread-safe.
+func SetSlogHandler(handler slog.Handler) {
+	checkVersionFn()
+
+	if handler == nil {
+		SetLoggerFunc(nil)
+		return
+	}
+
+	loggerFunc.Store(slog2func(handler))
+}
+
+func slog2func(handler slog.Handler) LoggerFunc {
+	return func(message LogMessage) {
+		ctx := context.Background()
+		level := slogLevel(message.Level)
+
+		if !handler.Enabled(ctx, level) {
+			return
+		}
+
+		record := slog.NewRecord(message.Time, level, message.Text, 0)
+
+		record.AddAttrs(slog.String("module", message.Module))
+		if message.File != "" {
+			record.AddAttrs(
+				slog.String("file", message.File),
+				slog.Int("line", message.Line),
+			)
+		}
+		record.AddAttrs(
+			slog.Uint64("pid", message.Pid),
+			slog.Uint64("tid", message.Tid),
+		)
+
+		_ = handler.Handle(ctx, record)
+	}
+}
+
+func slogLevel(level LogLevel) slog.Level {
+	switch level {
+	case LogError:
+		return slog.LevelError
+	case LogInfo:
+		return slog.LevelInfo
+	case LogDebug:
+		return slog.LevelDebug
+	default:
+		return LevelTrace
+	}
+}
@@ -0,0 +1,101 @@
+//go:build go1.21
+// +build go1.21
+
+package roc
+
+import (
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog_SlogLevels(t *testing.T) {
+	tests := []struct {
+		level LogLevel
+		want  slog.Level
+	}{
+		{LogError, slog.LevelError},
+		{LogInfo, slog.LevelInfo},
+		{LogDebug, slog.LevelDebug},
+		{LogTrace, LevelTrace},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.want, slogLevel(tt.level))
+		})
+	}
+}
+
+func TestLog_SlogFields(t *testing.T) {
+	tw := makeTestWriter()
+	handler := slog.NewJSONHandler(&tw, &slog.HandlerOptions{Level: LevelTrace})
+
+	slog2func(handler)(LogMessage{
+		Level:  LogInfo,
+		Module: "roc_go",
+		File:   "roc/context.go",
+		Line:   42,
+		Time:   time.Unix(1000, 0),
+		Pid:    100,
+		Tid:    200,
+		Text:   "hello",
+	})
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(tw.waitAny()), &rec))
+
+	assert.Equal(t, "INFO", rec["level"])
+	assert.Equal(t, "hello", rec["msg"])
+	assert.Equal(t, "roc_go", rec["module"])
+	assert.Equal(t, "roc/context.go", rec["file"])
+	assert.Equal(t, float64(42), rec["line"])
+	assert.Equal(t, float64(100), rec["pid"])
+	assert.Equal(t, float64(200), rec["tid"])
+}
+
+func TestLog_SlogEnabled(t *testing.T) {
+	tw := makeTestWriter()
+	handler := slog.NewJSONHandler(&tw, &slog.HandlerOptions{Level: slog.LevelInfo})
+
+	fn := slog2func(handler)
+	fn(LogMessage{Level: LogDebug, Text: "dropped"})
+	fn(LogMessage{Level: LogError, Text: "kept"})
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(tw.waitAny()), &rec))
+
+	assert.Equal(t, "kept", rec["msg"])
+	assert.NotContains(t, rec, "file")
+	assert.NotContains(t, rec, "line")
+}
+
+func TestLog_SlogHandler(t *testing.T) {
+	logDrain()
+
+	SetLogLevel(LogDebug)
+	defer SetLogLevel(defaultLogLevel)
+
+	tw := makeTestWriter()
+	SetSlogHandler(slog.NewJSONHandler(&tw, &slog.HandlerOptions{Level: LevelTrace}))
+	defer SetSlogHandler(nil)
+
+	ctx, err := OpenContext(ContextConfig{})
+	require.NoError(t, err)
+	ctx.Close()
+
+	msg := tw.waitAny()
+	if msg == "" {
+		t.Fatal("expected logs, didn't get them before timeout")
+	}
+
+	var rec map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(msg), &rec))
+	assert.NotEmpty(t, rec["module"])
+	assert.NotEmpty(t, rec["pid"])
+	assert.NotEmpty(t, rec["tid"])
+}
@@ -1,6 +1,7 @@
 package roc
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
@@ -239,3 +240,129 @@ func TestLog_Write(t *testing.T) {
 		}
 	}
 }
+
+func TestLog_Capture(t *testing.T) {
+	logDrain()
+
+	SetLogLevel(LogNone)
+	defer SetLogLevel(defaultLogLevel)
+
+	ch := make(chan LogMessage, 100)
+
+	SetLoggerFunc(func(msg LogMessage) {
+		if msg.Module != "roc_go" {
+			select {
+			case ch <- msg:
+			default:
+			}
+		}
+	})
+	defer SetLoggerFunc(nil)
+
+	ctx, err := OpenContext(ContextConfig{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	config := makeInterfaceConfig()
+	config.OutgoingAddress = "256.256.256.256"
+
+	// address is rejected and failure is logged on the calling thread
+	err = receiver.Configure(SlotDefault, InterfaceAudioSource, config)
+	require.Error(t, err)
+
+	var nativeErr NativeError
+	require.True(t, errors.As(err, &nativeErr))
+	require.Equal(t, "roc_receiver_configure()", nativeErr.Op)
+	require.NotEmpty(t, nativeErr.Log())
+
+	hasError := false
+	for _, msg := range nativeErr.Log() {
+		assert.Equal(t, nativeErr.Log()[0].Tid, msg.Tid)
+		assert.NotEmpty(t, msg.Text)
+		if msg.Level == LogError {
+			hasError = true
+		}
+	}
+	require.True(t, hasError)
+
+	// error-level messages are included into error text
+	require.NotEqual(t, stripNativeLog(err).Error(), err.Error())
+
+	// captured messages are not passed to logger when level is LogNone
+	select {
+	case msg := <-ch:
+		t.Fatalf("unexpected message passed to logger: %+v", msg)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestLog_CaptureBind(t *testing.T) {
+	logDrain()
+
+	SetLogLevel(LogError)
+	defer SetLogLevel(defaultLogLevel)
+
+	ch := make(chan LogMessage, 100)
+
+	SetLoggerFunc(func(msg LogMessage) {
+		if msg.Module != "roc_go" {
+			select {
+			case ch <- msg:
+			default:
+			}
+		}
+	})
+	defer SetLoggerFunc(nil)
+
+	ctx, err := OpenContext(ContextConfig{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	receiver1, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	defer receiver1.Close()
+
+	receiver2, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	defer receiver2.Close()
+
+	endp, err := ParseEndpoint("rtp://127.0.0.1:0")
+	require.NoError(t, err)
+
+	err = receiver1.Bind(SlotDefault, InterfaceAudioSource, endp)
+	require.NoError(t, err)
+
+	// port is already in use
+	err = receiver2.Bind(SlotDefault, InterfaceAudioSource, endp)
+	require.Error(t, err)
+
+	var nativeErr NativeError
+	require.True(t, errors.As(err, &nativeErr))
+	require.Equal(t, "roc_receiver_bind()", nativeErr.Op)
+	require.NotEmpty(t, nativeErr.Log())
+
+	// only messages of the calling thread are attached
+	tid := nativeErr.Log()[0].Tid
+	for _, msg := range nativeErr.Log() {
+		assert.Equal(t, tid, msg.Tid)
+		assert.LessOrEqual(t, int(msg.Level), int(LogInfo))
+		assert.NotContains(t, strings.ToLower(msg.Text), "in use")
+	}
+
+	// the actual reason is reported by network thread and passed to logger
+	timeout := time.After(100 * time.Millisecond)
+	for done := false; !done; {
+		select {
+		case msg := <-ch:
+			if strings.Contains(strings.ToLower(msg.Text), "in use") {
+				assert.NotEqual(t, tid, msg.Tid)
+			}
+		case <-timeout:
+			done = true
+		}
+	}
+}
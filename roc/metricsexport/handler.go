@@ -0,0 +1,345 @@
+// Package metricsexport exports metrics of Roc senders and receivers in
+// OpenMetrics text format, which can be scraped by Prometheus and compatible
+// monitoring systems.
+//
+// The package doesn't depend on Prometheus client libraries. Handler
+// implements http.Handler and can be mounted on any HTTP server:
+//
+//	handler := metricsexport.NewHandler()
+//	handler.AddReceiver("main", receiver)
+//	http.Handle("/metrics", handler)
+//
+// Every scrape queries native metrics of each registered sender and receiver
+// (connection count, per-connection end-to-end latency and jitter, expected
+// and lost packets summed over connections of a slot) and Go-side counters
+// (frames and samples written or read, and errors returned by write and read
+// calls).
+//
+// Connections don't have stable identity: "connection" label is the position
+// of connection in the list reported by the native library, which shifts when
+// some connection is closed. For this reason, packet totals are exported per
+// slot, and only latency and jitter are exported per connection. Per-slot
+// packet totals cover only active connections and decrease when some
+// connection is closed, so they're exported as gauges, not counters; use
+// delta() or deriv() instead of rate() on them.
+//
+// Count of packets recovered by FEC is not exported, because it's not
+// reported by the native library yet; lost packets already exclude them.
+package metricsexport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/roc-streaming/roc-go/roc"
+)
+
+// Handler is http.Handler that exports metrics of registered senders and
+// receivers in OpenMetrics text format.
+//
+// Each sender or receiver is registered under a name, which is exported as
+// "sender" or "receiver" label. Names should be unique among senders and
+// among receivers.
+//
+// Handler doesn't own registered objects. They should be removed from
+// handler before or after they're closed; closed objects are exported with
+// "up" metric set to zero and only Go-side counters. Slots that can't be
+// queried, e.g. because nothing was bound or connected to them yet, are
+// skipped and don't affect "up".
+//
+// Handler can be used concurrently.
+type Handler struct {
+	mu        sync.Mutex
+	senders   map[string]entry
+	receivers map[string]entry
+}
+
+// registered sender or receiver
+type entry struct {
+	peer  peer
+	slots []roc.Slot
+}
+
+// common interface for sender and receiver
+type peer interface {
+	query(slot roc.Slot) (connCount uint32, connMetrics []roc.ConnectionMetrics, err error)
+	stats() (frames uint64, samples uint64, errors uint64)
+}
+
+type senderPeer struct {
+	sender *roc.Sender
+}
+
+func (p senderPeer) query(slot roc.Slot) (uint32, []roc.ConnectionMetrics, error) {
+	metrics, connMetrics, err := p.sender.Query(slot)
+	return metrics.ConnectionCount, connMetrics, err
+}
+
+func (p senderPeer) stats() (uint64, uint64, uint64) {
+	stats := p.sender.Stats()
+	return stats.FramesWritten, stats.SamplesWritten, stats.WriteErrors
+}
+
+type receiverPeer struct {
+	receiver *roc.Receiver
+}
+
+func (p receiverPeer) query(slot roc.Slot) (uint32, []roc.ConnectionMetrics, error) {
+	metrics, connMetrics, err := p.receiver.Query(slot)
+	return metrics.ConnectionCount, connMetrics, err
+}
+
+func (p receiverPeer) stats() (uint64, uint64, uint64) {
+	stats := p.receiver.Stats()
+	return stats.FramesRead, stats.SamplesRead, stats.ReadErrors
+}
+
+// NewHandler creates handler without registered objects.
+func NewHandler() *Handler {
+	return &Handler{
+		senders:   make(map[string]entry),
+		receivers: make(map[string]entry),
+	}
+}
+
+// AddSender registers sender under given name.
+//
+// Metrics are queried for given slots. If no slots are specified,
+// roc.SlotDefault is used.
+func (h *Handler) AddSender(name string, sender *roc.Sender, slots ...roc.Slot) error {
+	if sender == nil {
+		return errors.New("sender is nil")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.senders[name]; ok {
+		return fmt.Errorf("sender %q already registered", name)
+	}
+
+	h.senders[name] = entry{peer: senderPeer{sender}, slots: defaultSlots(slots)}
+
+	return nil
+}
+
+// AddReceiver registers receiver under given name.
+//
+// Metrics are queried for given slots. If no slots are specified,
+// roc.SlotDefault is used.
+func (h *Handler) AddReceiver(name string, receiver *roc.Receiver, slots ...roc.Slot) error {
+	if receiver == nil {
+		return errors.New("receiver is nil")
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if _, ok := h.receivers[name]; ok {
+		return fmt.Errorf("receiver %q already registered", name)
+	}
+
+	h.receivers[name] = entry{peer: receiverPeer{receiver}, slots: defaultSlots(slots)}
+
+	return nil
+}
+
+// RemoveSender unregisters sender with given name.
+// Does nothing if there is no such sender.
+func (h *Handler) RemoveSender(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.senders, name)
+}
+
+// RemoveReceiver unregisters receiver with given name.
+// Does nothing if there is no such receiver.
+func (h *Handler) RemoveReceiver(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.receivers, name)
+}
+
+// ServeHTTP writes metrics of all registered objects.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", ContentType)
+
+	_ = writeFamilies(w, h.collect())
+}
+
+func defaultSlots(slots []roc.Slot) []roc.Slot {
+	if len(slots) == 0 {
+		return []roc.Slot{roc.SlotDefault}
+	}
+	return append([]roc.Slot(nil), slots...)
+}
+
+// families of metrics exported for sender or receiver
+type peerFamilies struct {
+	up          family
+	connections family
+	e2eLatency  family
+	meanJitter  family
+	expected    family
+	lost        family
+	frames      family
+	samples     family
+	errors      family
+}
+
+func newPeerFamilies(peer string, io string) *peerFamilies {
+	prefix := "roc_" + peer + "_"
+
+	return &peerFamilies{
+		up: family{
+			name: prefix + "up",
+			typ:  typeGauge,
+			help: "Whether " + peer + " is open.",
+		},
+		connections: family{
+			name: prefix + "connections",
+			typ:  typeGauge,
+			help: "Number of active connections in slot.",
+		},
+		e2eLatency: family{
+			name: prefix + "connection_e2e_latency_seconds",
+			typ:  typeGauge,
+			unit: "seconds",
+			help: "Estimated end-to-end latency of connection.",
+		},
+		meanJitter: family{
+			name: prefix + "connection_mean_jitter_seconds",
+			typ:  typeGauge,
+			unit: "seconds",
+			help: "Estimated interarrival jitter of connection.",
+		},
+		expected: family{
+			name: prefix + "expected_packets",
+			typ:  typeGauge,
+			help: "Packets expected to be delivered via currently active connections of slot.",
+		},
+		lost: family{
+			name: prefix + "lost_packets",
+			typ:  typeGauge,
+			help: "Packets lost in currently active connections of slot and not recovered by FEC.",
+		},
+		frames: family{
+			name: prefix + "frames_" + io,
+			typ:  typeCounter,
+			help: "Frames successfully " + io + " by Go bindings.",
+		},
+		samples: family{
+			name: prefix + "samples_" + io,
+			typ:  typeCounter,
+			help: "Samples (for all channels) successfully " + io + " by Go bindings.",
+		},
+		errors: family{
+			name: prefix + "errors",
+			typ:  typeCounter,
+			help: "Errors returned by " + peer + " calls in Go bindings.",
+		},
+	}
+}
+
+func (f *peerFamilies) list() []*family {
+	return []*family{
+		&f.up, &f.connections, &f.e2eLatency, &f.meanJitter,
+		&f.expected, &f.lost, &f.frames, &f.samples, &f.errors,
+	}
+}
+
+// adds samples for one slot of sender or receiver
+//
+// native library doesn't report stable identity of connections, and their
+// order changes when some connection is closed; hence packet totals are
+// summed per slot, and only latency and jitter are exported per connection,
+// labeled by position in the list
+func (f *peerFamilies) addSlot(peerLabel label, slot roc.Slot,
+	connCount uint32, connMetrics []roc.ConnectionMetrics,
+) {
+	slotLabels := []label{peerLabel, {"slot", strconv.Itoa(int(slot))}}
+
+	f.connections.addUint(slotLabels, uint64(connCount))
+
+	var expected, lost uint64
+
+	for i, m := range connMetrics {
+		connLabels := append(slotLabels[:2:2], label{"connection", strconv.Itoa(i)})
+
+		f.e2eLatency.addFloat(connLabels, m.E2eLatency.Seconds())
+		f.meanJitter.addFloat(connLabels, m.MeanJitter.Seconds())
+
+		expected += m.ExpectedPackets
+
+		// may be negative because of duplicates
+		if m.LostPackets > 0 {
+			lost += uint64(m.LostPackets)
+		}
+	}
+
+	f.expected.addUint(slotLabels, expected)
+	f.lost.addUint(slotLabels, lost)
+}
+
+// adds samples for all slots and Go-side counters
+func (f *peerFamilies) addEntry(peerLabel label, e entry) {
+	up := true
+
+	for _, slot := range e.slots {
+		connCount, connMetrics, err := e.peer.query(slot)
+		if err != nil {
+			// other errors mean that slot doesn't exist (yet)
+			if errors.Is(err, roc.ErrClosed) {
+				up = false
+			}
+			continue
+		}
+		f.addSlot(peerLabel, slot, connCount, connMetrics)
+	}
+
+	labels := []label{peerLabel}
+
+	if up {
+		f.up.addUint(labels, 1)
+	} else {
+		f.up.addUint(labels, 0)
+	}
+
+	frames, samples, errors := e.peer.stats()
+
+	f.frames.addUint(labels, frames)
+	f.samples.addUint(labels, samples)
+	f.errors.addUint(labels, errors)
+}
+
+func (h *Handler) collect() []*family {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	senderFamilies := newPeerFamilies("sender", "written")
+	for _, name := range sortedNames(h.senders) {
+		senderFamilies.addEntry(label{"sender", name}, h.senders[name])
+	}
+
+	receiverFamilies := newPeerFamilies("receiver", "read")
+	for _, name := range sortedNames(h.receivers) {
+		receiverFamilies.addEntry(label{"receiver", name}, h.receivers[name])
+	}
+
+	return append(senderFamilies.list(), receiverFamilies.list()...)
+}
+
+func sortedNames(entries map[string]entry) []string {
+	names := make([]string, 0, len(entries))
+	for name := range entries {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,149 @@
+package metricsexport
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/roc-streaming/roc-go/roc"
+	"github.com/stretchr/testify/require"
+)
+
+func makeEncoding() roc.MediaEncoding {
+	return roc.MediaEncoding{
+		Rate:     44100,
+		Format:   roc.FormatPcmFloat32,
+		Channels: roc.ChannelLayoutStereo,
+	}
+}
+
+func scrape(t *testing.T, handler *Handler) string {
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+
+	response := recorder.Result()
+	require.Equal(t, 200, response.StatusCode)
+	require.Equal(t, ContentType, response.Header.Get("Content-Type"))
+
+	body, err := ioutil.ReadAll(response.Body)
+	require.NoError(t, err)
+
+	return string(body)
+}
+
+func TestHandler_Empty(t *testing.T) {
+	handler := NewHandler()
+
+	require.Equal(t, "# EOF\n", scrape(t, handler))
+}
+
+func TestHandler_SenderReceiver(t *testing.T) {
+	ctx, err := roc.OpenContext(roc.ContextConfig{})
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	sender, err := roc.OpenSender(ctx, roc.SenderConfig{
+		FrameEncoding:  makeEncoding(),
+		PacketEncoding: roc.PacketEncodingAvpL16Stereo,
+		ClockSource:    roc.ClockSourceExternal,
+	})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	receiver, err := roc.OpenReceiver(ctx, roc.ReceiverConfig{
+		FrameEncoding: makeEncoding(),
+		ClockSource:   roc.ClockSourceExternal,
+	})
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	require.NoError(t, sender.WriteFloats(make([]float32, 200)))
+	require.NoError(t, receiver.ReadFloats(make([]float32, 100)))
+	require.NoError(t, receiver.ReadFloats(make([]float32, 100)))
+	require.Error(t, receiver.ReadFloats(nil))
+
+	handler := NewHandler()
+
+	require.NoError(t, handler.AddSender("snd", sender))
+	require.NoError(t, handler.AddReceiver("rcv", receiver))
+
+	require.Equal(t, errors.New(`sender "snd" already registered`),
+		handler.AddSender("snd", sender))
+	require.Equal(t, errors.New(`receiver "rcv" already registered`),
+		handler.AddReceiver("rcv", receiver))
+	require.Equal(t, errors.New("sender is nil"), handler.AddSender("x", nil))
+	require.Equal(t, errors.New("receiver is nil"), handler.AddReceiver("x", nil))
+
+	body := scrape(t, handler)
+
+	for _, line := range []string{
+		`roc_sender_up{sender="snd"} 1`,
+		`roc_sender_connections{sender="snd",slot="0"} 0`,
+		`roc_sender_frames_written_total{sender="snd"} 1`,
+		`roc_sender_samples_written_total{sender="snd"} 200`,
+		`roc_sender_errors_total{sender="snd"} 0`,
+		`roc_receiver_up{receiver="rcv"} 1`,
+		`roc_receiver_connections{receiver="rcv",slot="0"} 0`,
+		`roc_receiver_frames_read_total{receiver="rcv"} 2`,
+		`roc_receiver_samples_read_total{receiver="rcv"} 200`,
+		`roc_receiver_errors_total{receiver="rcv"} 1`,
+		`# TYPE roc_receiver_frames_read counter`,
+		`# TYPE roc_receiver_connections gauge`,
+	} {
+		require.Contains(t, body, line+"\n")
+	}
+	require.True(t, strings.HasSuffix(body, "# EOF\n"))
+
+	// open object with slot that can't be queried is still up
+	require.NoError(t, handler.AddSender("idle", sender, roc.Slot(5)))
+
+	body = scrape(t, handler)
+	require.Contains(t, body, `roc_sender_up{sender="idle"} 1`+"\n")
+
+	handler.RemoveSender("idle")
+
+	// closed objects are exported as down
+	require.NoError(t, receiver.Close())
+
+	body = scrape(t, handler)
+	require.Contains(t, body, `roc_receiver_up{receiver="rcv"} 0`+"\n")
+	require.NotContains(t, body, `roc_receiver_connections{`)
+	require.Contains(t, body, `roc_receiver_frames_read_total{receiver="rcv"} 2`+"\n")
+
+	// removed objects are not exported
+	handler.RemoveSender("snd")
+	handler.RemoveReceiver("rcv")
+
+	require.Equal(t, "# EOF\n", scrape(t, handler))
+}
+
+func TestHandler_Connections(t *testing.T) {
+	families := newPeerFamilies("receiver", "read")
+
+	families.addSlot(label{"receiver", "r"}, 1, 2, []roc.ConnectionMetrics{
+		{E2eLatency: 1500000, MeanJitter: 2000000, ExpectedPackets: 100, LostPackets: 3},
+		{E2eLatency: 0, MeanJitter: 0, ExpectedPackets: 5, LostPackets: -1},
+	})
+
+	var buf strings.Builder
+	require.NoError(t, writeFamilies(&buf, families.list()))
+
+	for _, line := range []string{
+		`roc_receiver_connections{receiver="r",slot="1"} 2`,
+		`roc_receiver_connection_e2e_latency_seconds{receiver="r",slot="1",connection="0"} 0.0015`,
+		`roc_receiver_connection_mean_jitter_seconds{receiver="r",slot="1",connection="0"} 0.002`,
+		`roc_receiver_connection_e2e_latency_seconds{receiver="r",slot="1",connection="1"} 0`,
+		`roc_receiver_expected_packets{receiver="r",slot="1"} 105`,
+		`roc_receiver_lost_packets{receiver="r",slot="1"} 3`,
+		// totals decrease when connection is closed
+		`# TYPE roc_receiver_expected_packets gauge`,
+		`# TYPE roc_receiver_lost_packets gauge`,
+	} {
+		require.Contains(t, buf.String(), line+"\n")
+	}
+
+	// packet totals are not exported per connection
+	require.NotContains(t, buf.String(), `packets{receiver="r",slot="1",connection=`)
+}
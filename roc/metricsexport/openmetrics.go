@@ -0,0 +1,114 @@
+package metricsexport
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Content type of OpenMetrics text format.
+const ContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+// metric type
+type metricType string
+
+const (
+	typeGauge   metricType = "gauge"
+	typeCounter metricType = "counter"
+)
+
+// label name and value
+type label struct {
+	name  string
+	value string
+}
+
+// single sample of metric family
+type sample struct {
+	labels []label
+	value  string
+}
+
+// metric family with all its samples
+// all samples of a family should be written together
+type family struct {
+	name    string
+	typ     metricType
+	unit    string
+	help    string
+	samples []sample
+}
+
+func (f *family) addFloat(labels []label, value float64) {
+	f.samples = append(f.samples, sample{
+		labels: labels,
+		value:  strconv.FormatFloat(value, 'g', -1, 64),
+	})
+}
+
+func (f *family) addUint(labels []label, value uint64) {
+	f.samples = append(f.samples, sample{
+		labels: labels,
+		value:  strconv.FormatUint(value, 10),
+	})
+}
+
+// writes families in OpenMetrics text format, terminated by "# EOF"
+// families without samples are omitted
+func writeFamilies(w io.Writer, families []*family) error {
+	bw := bufio.NewWriter(w)
+
+	sort.SliceStable(families, func(i, j int) bool {
+		return families[i].name < families[j].name
+	})
+
+	for _, f := range families {
+		if len(f.samples) == 0 {
+			continue
+		}
+
+		bw.WriteString("# TYPE " + f.name + " " + string(f.typ) + "\n")
+		if f.unit != "" {
+			bw.WriteString("# UNIT " + f.name + " " + f.unit + "\n")
+		}
+		bw.WriteString("# HELP " + f.name + " " + escapeHelp(f.help) + "\n")
+
+		sampleName := f.name
+		if f.typ == typeCounter {
+			sampleName += "_total"
+		}
+
+		for _, s := range f.samples {
+			bw.WriteString(sampleName)
+			if len(s.labels) != 0 {
+				bw.WriteByte('{')
+				for i, l := range s.labels {
+					if i != 0 {
+						bw.WriteByte(',')
+					}
+					bw.WriteString(l.name + `="` + escapeLabel(l.value) + `"`)
+				}
+				bw.WriteByte('}')
+			}
+			bw.WriteString(" " + s.value + "\n")
+		}
+	}
+
+	bw.WriteString("# EOF\n")
+
+	return bw.Flush()
+}
+
+var helpReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+func escapeHelp(s string) string {
+	return helpReplacer.Replace(s)
+}
+
+var labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+func escapeLabel(s string) string {
+	return labelReplacer.Replace(s)
+}
@@ -0,0 +1,48 @@
+package metricsexport
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFamilies(t *testing.T) {
+	counter := &family{
+		name: "test_counter",
+		typ:  typeCounter,
+		help: "Counter with \\ and\nnewline.",
+	}
+	counter.addUint([]label{{"name", `a"b\c` + "\n"}}, 10)
+	counter.addUint(nil, 20)
+
+	gauge := &family{
+		name: "test_duration_seconds",
+		typ:  typeGauge,
+		unit: "seconds",
+		help: "Gauge.",
+	}
+	gauge.addFloat([]label{{"a", "1"}, {"b", "2"}}, 0.25)
+
+	empty := &family{
+		name: "test_empty",
+		typ:  typeGauge,
+		help: "Empty.",
+	}
+
+	var buf bytes.Buffer
+
+	err := writeFamilies(&buf, []*family{gauge, empty, counter})
+	require.NoError(t, err)
+
+	require.Equal(t, `# TYPE test_counter counter
+# HELP test_counter Counter with \\ and\nnewline.
+test_counter_total{name="a\"b\\c\n"} 10
+test_counter_total 20
+# TYPE test_duration_seconds gauge
+# UNIT test_duration_seconds seconds
+# HELP test_duration_seconds Gauge.
+test_duration_seconds{a="1",b="2"} 0.25
+# EOF
+`, buf.String())
+}
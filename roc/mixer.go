@@ -0,0 +1,299 @@
+package roc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Mixer combines streams from multiple receivers into one.
+//
+// Mixer reads frames from every added receiver using Receiver.ReadFloats(),
+// applies per-input gain and mute, converts channel layout of every input to
+// the output layout, sums inputs, and applies soft limiter to the result.
+//
+// # Limiter
+//
+// When several loud inputs are summed, the result may exceed range [-1; 1].
+// Instead of hard clipping, which causes harsh distortion, Mixer passes
+// samples below 0.8 unchanged, and smoothly compresses louder samples into
+// range (0.8; 1], so that output never exceeds full scale and louder peaks
+// remain louder. Peaks are still distorted, but gently; to avoid this
+// entirely, lower gains of inputs, so that their sum stays below 0.8.
+//
+// Unlike mixing performed by a single receiver, each input is a separate
+// receiver with its own config, e.g. its own ClockSource and latency settings.
+//
+// Supported channel layouts are ChannelLayoutMono and ChannelLayoutStereo.
+// Mono input is duplicated to both output channels; stereo input is averaged
+// to mono output. All inputs should have the same sample rate as the output,
+// and FormatPcmFloat32 format.
+//
+// Mixer doesn't own receivers. The user closes them when they're no longer
+// needed, after removing them from mixer or when mixer is no longer used.
+//
+// # Clock
+//
+// Mixer reads inputs sequentially. If some receivers use ClockSourceInternal,
+// the read blocks until it's time to decode their samples, so the output is
+// paced by them. If all receivers use ClockSourceExternal, the read doesn't
+// block, and the user is responsible for calling it at the right pace.
+//
+// # Thread safety
+//
+// Can be used concurrently. Reads are serialized.
+type Mixer struct {
+	readMu   sync.Mutex
+	mu       sync.Mutex
+	encoding MediaEncoding
+	numChans int
+	inputs   []*mixerInput
+
+	// reused on every read, guarded by readMu
+	snapshot []mixerInput
+	buf      []float32
+}
+
+type mixerInput struct {
+	receiver *Receiver
+	numChans int
+	gain     float32
+	muted    bool
+}
+
+// Create a new mixer.
+//
+// encoding defines format of the mixed stream produced by Mixer.ReadFloats().
+// It should have FormatPcmFloat32 format, non-zero rate, and mono or stereo
+// channel layout.
+func NewMixer(encoding MediaEncoding) (*Mixer, error) {
+	if err := mixerCheckEncoding(encoding); err != nil {
+		return nil, err
+	}
+
+	return &Mixer{
+		encoding: encoding,
+		numChans: encoding.channelCount(),
+	}, nil
+}
+
+// Add receiver to mixer.
+//
+// The receiver should be opened, and its FrameEncoding should have the same
+// rate as mixer encoding. New input has gain 1 and is not muted.
+//
+// Returns ErrInvalidArgument if receiver is nil, and ErrExists if it's
+// already added.
+func (m *Mixer) AddInput(receiver *Receiver) error {
+	if receiver == nil {
+		return newErr(ErrInvalidArgument, "receiver is nil")
+	}
+
+	encoding := receiver.frameEncoding
+
+	if err := mixerCheckEncoding(encoding); err != nil {
+		return err
+	}
+	if encoding.Rate != m.encoding.Rate {
+		return newErr(ErrNotSupported,
+			fmt.Sprintf("receiver rate %d doesn't match mixer rate %d",
+				encoding.Rate, m.encoding.Rate))
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.findLocked(receiver) != nil {
+		return newErr(ErrExists, "receiver is already added to mixer")
+	}
+
+	m.inputs = append(m.inputs, &mixerInput{
+		receiver: receiver,
+		numChans: encoding.channelCount(),
+		gain:     1,
+	})
+
+	return nil
+}
+
+// Remove receiver from mixer.
+//
+// The receiver is not closed. Returns ErrNotFound if it's not added.
+func (m *Mixer) RemoveInput(receiver *Receiver) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for n, input := range m.inputs {
+		if input.receiver == receiver {
+			m.inputs = append(m.inputs[:n:n], m.inputs[n+1:]...)
+			return nil
+		}
+	}
+
+	return newErr(ErrNotFound, "receiver is not added to mixer")
+}
+
+// Set gain of receiver.
+//
+// Samples of the receiver are multiplied by gain before mixing. Gain 1 keeps
+// samples unchanged. Takes effect on the next read. Returns ErrNotFound if
+// receiver is not added.
+func (m *Mixer) SetGain(receiver *Receiver, gain float32) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	input := m.findLocked(receiver)
+	if input == nil {
+		return newErr(ErrNotFound, "receiver is not added to mixer")
+	}
+
+	input.gain = gain
+
+	return nil
+}
+
+// Mute or unmute receiver.
+//
+// Muted receiver is still read, so that its stream doesn't accumulate latency,
+// but its samples are not mixed. Takes effect on the next read. Returns
+// ErrNotFound if receiver is not added.
+func (m *Mixer) SetMute(receiver *Receiver, muted bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	input := m.findLocked(receiver)
+	if input == nil {
+		return newErr(ErrNotFound, "receiver is not added to mixer")
+	}
+
+	input.muted = muted
+
+	return nil
+}
+
+// Read mixed samples.
+//
+// Reads the same duration of samples from every input, mixes them, and stores
+// result into the provided frame. Frame size should be a multiple of number of
+// output channels. If there are no inputs, the frame is filled with zeros.
+//
+// If reading from some input fails, returns its error.
+func (m *Mixer) ReadFloats(frame []float32) (err error) {
+	return m.ReadFloatsContext(context.Background(), frame)
+}
+
+// Read mixed samples, with cancellation.
+//
+// Same as Mixer.ReadFloats(), but the blocking can be cancelled using ctx.
+// See Receiver.ReadFloatsContext() for details.
+func (m *Mixer) ReadFloatsContext(ctx context.Context, frame []float32) (err error) {
+	if frame == nil {
+		return newErr(ErrInvalidArgument, "frame is nil")
+	}
+	if len(frame)%m.numChans != 0 {
+		return newErr(ErrInvalidArgument,
+			fmt.Sprintf("frame size is not a multiple of %d", m.numChans))
+	}
+
+	m.readMu.Lock()
+	defer m.readMu.Unlock()
+
+	m.mu.Lock()
+	m.snapshot = m.snapshot[:0]
+	for _, input := range m.inputs {
+		m.snapshot = append(m.snapshot, *input)
+	}
+	m.mu.Unlock()
+
+	numFrames := len(frame) / m.numChans
+
+	for n := range frame {
+		frame[n] = 0
+	}
+
+	for _, input := range m.snapshot {
+		bufLen := numFrames * input.numChans
+		if cap(m.buf) < bufLen {
+			m.buf = make([]float32, bufLen)
+		}
+		buf := m.buf[:bufLen]
+
+		if err := input.receiver.ReadFloatsContext(ctx, buf); err != nil {
+			return err
+		}
+
+		if !input.muted {
+			mixerAdd(frame, m.numChans, buf, input.numChans, input.gain)
+		}
+	}
+
+	mixerLimit(frame)
+
+	return nil
+}
+
+// should be called with m.mu locked
+func (m *Mixer) findLocked(receiver *Receiver) *mixerInput {
+	for _, input := range m.inputs {
+		if input.receiver == receiver {
+			return input
+		}
+	}
+	return nil
+}
+
+func mixerCheckEncoding(encoding MediaEncoding) error {
+	if encoding.Format != FormatPcmFloat32 {
+		return newErr(ErrNotSupported, "unsupported frame encoding format")
+	}
+	if encoding.Rate == 0 {
+		return newErr(ErrInvalidConfig, "frame encoding rate should be non-zero")
+	}
+	if encoding.Channels != ChannelLayoutMono && encoding.Channels != ChannelLayoutStereo {
+		return newErr(ErrNotSupported, "unsupported frame encoding channels")
+	}
+	return nil
+}
+
+// adds src multiplied by gain to dst, converting channel count
+// supports 1 and 2 channels
+func mixerAdd(dst []float32, dstChans int, src []float32, srcChans int, gain float32) {
+	switch {
+	case srcChans == dstChans:
+		for i := range dst {
+			dst[i] += src[i] * gain
+		}
+	case srcChans == 1 && dstChans == 2:
+		for i := range src {
+			s := src[i] * gain
+			dst[i*2] += s
+			dst[i*2+1] += s
+		}
+	case srcChans == 2 && dstChans == 1:
+		for i := range dst {
+			dst[i] += (src[i*2] + src[i*2+1]) / 2 * gain
+		}
+	}
+}
+
+// Level above which samples are compressed by limiter.
+const mixerLimitThreshold = 0.8
+
+// soft-limits samples to range [-1; 1]: samples below threshold are kept,
+// and samples above it are compressed using tanh, which has the same slope
+// at zero, so there is no kink at threshold
+func mixerLimit(frame []float32) {
+	const headroom = 1 - mixerLimitThreshold
+
+	for i, s := range frame {
+		a := math.Abs(float64(s))
+		if a <= mixerLimitThreshold {
+			continue
+		}
+
+		a = mixerLimitThreshold + headroom*math.Tanh((a-mixerLimitThreshold)/headroom)
+
+		frame[i] = float32(math.Copysign(a, float64(s)))
+	}
+}
@@ -0,0 +1,259 @@
+package roc
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMixer_Add(t *testing.T) {
+	tests := []struct {
+		name     string
+		dst      []float32
+		dstChans int
+		src      []float32
+		srcChans int
+		gain     float32
+		want     []float32
+	}{
+		{
+			name:     "mono to mono",
+			dst:      []float32{0.1, 0.2},
+			dstChans: 1,
+			src:      []float32{0.5, -0.5},
+			srcChans: 1,
+			gain:     1,
+			want:     []float32{0.6, -0.3},
+		},
+		{
+			name:     "stereo to stereo with gain",
+			dst:      []float32{0, 0, 0, 0},
+			dstChans: 2,
+			src:      []float32{0.5, -0.5, 0.25, -0.25},
+			srcChans: 2,
+			gain:     0.5,
+			want:     []float32{0.25, -0.25, 0.125, -0.125},
+		},
+		{
+			name:     "mono to stereo",
+			dst:      []float32{0, 0, 0, 0},
+			dstChans: 2,
+			src:      []float32{0.5, -0.25},
+			srcChans: 1,
+			gain:     1,
+			want:     []float32{0.5, 0.5, -0.25, -0.25},
+		},
+		{
+			name:     "stereo to mono",
+			dst:      []float32{0, 0},
+			dstChans: 1,
+			src:      []float32{0.5, 0.25, -0.5, 0.5},
+			srcChans: 2,
+			gain:     2,
+			want:     []float32{0.75, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mixerAdd(tt.dst, tt.dstChans, tt.src, tt.srcChans, tt.gain)
+			require.InDeltaSlice(t, tt.want, tt.dst, 1e-6)
+		})
+	}
+}
+
+func TestMixer_Limit(t *testing.T) {
+	// below threshold, samples are unchanged
+	frame := []float32{-0.8, -0.5, 0, 0.5, 0.8}
+	mixerLimit(frame)
+	require.Equal(t, []float32{-0.8, -0.5, 0, 0.5, 0.8}, frame)
+
+	// sum of full-scale inputs stays below full scale, and louder input
+	// remains louder instead of being flattened
+	frame = []float32{0.9, 1, 1.5, 2}
+	mixerLimit(frame)
+
+	for i := range frame {
+		require.Greater(t, frame[i], float32(0.8))
+		require.Less(t, frame[i], float32(1))
+		if i > 0 {
+			require.Greater(t, frame[i], frame[i-1])
+		}
+	}
+
+	// huge values don't exceed full scale
+	frame = []float32{10, -10}
+	mixerLimit(frame)
+	require.Equal(t, []float32{1, -1}, frame)
+
+	// symmetric for negative samples
+	frame = []float32{1.5, -1.5}
+	mixerLimit(frame)
+	require.Equal(t, frame[0], -frame[1])
+
+	// no jump at threshold
+	edge := []float32{0.8001}
+	mixerLimit(edge)
+	require.InDelta(t, 0.8001, edge[0], 1e-6)
+}
+
+func TestMixer_LimitFullScale(t *testing.T) {
+	// two full-scale sines mixed in phase
+	const n = 100
+	frame := make([]float32, n)
+	for i := range frame {
+		s := float32(math.Sin(2 * math.Pi * float64(i) / n))
+		mixerAdd(frame[i:i+1], 1, []float32{s}, 1, 1)
+		mixerAdd(frame[i:i+1], 1, []float32{s}, 1, 1)
+	}
+
+	mixerLimit(frame)
+
+	for _, s := range frame {
+		require.Less(t, math.Abs(float64(s)), 1.0)
+	}
+
+	// unlike hard clipping, which flattens about a third of this sine at
+	// full scale, limited signal keeps rising until the peak
+	for i := 1; i <= n/4; i++ {
+		require.Greater(t, frame[i], frame[i-1])
+	}
+}
+
+func TestMixer_New(t *testing.T) {
+	tests := []struct {
+		name     string
+		encoding MediaEncoding
+		wantErr  error
+	}{
+		{
+			name: "stereo",
+			encoding: MediaEncoding{
+				Rate: 44100, Format: FormatPcmFloat32, Channels: ChannelLayoutStereo,
+			},
+		},
+		{
+			name: "mono",
+			encoding: MediaEncoding{
+				Rate: 44100, Format: FormatPcmFloat32, Channels: ChannelLayoutMono,
+			},
+		},
+		{
+			name: "zero rate",
+			encoding: MediaEncoding{
+				Format: FormatPcmFloat32, Channels: ChannelLayoutStereo,
+			},
+			wantErr: ErrInvalidConfig,
+		},
+		{
+			name: "bad format",
+			encoding: MediaEncoding{
+				Rate: 44100, Channels: ChannelLayoutStereo,
+			},
+			wantErr: ErrNotSupported,
+		},
+		{
+			name: "multitrack",
+			encoding: MediaEncoding{
+				Rate: 44100, Format: FormatPcmFloat32, Channels: ChannelLayoutMultitrack,
+				Tracks: 4,
+			},
+			wantErr: ErrNotSupported,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mixer, err := NewMixer(tt.encoding)
+			if tt.wantErr != nil {
+				require.True(t, errors.Is(err, tt.wantErr))
+				require.Nil(t, mixer)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, mixer)
+			}
+		})
+	}
+}
+
+func TestMixer_ReadFloats(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	stereoConfig := makeReceiverConfig()
+
+	monoConfig := makeReceiverConfig()
+	monoConfig.FrameEncoding.Channels = ChannelLayoutMono
+
+	stereo, err := OpenReceiver(ctx, stereoConfig)
+	require.NoError(t, err)
+
+	mono, err := OpenReceiver(ctx, monoConfig)
+	require.NoError(t, err)
+
+	mixer, err := NewMixer(stereoConfig.FrameEncoding)
+	require.NoError(t, err)
+
+	frame := make([]float32, 100)
+
+	// no inputs
+	frame[0] = 1
+	require.NoError(t, mixer.ReadFloats(frame))
+	require.Equal(t, make([]float32, 100), frame)
+
+	require.NoError(t, mixer.AddInput(stereo))
+	require.NoError(t, mixer.AddInput(mono))
+	require.True(t, errors.Is(mixer.AddInput(stereo), ErrExists))
+	require.True(t, errors.Is(mixer.AddInput(nil), ErrInvalidArgument))
+
+	require.NoError(t, mixer.SetGain(mono, 0.5))
+	require.NoError(t, mixer.SetMute(stereo, true))
+
+	// receivers are not connected and produce silence
+	require.NoError(t, mixer.ReadFloats(frame))
+	require.Equal(t, make([]float32, 100), frame)
+
+	require.True(t, errors.Is(mixer.ReadFloats(make([]float32, 101)), ErrInvalidArgument))
+	require.True(t, errors.Is(mixer.ReadFloats(nil), ErrInvalidArgument))
+
+	require.NoError(t, mixer.RemoveInput(stereo))
+	require.True(t, errors.Is(mixer.RemoveInput(stereo), ErrNotFound))
+	require.True(t, errors.Is(mixer.SetGain(stereo, 1), ErrNotFound))
+	require.True(t, errors.Is(mixer.SetMute(stereo, false), ErrNotFound))
+
+	require.NoError(t, mono.Close())
+
+	err = mixer.ReadFloats(frame)
+	require.True(t, errors.Is(err, ErrClosed))
+
+	require.NoError(t, stereo.Close())
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestMixer_RateMismatch(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	config := makeReceiverConfig()
+	config.FrameEncoding.Rate = 48000
+
+	receiver, err := OpenReceiver(ctx, config)
+	require.NoError(t, err)
+
+	mixer, err := NewMixer(MediaEncoding{
+		Rate: 44100, Format: FormatPcmFloat32, Channels: ChannelLayoutStereo,
+	})
+	require.NoError(t, err)
+
+	err = mixer.AddInput(receiver)
+	require.True(t, errors.Is(err, ErrNotSupported))
+
+	require.NoError(t, receiver.Close())
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
@@ -0,0 +1,316 @@
+package roc
+
+import (
+	"encoding/binary"
+	"math"
+	"time"
+)
+
+// Number of samples per channel converted at once by PcmWriter and PcmReader.
+const pcmChunkLen = 1024
+
+// Writer of interleaved PCM byte stream to sender.
+//
+// PcmWriter implements io.Writer. It accepts bytes in given PcmFormat,
+// converts them to FormatPcmFloat32, and writes to sender using
+// Sender.WriteFloats().
+//
+// Writes don't need to be aligned to sample or channel boundaries. If a write
+// ends in the middle of a sample, or in the middle of a group of samples for
+// all channels, the remaining bytes are buffered until the next write.
+//
+// PcmWriter is created using Sender.AsWriter().
+//
+// # Thread safety
+//
+// Should not be used concurrently.
+type PcmWriter struct {
+	writer    floatsWriter
+	format    PcmFormat
+	frameSize int
+	pending   []byte
+	floats    []float32
+}
+
+// Reader of interleaved PCM byte stream from receiver.
+//
+// PcmReader implements io.Reader. It reads samples from receiver using
+// Receiver.ReadFloats(), converts them from FormatPcmFloat32 to given
+// PcmFormat, and returns as bytes.
+//
+// Reads don't need to be aligned to sample or channel boundaries. If a read
+// buffer ends in the middle of a sample, the remaining bytes are returned
+// by the next read.
+//
+// Receiver produces a continuous stream (filled with zeros when there are no
+// connected senders), so PcmReader never returns io.EOF.
+//
+// PcmReader is created using Receiver.AsReader().
+//
+// # Thread safety
+//
+// Should not be used concurrently.
+type PcmReader struct {
+	reader    floatsReader
+	format    PcmFormat
+	frameSize int
+	pending   []byte
+	bytes     []byte
+	floats    []float32
+}
+
+type floatsWriter interface {
+	WriteFloats(frame []float32) error
+}
+
+type floatsReader interface {
+	ReadFloats(frame []float32) error
+}
+
+// AsWriter returns io.Writer that accepts PCM bytes in given format and
+// writes them to the sender.
+//
+// Number of channels is determined by FrameEncoding of SenderConfig.
+func (s *Sender) AsWriter(format PcmFormat) (*PcmWriter, error) {
+	return newPcmWriter(s, format, s.frameEncoding)
+}
+
+// AsReader returns io.Reader that reads samples from the receiver and
+// produces PCM bytes in given format.
+//
+// Number of channels is determined by FrameEncoding of ReceiverConfig.
+func (r *Receiver) AsReader(format PcmFormat) (*PcmReader, error) {
+	return newPcmReader(r, format, r.frameEncoding)
+}
+
+func newPcmWriter(
+	writer floatsWriter, format PcmFormat, encoding MediaEncoding,
+) (*PcmWriter, error) {
+	numChans, err := pcmCheckEncoding(format, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &PcmWriter{
+		writer:    writer,
+		format:    format,
+		frameSize: format.SampleSize() * numChans,
+		floats:    make([]float32, pcmChunkLen*numChans),
+	}
+	w.pending = make([]byte, 0, w.frameSize)
+
+	return w, nil
+}
+
+func newPcmReader(
+	reader floatsReader, format PcmFormat, encoding MediaEncoding,
+) (*PcmReader, error) {
+	numChans, err := pcmCheckEncoding(format, encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &PcmReader{
+		reader:    reader,
+		format:    format,
+		frameSize: format.SampleSize() * numChans,
+		floats:    make([]float32, pcmChunkLen*numChans),
+	}
+	r.bytes = make([]byte, pcmChunkLen*r.frameSize)
+
+	return r, nil
+}
+
+func pcmCheckEncoding(format PcmFormat, encoding MediaEncoding) (int, error) {
+	if format.SampleSize() == 0 {
+		return 0, newErr(ErrNotSupported, "unsupported pcm format")
+	}
+
+	if encoding.Format != FormatPcmFloat32 {
+		return 0, newErr(ErrNotSupported, "unsupported frame encoding format")
+	}
+
+	numChans := encoding.channelCount()
+	if numChans == 0 {
+		return 0, newErr(ErrNotSupported, "unsupported frame encoding channels")
+	}
+
+	return numChans, nil
+}
+
+// Write PCM bytes to sender.
+//
+// Converts all complete samples and writes them to sender. Incomplete
+// trailing samples are buffered until the next write.
+//
+// If ClockSourceInternal is used, blocks until all samples are written.
+func (w *PcmWriter) Write(p []byte) (n int, err error) {
+	for len(p) != 0 {
+		if len(w.pending) != 0 || len(p) < w.frameSize {
+			// accumulate bytes of incomplete frame
+			k := len(p)
+			if k > w.frameSize-len(w.pending) {
+				k = w.frameSize - len(w.pending)
+			}
+			w.pending = append(w.pending, p[:k]...)
+			p = p[k:]
+			n += k
+
+			if len(w.pending) < w.frameSize {
+				break
+			}
+
+			if err = w.writeFrames(w.pending); err != nil {
+				// keep bytes from previous writes, they were already reported
+				// as written and will be flushed by the next write
+				w.pending = w.pending[:len(w.pending)-k]
+				return n - k, err
+			}
+			w.pending = w.pending[:0]
+
+			continue
+		}
+
+		// convert complete frames directly from p
+		numFrames := len(p) / w.frameSize
+		if numFrames > pcmChunkLen {
+			numFrames = pcmChunkLen
+		}
+		chunkSize := numFrames * w.frameSize
+
+		if err = w.writeFrames(p[:chunkSize]); err != nil {
+			return n, err
+		}
+		p = p[chunkSize:]
+		n += chunkSize
+	}
+
+	return n, nil
+}
+
+func (w *PcmWriter) writeFrames(b []byte) error {
+	floats := w.floats[:len(b)/w.format.SampleSize()]
+	pcmDecode(w.format, b, floats)
+
+	return w.writer.WriteFloats(floats)
+}
+
+// Read PCM bytes from receiver.
+//
+// If there are bytes remaining from previous read, returns them. Otherwise,
+// reads enough samples from receiver to fill p, converts them, and returns.
+//
+// If ClockSourceInternal is used, blocks until samples are read.
+func (r *PcmReader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if len(r.pending) == 0 {
+		numFrames := (len(p) + r.frameSize - 1) / r.frameSize
+		if numFrames > pcmChunkLen {
+			numFrames = pcmChunkLen
+		}
+
+		floats := r.floats[:numFrames*(r.frameSize/r.format.SampleSize())]
+		if err = r.reader.ReadFloats(floats); err != nil {
+			return 0, err
+		}
+
+		r.pending = r.bytes[:numFrames*r.frameSize]
+		pcmEncode(r.format, floats, r.pending)
+	}
+
+	n = copy(p, r.pending)
+	r.pending = r.pending[n:]
+
+	return n, nil
+}
+
+// returns number of interleaved channels in frames
+// returns zero for unknown layouts
+func (encoding MediaEncoding) channelCount() int {
+	switch encoding.Channels {
+	case ChannelLayoutMono:
+		return 1
+	case ChannelLayoutStereo:
+		return 2
+	case ChannelLayoutMultitrack:
+		return int(encoding.Tracks)
+	}
+	return 0
+}
+
+// returns number of samples per channel in duration
+// returns zero if duration is negative
+func (encoding MediaEncoding) durationLen(d time.Duration) int {
+	if d < 0 {
+		return 0
+	}
+	return int(uint64(encoding.Rate) * uint64(d) / uint64(time.Second))
+}
+
+// converts PCM bytes to floats
+// len(dst) should be equal to len(src) / format.SampleSize()
+func pcmDecode(format PcmFormat, src []byte, dst []float32) {
+	switch format {
+	case PcmFormatS16LE:
+		for i := range dst {
+			v := int16(binary.LittleEndian.Uint16(src[i*2:]))
+			dst[i] = int2float(int32(v), 16)
+		}
+	case PcmFormatS24LE:
+		for i := range dst {
+			b := src[i*3 : i*3+3]
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			dst[i] = int2float(v, 24)
+		}
+	case PcmFormatS32LE:
+		for i := range dst {
+			v := int32(binary.LittleEndian.Uint32(src[i*4:]))
+			dst[i] = int2float(v, 32)
+		}
+	case PcmFormatF32LE:
+		for i := range dst {
+			dst[i] = math.Float32frombits(binary.LittleEndian.Uint32(src[i*4:]))
+		}
+	}
+}
+
+// converts floats to PCM bytes
+// len(dst) should be equal to len(src) * format.SampleSize()
+func pcmEncode(format PcmFormat, src []float32, dst []byte) {
+	switch format {
+	case PcmFormatS16LE:
+		for i, s := range src {
+			binary.LittleEndian.PutUint16(dst[i*2:], uint16(float2int(s, 16)))
+		}
+	case PcmFormatS24LE:
+		for i, s := range src {
+			v := uint32(float2int(s, 24))
+			dst[i*3] = byte(v)
+			dst[i*3+1] = byte(v >> 8)
+			dst[i*3+2] = byte(v >> 16)
+		}
+	case PcmFormatS32LE:
+		for i, s := range src {
+			binary.LittleEndian.PutUint32(dst[i*4:], uint32(float2int(s, 32)))
+		}
+	case PcmFormatF32LE:
+		for i, s := range src {
+			binary.LittleEndian.PutUint32(dst[i*4:], math.Float32bits(s))
+		}
+	}
+}
+
+// converts signed integer sample with given number of bits to float
+// result is in range [-1; 1)
+func int2float(v int32, bits uint) float32 {
+	return float32(float64(v) / float64(uint64(1)<<(bits-1)))
+}
+
+// converts float sample to signed integer with given number of bits
+// input is clipped to range [-1; 1)
+func float2int(s float32, bits uint) int32 {
+	return float2intNoise(s, bits, 0)
+}
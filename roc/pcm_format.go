@@ -0,0 +1,42 @@
+package roc
+
+// PCM byte stream format.
+//
+// Defines how samples are represented in byte streams accepted by PcmWriter
+// and produced by PcmReader. Samples are always interleaved, i.e. two channels
+// are encoded as "L R L R...".
+//
+// Unlike Format, which defines format of frames passed to the native library,
+// PcmFormat defines format of raw byte streams like files, pipes, or sockets.
+// PcmWriter and PcmReader convert between PcmFormat and FormatPcmFloat32.
+//
+//go:generate stringer -type PcmFormat -trimprefix PcmFormat -output pcm_format_string.go
+type PcmFormat int
+
+const (
+	// Signed 16-bit little-endian integers.
+	PcmFormatS16LE PcmFormat = 1
+
+	// Signed 24-bit little-endian integers, packed into 3 bytes.
+	PcmFormatS24LE PcmFormat = 2
+
+	// Signed 32-bit little-endian integers.
+	PcmFormatS32LE PcmFormat = 3
+
+	// 32-bit little-endian IEEE floats in range [-1; 1].
+	PcmFormatF32LE PcmFormat = 4
+)
+
+// SampleSize returns number of bytes per sample.
+// Returns zero for unknown formats.
+func (f PcmFormat) SampleSize() int {
+	switch f {
+	case PcmFormatS16LE:
+		return 2
+	case PcmFormatS24LE:
+		return 3
+	case PcmFormatS32LE, PcmFormatF32LE:
+		return 4
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+// Code generated by "stringer -type PcmFormat -trimprefix PcmFormat -output pcm_format_string.go"; DO NOT EDIT.
+
+package roc
+
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PcmFormatS16LE-1]
+	_ = x[PcmFormatS24LE-2]
+	_ = x[PcmFormatS32LE-3]
+	_ = x[PcmFormatF32LE-4]
+}
+
+const _PcmFormat_name = "S16LES24LES32LEF32LE"
+
+var _PcmFormat_index = [...]uint8{0, 5, 10, 15, 20}
+
+func (i PcmFormat) String() string {
+	i -= 1
+	if i < 0 || i >= PcmFormat(len(_PcmFormat_index)-1) {
+		return "PcmFormat(" + strconv.FormatInt(int64(i+1), 10) + ")"
+	}
+	return _PcmFormat_name[_PcmFormat_index[i]:_PcmFormat_index[i+1]]
+}
@@ -0,0 +1,365 @@
+package roc
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testFloatsWriter struct {
+	samples []float32
+	writes  int
+	err     error
+}
+
+func (w *testFloatsWriter) WriteFloats(frame []float32) error {
+	if w.err != nil {
+		return w.err
+	}
+	w.samples = append(w.samples, frame...)
+	w.writes++
+	return nil
+}
+
+type testFloatsReader struct {
+	next float32
+	err  error
+}
+
+func (r *testFloatsReader) ReadFloats(frame []float32) error {
+	if r.err != nil {
+		return r.err
+	}
+	for i := range frame {
+		frame[i] = r.next
+		r.next += 1.0 / 1024
+		if r.next >= 1 {
+			r.next = -1
+		}
+	}
+	return nil
+}
+
+func TestPcm_EncodeDecode(t *testing.T) {
+	tests := []struct {
+		format  PcmFormat
+		floats  []float32
+		bytes   []byte
+		epsilon float64
+	}{
+		{
+			format: PcmFormatS16LE,
+			floats: []float32{0, 0.5, -0.5, -1},
+			bytes: []byte{
+				0x00, 0x00,
+				0x00, 0x40,
+				0x00, 0xc0,
+				0x00, 0x80,
+			},
+			epsilon: 1.0 / (1 << 15),
+		},
+		{
+			format: PcmFormatS24LE,
+			floats: []float32{0, 0.5, -0.5, -1},
+			bytes: []byte{
+				0x00, 0x00, 0x00,
+				0x00, 0x00, 0x40,
+				0x00, 0x00, 0xc0,
+				0x00, 0x00, 0x80,
+			},
+			epsilon: 1.0 / (1 << 23),
+		},
+		{
+			format: PcmFormatS32LE,
+			floats: []float32{0, 0.5, -0.5, -1},
+			bytes: []byte{
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x40,
+				0x00, 0x00, 0x00, 0xc0,
+				0x00, 0x00, 0x00, 0x80,
+			},
+			epsilon: 1.0 / (1 << 24),
+		},
+		{
+			format: PcmFormatF32LE,
+			floats: []float32{0, 0.5, -0.5, -1},
+			bytes: []byte{
+				0x00, 0x00, 0x00, 0x00,
+				0x00, 0x00, 0x00, 0x3f,
+				0x00, 0x00, 0x00, 0xbf,
+				0x00, 0x00, 0x80, 0xbf,
+			},
+			epsilon: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format.String(), func(t *testing.T) {
+			encoded := make([]byte, len(tt.floats)*tt.format.SampleSize())
+			pcmEncode(tt.format, tt.floats, encoded)
+			assert.Equal(t, tt.bytes, encoded)
+
+			decoded := make([]float32, len(tt.bytes)/tt.format.SampleSize())
+			pcmDecode(tt.format, tt.bytes, decoded)
+			assert.InDeltaSlice(t, tt.floats, decoded, tt.epsilon)
+		})
+	}
+}
+
+func TestPcm_Clipping(t *testing.T) {
+	tests := []struct {
+		sample float32
+		bits   uint
+		want   int32
+	}{
+		{sample: 1, bits: 16, want: math.MaxInt16},
+		{sample: 2, bits: 16, want: math.MaxInt16},
+		{sample: -2, bits: 16, want: math.MinInt16},
+		{sample: 1, bits: 24, want: 1<<23 - 1},
+		{sample: -2, bits: 24, want: -1 << 23},
+		{sample: 1, bits: 32, want: math.MaxInt32},
+		{sample: -2, bits: 32, want: math.MinInt32},
+		{sample: float32(math.NaN()), bits: 16, want: 0},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, float2int(tt.sample, tt.bits),
+			"sample=%v bits=%v", tt.sample, tt.bits)
+	}
+}
+
+func TestPcm_Writer(t *testing.T) {
+	for _, format := range []PcmFormat{
+		PcmFormatS16LE, PcmFormatS24LE, PcmFormatS32LE, PcmFormatF32LE,
+	} {
+		t.Run(format.String(), func(t *testing.T) {
+			// more than one chunk
+			samples := make([]float32, pcmChunkLen*5)
+			for i := range samples {
+				samples[i] = float32(i%512)/512 - 0.5
+			}
+
+			data := make([]byte, len(samples)*format.SampleSize())
+			pcmEncode(format, samples, data)
+
+			fw := &testFloatsWriter{}
+
+			writer, err := newPcmWriter(fw, format, makeMediaEncoding())
+			require.NoError(t, err)
+
+			// write using chunks not aligned to samples and frames
+			for pos, step := 0, 1; pos < len(data); step = step*3 + 1 {
+				end := pos + step
+				if end > len(data) {
+					end = len(data)
+				}
+
+				n, err := writer.Write(data[pos:end])
+				require.NoError(t, err)
+				require.Equal(t, end-pos, n)
+
+				pos = end
+			}
+
+			require.Empty(t, writer.pending)
+			require.Len(t, fw.samples, len(samples))
+			require.Equal(t, samples, fw.samples)
+		})
+	}
+}
+
+func TestPcm_WriterIncomplete(t *testing.T) {
+	fw := &testFloatsWriter{}
+
+	writer, err := newPcmWriter(fw, PcmFormatS16LE, makeMediaEncoding())
+	require.NoError(t, err)
+
+	// 1.5 frames
+	n, err := writer.Write([]byte{0x00, 0x40, 0x00, 0xc0, 0x00, 0x40})
+	require.NoError(t, err)
+	require.Equal(t, 6, n)
+	require.Equal(t, []float32{0.5, -0.5}, fw.samples)
+
+	// remaining 0.5 frame
+	n, err = writer.Write([]byte{0x00, 0xc0})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []float32{0.5, -0.5, 0.5, -0.5}, fw.samples)
+}
+
+func TestPcm_WriterError(t *testing.T) {
+	fw := &testFloatsWriter{err: errors.New("test error")}
+
+	writer, err := newPcmWriter(fw, PcmFormatS16LE, makeMediaEncoding())
+	require.NoError(t, err)
+
+	n, err := writer.Write(make([]byte, 100))
+	require.Equal(t, fw.err, err)
+	require.Equal(t, 0, n)
+}
+
+func TestPcm_WriterPendingError(t *testing.T) {
+	fw := &testFloatsWriter{}
+
+	writer, err := newPcmWriter(fw, PcmFormatS16LE, makeMediaEncoding())
+	require.NoError(t, err)
+
+	// 0.5 frame is buffered
+	n, err := writer.Write([]byte{0x00, 0x40})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+
+	// flush fails, buffered bytes are kept
+	fw.err = errors.New("test error")
+
+	n, err = writer.Write([]byte{0x00, 0xc0})
+	require.Equal(t, fw.err, err)
+	require.Equal(t, 0, n)
+	require.Empty(t, fw.samples)
+
+	// flush succeeds on retry
+	fw.err = nil
+
+	n, err = writer.Write([]byte{0x00, 0xc0})
+	require.NoError(t, err)
+	require.Equal(t, 2, n)
+	require.Equal(t, []float32{0.5, -0.5}, fw.samples)
+}
+
+func TestPcm_Reader(t *testing.T) {
+	for _, format := range []PcmFormat{
+		PcmFormatS16LE, PcmFormatS24LE, PcmFormatS32LE, PcmFormatF32LE,
+	} {
+		t.Run(format.String(), func(t *testing.T) {
+			numSamples := pcmChunkLen * 5
+
+			expected := make([]float32, numSamples)
+			err := (&testFloatsReader{}).ReadFloats(expected)
+			require.NoError(t, err)
+
+			expectedData := make([]byte, numSamples*format.SampleSize())
+			pcmEncode(format, expected, expectedData)
+
+			reader, err := newPcmReader(&testFloatsReader{}, format, makeMediaEncoding())
+			require.NoError(t, err)
+
+			// read using buffers not aligned to samples and frames
+			var data bytes.Buffer
+			for step := 1; data.Len() < len(expectedData); step = step*3 + 1 {
+				buf := make([]byte, step)
+				if step > len(expectedData)-data.Len() {
+					buf = buf[:len(expectedData)-data.Len()]
+				}
+
+				n, err := reader.Read(buf)
+				require.NoError(t, err)
+				require.NotZero(t, n)
+
+				data.Write(buf[:n])
+			}
+
+			require.Equal(t, expectedData, data.Bytes())
+		})
+	}
+}
+
+func TestPcm_ReaderError(t *testing.T) {
+	fr := &testFloatsReader{err: errors.New("test error")}
+
+	reader, err := newPcmReader(fr, PcmFormatS16LE, makeMediaEncoding())
+	require.NoError(t, err)
+
+	n, err := reader.Read(make([]byte, 100))
+	require.Equal(t, fr.err, err)
+	require.Equal(t, 0, n)
+}
+
+func TestPcm_BadEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		format   PcmFormat
+		encoding MediaEncoding
+		wantErr  error
+	}{
+		{
+			name:     "bad pcm format",
+			format:   0,
+			encoding: makeMediaEncoding(),
+			wantErr:  newErr(ErrNotSupported, "unsupported pcm format"),
+		},
+		{
+			name:   "bad frame format",
+			format: PcmFormatS16LE,
+			encoding: MediaEncoding{
+				Rate:     44100,
+				Channels: ChannelLayoutStereo,
+			},
+			wantErr: newErr(ErrNotSupported, "unsupported frame encoding format"),
+		},
+		{
+			name:   "bad frame channels",
+			format: PcmFormatS16LE,
+			encoding: MediaEncoding{
+				Rate:   44100,
+				Format: FormatPcmFloat32,
+			},
+			wantErr: newErr(ErrNotSupported, "unsupported frame encoding channels"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer, err := newPcmWriter(&testFloatsWriter{}, tt.format, tt.encoding)
+			require.Equal(t, tt.wantErr, err)
+			require.Nil(t, writer)
+
+			reader, err := newPcmReader(&testFloatsReader{}, tt.format, tt.encoding)
+			require.Equal(t, tt.wantErr, err)
+			require.Nil(t, reader)
+		})
+	}
+}
+
+func TestPcm_SenderReceiver(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+	defer sender.Close()
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	writer, err := sender.AsWriter(PcmFormatS16LE)
+	require.NoError(t, err)
+
+	n, err := writer.Write(make([]byte, 400))
+	require.NoError(t, err)
+	require.Equal(t, 400, n)
+
+	reader, err := receiver.AsReader(PcmFormatS16LE)
+	require.NoError(t, err)
+
+	n, err = reader.Read(make([]byte, 400))
+	require.NoError(t, err)
+	require.Equal(t, 400, n)
+
+	err = sender.Close()
+	require.NoError(t, err)
+
+	_, err = writer.Write(make([]byte, 400))
+	require.Equal(t, newErr(ErrClosed, "sender is closed"), err)
+
+	err = receiver.Close()
+	require.NoError(t, err)
+
+	_, err = reader.Read(make([]byte, 400))
+	require.Equal(t, newErr(ErrClosed, "receiver is closed"), err)
+}
@@ -0,0 +1,213 @@
+package roc
+
+/*
+#include <roc/plugin.h>
+
+roc_plugin_plc* rocGoPlcPluginAllocate(unsigned long long factory_handle);
+void rocGoPlcPluginDeallocate(roc_plugin_plc* plugin);
+*/
+import "C"
+
+import (
+	"sync"
+)
+
+// Range of plugin identifiers accepted by Context.RegisterPlc().
+const (
+	plcPluginMinID PlcBackend = 1000
+	plcPluginMaxID PlcBackend = 9999
+)
+
+// Packet loss concealment (PLC) implementation.
+//
+// Plc is an interface for custom PLC algorithms implemented in Go. An
+// implementation is registered on a context using Context.RegisterPlc() and
+// selected on receiver using PlcBackend field of ReceiverConfig.
+//
+// When receiver is decoding a stream, it calls Plc.HistoryFrame() for every
+// frame that was successfully received, and Plc.LostFrame() for every frame
+// that was lost and was not recovered by FEC. The implementation can use
+// history frames to build an approximation of the lost signal.
+//
+// Frames contain interleaved 32-bit float samples in the encoding that was
+// passed to PlcFactory. Frames point to memory owned by the native library and
+// are valid only during the call; implementation should not retain them.
+//
+// Each Plc instance is used by one connection, and its calls are serialized,
+// so it doesn't need to be thread-safe.
+type Plc interface {
+	// Process frame that was successfully decoded.
+	//
+	// Called for every frame that was not lost. Implementation may use it to
+	// remember recent history of the signal. The frame should not be modified.
+	HistoryFrame(frame []float32)
+
+	// Fill frame that was lost.
+	//
+	// Called for every frame that was lost and could not be recovered by FEC.
+	// Implementation should fill lostFrame with the concealed signal.
+	//
+	// If implementation also implements PlcLookahead, nextFrame contains
+	// samples that follow the lost frame, up to the requested lookahead length.
+	// Otherwise, or if the following samples are not available yet, nextFrame
+	// is empty.
+	LostFrame(lostFrame []float32, nextFrame []float32)
+}
+
+// Optional extension of Plc interface.
+//
+// If Plc implementation also implements PlcLookahead, receiver will provide
+// it with samples following the lost frame, when they are available.
+type PlcLookahead interface {
+	// Get look-ahead length.
+	//
+	// Returns how many samples per channel after the lost frame the
+	// implementation needs. Returned value should not change over time.
+	LookaheadLen() int
+}
+
+// Factory for Plc instances.
+//
+// Called by receiver when a new connection is created, with the encoding of
+// the connection stream. If the factory returns nil, the connection fails.
+type PlcFactory func(encoding MediaEncoding) Plc
+
+// registered PLC plugin
+type plcPlugin struct {
+	cPtr   *C.roc_plugin_plc
+	handle uint64
+}
+
+// Handle tables for PLC factories and instances.
+// Native library refers to factories and instances by integer handles,
+// because it's not allowed to keep Go pointers in C memory.
+var (
+	plcMu         sync.Mutex
+	plcLastHandle uint64
+	plcFactories  = make(map[uint64]PlcFactory)
+	plcInstances  = make(map[uint64]Plc)
+)
+
+func plcNextHandle() uint64 {
+	plcLastHandle++
+	return plcLastHandle
+}
+
+func plcAllocate(factory PlcFactory) (plcPlugin, bool) {
+	plcMu.Lock()
+	defer plcMu.Unlock()
+
+	handle := plcNextHandle()
+
+	cPlugin := C.rocGoPlcPluginAllocate(C.ulonglong(handle))
+	if cPlugin == nil {
+		return plcPlugin{}, false
+	}
+
+	plcFactories[handle] = factory
+
+	return plcPlugin{cPtr: cPlugin, handle: handle}, true
+}
+
+func plcDeallocate(plugin plcPlugin) {
+	plcMu.Lock()
+	defer plcMu.Unlock()
+
+	delete(plcFactories, plugin.handle)
+
+	C.rocGoPlcPluginDeallocate(plugin.cPtr)
+}
+
+func plcLookupInstance(handle C.ulonglong) Plc {
+	plcMu.Lock()
+	defer plcMu.Unlock()
+
+	return plcInstances[uint64(handle)]
+}
+
+// Create PLC instance.
+// Invoked from C library when receiver creates a new connection.
+// Returns zero on failure.
+//
+//export rocGoPlcNew
+func rocGoPlcNew(factoryHandle C.ulonglong, cEncoding *C.roc_media_encoding) C.ulonglong {
+	plcMu.Lock()
+	factory := plcFactories[uint64(factoryHandle)]
+	plcMu.Unlock()
+
+	if factory == nil {
+		return 0
+	}
+
+	encoding := MediaEncoding{
+		Rate:     uint32(cEncoding.rate),
+		Format:   Format(cEncoding.format),
+		Channels: ChannelLayout(cEncoding.channels),
+		Tracks:   uint32(cEncoding.tracks),
+	}
+
+	plc := factory(encoding)
+	if plc == nil {
+		logWrite(LogError, "plc factory returned nil: encoding=%+v", encoding)
+		return 0
+	}
+
+	plcMu.Lock()
+	defer plcMu.Unlock()
+
+	handle := plcNextHandle()
+	plcInstances[handle] = plc
+
+	return C.ulonglong(handle)
+}
+
+// Destroy PLC instance.
+// Invoked from C library when receiver destroys connection.
+//
+//export rocGoPlcDelete
+func rocGoPlcDelete(handle C.ulonglong) {
+	plcMu.Lock()
+	defer plcMu.Unlock()
+
+	delete(plcInstances, uint64(handle))
+}
+
+// Get PLC instance look-ahead length.
+// Invoked from C library.
+//
+//export rocGoPlcLookaheadLen
+func rocGoPlcLookaheadLen(handle C.ulonglong) C.uint {
+	if plc, ok := plcLookupInstance(handle).(PlcLookahead); ok {
+		if n := plc.LookaheadLen(); n > 0 {
+			return C.uint(n)
+		}
+	}
+
+	return 0
+}
+
+// Pass history frame to PLC instance.
+// Invoked from C library.
+//
+//export rocGoPlcProcessHistory
+func rocGoPlcProcessHistory(handle C.ulonglong, samples *C.float, samplesSize C.ulong) {
+	if plc := plcLookupInstance(handle); plc != nil {
+		plc.HistoryFrame(c2goFloats(samples, samplesSize))
+	}
+}
+
+// Pass lost frame to PLC instance.
+// Invoked from C library.
+//
+//export rocGoPlcProcessLoss
+func rocGoPlcProcessLoss(handle C.ulonglong,
+	lostSamples *C.float, lostSamplesSize C.ulong,
+	nextSamples *C.float, nextSamplesSize C.ulong,
+) {
+	if plc := plcLookupInstance(handle); plc != nil {
+		plc.LostFrame(
+			c2goFloats(lostSamples, lostSamplesSize),
+			c2goFloats(nextSamples, nextSamplesSize),
+		)
+	}
+}
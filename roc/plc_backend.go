@@ -0,0 +1,27 @@
+package roc
+
+// PLC backend.
+//
+// Packet loss concealment (PLC), is used to reduce distortion caused by lost
+// packets by filling gaps with interpolated or extrapolated data.
+//
+// PLC is used when a packet was lost and FEC was not able to recover it.
+//
+// Besides built-in backends, a custom PLC implementation can be registered
+// using Context.RegisterPlc(). In this case, plugin identifier is used as the
+// backend value.
+//
+//go:generate stringer -type PlcBackend -trimprefix PlcBackend -output plc_backend_string.go
+type PlcBackend int
+
+const (
+	// Default backend.
+	//
+	// Current default is PlcBackendNone.
+	PlcBackendDefault PlcBackend = 0
+
+	// No PLC.
+	//
+	// Lost samples are filled with zeros.
+	PlcBackendNone PlcBackend = 1
+)
@@ -0,0 +1,24 @@
+// Code generated by "stringer -type PlcBackend -trimprefix PlcBackend -output plc_backend_string.go"; DO NOT EDIT.
+
+package roc
+
+import "strconv"
+
+func _() {
+	// An "invalid array index" compiler error signifies that the constant values have changed.
+	// Re-run the stringer command to generate them again.
+	var x [1]struct{}
+	_ = x[PlcBackendDefault-0]
+	_ = x[PlcBackendNone-1]
+}
+
+const _PlcBackend_name = "DefaultNone"
+
+var _PlcBackend_index = [...]uint8{0, 7, 11}
+
+func (i PlcBackend) String() string {
+	if i < 0 || i >= PlcBackend(len(_PlcBackend_index)-1) {
+		return "PlcBackend(" + strconv.FormatInt(int64(i), 10) + ")"
+	}
+	return _PlcBackend_name[_PlcBackend_index[i]:_PlcBackend_index[i+1]]
+}
@@ -0,0 +1,192 @@
+package roc
+
+import "fmt"
+
+// Default port for RTSP (RFC 2326).
+const rtspDefaultPort = 554
+
+// static properties of protocol
+type protocolInfo struct {
+	proto       Protocol
+	iface       Interface
+	fecEncoding FecEncoding
+	hasFec      bool
+	defaultPort int
+	resource    bool
+}
+
+var protocolInfos = []protocolInfo{
+	{
+		proto:       ProtoRtsp,
+		iface:       InterfaceConsolidated,
+		defaultPort: rtspDefaultPort,
+		resource:    true,
+	},
+	{
+		proto:       ProtoRtp,
+		iface:       InterfaceAudioSource,
+		fecEncoding: FecEncodingDisable,
+		hasFec:      true,
+	},
+	{
+		proto:       ProtoRtpRs8mSource,
+		iface:       InterfaceAudioSource,
+		fecEncoding: FecEncodingRs8m,
+		hasFec:      true,
+	},
+	{
+		proto:       ProtoRs8mRepair,
+		iface:       InterfaceAudioRepair,
+		fecEncoding: FecEncodingRs8m,
+		hasFec:      true,
+	},
+	{
+		proto:       ProtoRtpLdpcSource,
+		iface:       InterfaceAudioSource,
+		fecEncoding: FecEncodingLdpcStaircase,
+		hasFec:      true,
+	},
+	{
+		proto:       ProtoLdpcRepair,
+		iface:       InterfaceAudioRepair,
+		fecEncoding: FecEncodingLdpcStaircase,
+		hasFec:      true,
+	},
+	{
+		proto: ProtoRtcp,
+		iface: InterfaceAudioControl,
+	},
+}
+
+func lookupProtocolInfo(proto Protocol) (protocolInfo, bool) {
+	for _, info := range protocolInfos {
+		if info.proto == proto {
+			return info, true
+		}
+	}
+	return protocolInfo{}, false
+}
+
+// Scheme returns URI scheme of the protocol, e.g. "rtp+rs8m".
+// Returns empty string for unknown protocol.
+func (p Protocol) Scheme() string {
+	for _, n := range protocolNames {
+		if n.value == int(p) {
+			return n.name
+		}
+	}
+	return ""
+}
+
+// Interface returns the interface which the protocol can be used with.
+// Returns false for unknown protocol.
+func (p Protocol) Interface() (Interface, bool) {
+	info, ok := lookupProtocolInfo(p)
+	return info.iface, ok
+}
+
+// FecEncoding returns FEC encoding carried by the protocol.
+//
+// Returns FecEncodingDisable for ProtoRtp. Returns false for protocols not
+// related to FEC (ProtoRtsp, ProtoRtcp) and for unknown protocol.
+func (p Protocol) FecEncoding() (FecEncoding, bool) {
+	info, ok := lookupProtocolInfo(p)
+	return info.fecEncoding, ok && info.hasFec
+}
+
+// DefaultPort returns port used when Endpoint.Port is -1.
+// Returns false if the protocol doesn't have default port.
+func (p Protocol) DefaultPort() (int, bool) {
+	info, ok := lookupProtocolInfo(p)
+	return info.defaultPort, ok && info.defaultPort != 0
+}
+
+// SupportsResource reports whether Endpoint.Resource may be non-empty for
+// the protocol.
+func (p Protocol) SupportsResource() bool {
+	info, _ := lookupProtocolInfo(p)
+	return info.resource
+}
+
+// SourceProtocol returns protocol for InterfaceAudioSource compatible with
+// the FEC encoding. FecEncodingDefault is treated as FecEncodingRs8m.
+// Returns false for unknown encoding.
+func (fe FecEncoding) SourceProtocol() (Protocol, bool) {
+	switch fe.resolve() {
+	case FecEncodingDisable:
+		return ProtoRtp, true
+	case FecEncodingRs8m:
+		return ProtoRtpRs8mSource, true
+	case FecEncodingLdpcStaircase:
+		return ProtoRtpLdpcSource, true
+	}
+	return 0, false
+}
+
+// RepairProtocol returns protocol for InterfaceAudioRepair compatible with
+// the FEC encoding. FecEncodingDefault is treated as FecEncodingRs8m.
+// Returns false for FecEncodingDisable and unknown encoding.
+func (fe FecEncoding) RepairProtocol() (Protocol, bool) {
+	switch fe.resolve() {
+	case FecEncodingRs8m:
+		return ProtoRs8mRepair, true
+	case FecEncodingLdpcStaircase:
+		return ProtoLdpcRepair, true
+	}
+	return 0, false
+}
+
+// replaces FecEncodingDefault with actual encoding
+func (fe FecEncoding) resolve() FecEncoding {
+	if fe == FecEncodingDefault {
+		return FecEncodingRs8m
+	}
+	return fe
+}
+
+// checks that protocol of endpoint can be used with given interface
+func checkEndpointInterface(iface Interface, endpoint *Endpoint) error {
+	if !isKnownInterface(iface) {
+		// unknown interface is reported by native library
+		return nil
+	}
+
+	protoIface, ok := endpoint.Protocol.Interface()
+	if !ok {
+		// unknown protocol is reported by endpoint validation
+		return nil
+	}
+
+	if protoIface != iface {
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("protocol %v can't be used with interface %v, expected interface %v",
+				endpoint.Protocol, iface, protoIface))
+	}
+
+	return nil
+}
+
+// checks that protocol of endpoint can be used with given FEC encoding
+func checkEndpointFec(fecEncoding FecEncoding, endpoint *Endpoint) error {
+	protoFec, ok := endpoint.Protocol.FecEncoding()
+	if !ok {
+		return nil
+	}
+
+	if protoFec != fecEncoding.resolve() {
+		return newErr(ErrBadEndpoint,
+			fmt.Sprintf("protocol %v can't be used with fec encoding %v, expected fec encoding %v",
+				endpoint.Protocol, fecEncoding, protoFec))
+	}
+
+	return nil
+}
+
+func isKnownInterface(iface Interface) bool {
+	for _, n := range interfaceNames {
+		if n.value == int(iface) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,270 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProtocol_Meta(t *testing.T) {
+	tests := []struct {
+		proto       Protocol
+		scheme      string
+		iface       Interface
+		fecEncoding FecEncoding
+		hasFec      bool
+		defaultPort int
+		resource    bool
+	}{
+		{
+			proto:       ProtoRtsp,
+			scheme:      "rtsp",
+			iface:       InterfaceConsolidated,
+			defaultPort: 554,
+			resource:    true,
+		},
+		{
+			proto:       ProtoRtp,
+			scheme:      "rtp",
+			iface:       InterfaceAudioSource,
+			fecEncoding: FecEncodingDisable,
+			hasFec:      true,
+		},
+		{
+			proto:       ProtoRtpRs8mSource,
+			scheme:      "rtp+rs8m",
+			iface:       InterfaceAudioSource,
+			fecEncoding: FecEncodingRs8m,
+			hasFec:      true,
+		},
+		{
+			proto:       ProtoRs8mRepair,
+			scheme:      "rs8m",
+			iface:       InterfaceAudioRepair,
+			fecEncoding: FecEncodingRs8m,
+			hasFec:      true,
+		},
+		{
+			proto:       ProtoRtpLdpcSource,
+			scheme:      "rtp+ldpc",
+			iface:       InterfaceAudioSource,
+			fecEncoding: FecEncodingLdpcStaircase,
+			hasFec:      true,
+		},
+		{
+			proto:       ProtoLdpcRepair,
+			scheme:      "ldpc",
+			iface:       InterfaceAudioRepair,
+			fecEncoding: FecEncodingLdpcStaircase,
+			hasFec:      true,
+		},
+		{
+			proto:  ProtoRtcp,
+			scheme: "rtcp",
+			iface:  InterfaceAudioControl,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.proto.String(), func(t *testing.T) {
+			assert.Equal(t, tt.scheme, tt.proto.Scheme())
+
+			iface, ok := tt.proto.Interface()
+			assert.True(t, ok)
+			assert.Equal(t, tt.iface, iface)
+
+			fecEncoding, ok := tt.proto.FecEncoding()
+			assert.Equal(t, tt.hasFec, ok)
+			if tt.hasFec {
+				assert.Equal(t, tt.fecEncoding, fecEncoding)
+			}
+
+			port, ok := tt.proto.DefaultPort()
+			assert.Equal(t, tt.defaultPort != 0, ok)
+			assert.Equal(t, tt.defaultPort, port)
+
+			assert.Equal(t, tt.resource, tt.proto.SupportsResource())
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		proto := Protocol(1)
+
+		assert.Empty(t, proto.Scheme())
+
+		_, ok := proto.Interface()
+		assert.False(t, ok)
+
+		_, ok = proto.FecEncoding()
+		assert.False(t, ok)
+
+		_, ok = proto.DefaultPort()
+		assert.False(t, ok)
+
+		assert.False(t, proto.SupportsResource())
+	})
+}
+
+func TestFecEncoding_Protocols(t *testing.T) {
+	tests := []struct {
+		fecEncoding FecEncoding
+		source      Protocol
+		repair      Protocol
+		hasRepair   bool
+	}{
+		{
+			fecEncoding: FecEncodingDisable,
+			source:      ProtoRtp,
+		},
+		{
+			fecEncoding: FecEncodingDefault,
+			source:      ProtoRtpRs8mSource,
+			repair:      ProtoRs8mRepair,
+			hasRepair:   true,
+		},
+		{
+			fecEncoding: FecEncodingRs8m,
+			source:      ProtoRtpRs8mSource,
+			repair:      ProtoRs8mRepair,
+			hasRepair:   true,
+		},
+		{
+			fecEncoding: FecEncodingLdpcStaircase,
+			source:      ProtoRtpLdpcSource,
+			repair:      ProtoLdpcRepair,
+			hasRepair:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fecEncoding.String(), func(t *testing.T) {
+			source, ok := tt.fecEncoding.SourceProtocol()
+			require.True(t, ok)
+			assert.Equal(t, tt.source, source)
+
+			repair, ok := tt.fecEncoding.RepairProtocol()
+			require.Equal(t, tt.hasRepair, ok)
+			if tt.hasRepair {
+				assert.Equal(t, tt.repair, repair)
+			}
+
+			// protocols map back to the same encoding
+			sourceFec, ok := source.FecEncoding()
+			require.True(t, ok)
+			if tt.fecEncoding == FecEncodingDefault {
+				assert.Equal(t, FecEncodingRs8m, sourceFec)
+			} else {
+				assert.Equal(t, tt.fecEncoding, sourceFec)
+			}
+		})
+	}
+
+	t.Run("unknown", func(t *testing.T) {
+		_, ok := FecEncoding(100).SourceProtocol()
+		assert.False(t, ok)
+
+		_, ok = FecEncoding(100).RepairProtocol()
+		assert.False(t, ok)
+	})
+}
+
+func TestProtocol_CheckEndpoint(t *testing.T) {
+	tests := []struct {
+		name        string
+		iface       Interface
+		proto       Protocol
+		fecEncoding FecEncoding
+		wantErr     bool
+	}{
+		{
+			name:        "source ok",
+			iface:       InterfaceAudioSource,
+			proto:       ProtoRtpRs8mSource,
+			fecEncoding: FecEncodingDefault,
+		},
+		{
+			name:        "repair ok",
+			iface:       InterfaceAudioRepair,
+			proto:       ProtoLdpcRepair,
+			fecEncoding: FecEncodingLdpcStaircase,
+		},
+		{
+			name:        "no fec ok",
+			iface:       InterfaceAudioSource,
+			proto:       ProtoRtp,
+			fecEncoding: FecEncodingDisable,
+		},
+		{
+			name:        "control ok",
+			iface:       InterfaceAudioControl,
+			proto:       ProtoRtcp,
+			fecEncoding: FecEncodingRs8m,
+		},
+		{
+			name:        "consolidated ok",
+			iface:       InterfaceConsolidated,
+			proto:       ProtoRtsp,
+			fecEncoding: FecEncodingRs8m,
+		},
+		{
+			name:        "repair on source",
+			iface:       InterfaceAudioSource,
+			proto:       ProtoRs8mRepair,
+			fecEncoding: FecEncodingRs8m,
+			wantErr:     true,
+		},
+		{
+			name:        "source on consolidated",
+			iface:       InterfaceConsolidated,
+			proto:       ProtoRtp,
+			fecEncoding: FecEncodingDisable,
+			wantErr:     true,
+		},
+		{
+			name:        "fec mismatch",
+			iface:       InterfaceAudioSource,
+			proto:       ProtoRtpLdpcSource,
+			fecEncoding: FecEncodingRs8m,
+			wantErr:     true,
+		},
+		{
+			name:        "fec disabled",
+			iface:       InterfaceAudioRepair,
+			proto:       ProtoRs8mRepair,
+			fecEncoding: FecEncodingDisable,
+			wantErr:     true,
+		},
+		{
+			name:        "unknown interface",
+			iface:       -1,
+			proto:       ProtoRtp,
+			fecEncoding: FecEncodingDisable,
+		},
+		{
+			name:        "unknown protocol",
+			iface:       InterfaceAudioSource,
+			proto:       1,
+			fecEncoding: FecEncodingDisable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			endpoint := &Endpoint{Protocol: tt.proto, Host: "127.0.0.1", Port: 0}
+
+			err := checkEndpointInterface(tt.iface, endpoint)
+			if err == nil {
+				err = checkEndpointFec(tt.fecEncoding, endpoint)
+			}
+
+			if tt.wantErr {
+				require.Error(t, err)
+				require.True(t, errors.Is(err, ErrBadEndpoint))
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
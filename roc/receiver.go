@@ -10,9 +10,10 @@ int rocGoReceiverReadFloats(roc_receiver* receiver, float* samples, unsigned lon
 import "C"
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // Receiver peer.
@@ -174,8 +175,15 @@ import (
 //
 // Can be used concurrently.
 type Receiver struct {
-	mu   sync.RWMutex
-	cPtr *C.roc_receiver
+	counters      ioCounters
+	mu            sync.RWMutex
+	cPtr          *C.roc_receiver
+	frameEncoding MediaEncoding
+	dither        bool
+	closing       int32
+	release       func() error
+	slots         slotRegistry
+	scratch       sampleScratch
 }
 
 // Open a new receiver.
@@ -192,27 +200,61 @@ func OpenReceiver(context *Context, config ReceiverConfig) (receiver *Receiver,
 	checkVersionFn()
 
 	if context == nil {
-		return nil, errors.New("context is nil")
+		return nil, ErrNilContext
 	}
 
 	context.mu.RLock()
 	defer context.mu.RUnlock()
 
 	if context.cPtr == nil {
-		return nil, errors.New("context is closed")
+		return nil, newErr(ErrClosed, "context is closed")
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	cConfig, err := go2cReceiverConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var cRecv *C.roc_receiver
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_receiver_open(context.cPtr, &cConfig, &cRecv)
+	})
+	if errCode != 0 {
+		return nil, newNativeErrLog("roc_receiver_open()", errCode, messages)
+	}
+	if cRecv == nil {
+		panic("roc_receiver_open() returned nil")
+	}
+
+	receiver = &Receiver{
+		cPtr:          cRecv,
+		frameEncoding: config.FrameEncoding,
+	}
+
+	return receiver, nil
+}
+
+// builds C receiver config from Go config
+func go2cReceiverConfig(config ReceiverConfig) (C.struct_roc_receiver_config, error) {
+	var cConfig C.struct_roc_receiver_config
+
 	cTargetLatency, err := go2cUnsignedDuration(config.TargetLatency)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config.TargetLatency: %w", err)
+		return cConfig, wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.TargetLatency: %w", err))
 	}
 
 	cLatencyTolerance, err := go2cUnsignedDuration(config.LatencyTolerance)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config.LatencyTolerance: %w", err)
+		return cConfig, wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.LatencyTolerance: %w", err))
 	}
 
-	cConfig := C.struct_roc_receiver_config{
+	cConfig = C.struct_roc_receiver_config{
 		frame_encoding: C.struct_roc_media_encoding{
 			rate:     C.uint(config.FrameEncoding.Rate),
 			format:   C.roc_format(config.FrameEncoding.Format),
@@ -224,26 +266,14 @@ func OpenReceiver(context *Context, config ReceiverConfig) (receiver *Receiver,
 		latency_tuner_profile:   C.roc_latency_tuner_profile(config.LatencyTunerProfile),
 		resampler_backend:       C.roc_resampler_backend(config.ResamplerBackend),
 		resampler_profile:       C.roc_resampler_profile(config.ResamplerProfile),
+		plc_backend:             C.roc_plc_backend(config.PlcBackend),
 		target_latency:          cTargetLatency,
 		latency_tolerance:       cLatencyTolerance,
 		no_playback_timeout:     go2cSignedDuration(config.NoPlaybackTimeout),
 		choppy_playback_timeout: go2cSignedDuration(config.ChoppyPlaybackTimeout),
 	}
 
-	var cRecv *C.roc_receiver
-	errCode := C.roc_receiver_open(context.cPtr, &cConfig, &cRecv)
-	if errCode != 0 {
-		return nil, newNativeErr("roc_receiver_open()", errCode)
-	}
-	if cRecv == nil {
-		panic("roc_receiver_open() returned nil")
-	}
-
-	receiver = &Receiver{
-		cPtr: cRecv,
-	}
-
-	return receiver, nil
+	return cConfig, nil
 }
 
 // Set receiver interface configuration.
@@ -270,17 +300,19 @@ func (r *Receiver) Configure(slot Slot, iface Interface, config InterfaceConfig)
 	defer r.mu.RUnlock()
 
 	if r.cPtr == nil {
-		return errors.New("receiver is closed")
+		return newErr(ErrClosed, "receiver is closed")
 	}
 
 	cOutgoingAddress, err := go2cStr(config.OutgoingAddress)
 	if err != nil {
-		return fmt.Errorf("invalid config.OutgoingAddress: %w", err)
+		return wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.OutgoingAddress: %w", err))
 	}
 
 	cMulticastGroup, err := go2cStr(config.MulticastGroup)
 	if err != nil {
-		return fmt.Errorf("invalid config.MulticastGroup: %w", err)
+		return wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.MulticastGroup: %w", err))
 	}
 
 	var cConfig C.struct_roc_interface_config
@@ -288,25 +320,30 @@ func (r *Receiver) Configure(slot Slot, iface Interface, config InterfaceConfig)
 
 	errCode = C.rocGoSetOutgoingAddress(&cConfig, (*C.char)(&cOutgoingAddress[0]))
 	if errCode != 0 {
-		return fmt.Errorf("invalid config.OutgoingAddress: too long")
+		return newErr(ErrInvalidConfig, "invalid config.OutgoingAddress: too long")
 	}
 
 	errCode = C.rocGoSetMulticastGroup(&cConfig, (*C.char)(&cMulticastGroup[0]))
 	if errCode != 0 {
-		return fmt.Errorf("invalid config.MulticastGroup: too long")
+		return newErr(ErrInvalidConfig, "invalid config.MulticastGroup: too long")
 	}
 
 	cConfig.reuse_address = (C.int)(go2cBool(config.ReuseAddress))
 
-	errCode = C.roc_receiver_configure(
-		r.cPtr,
-		(C.roc_slot)(slot),
-		(C.roc_interface)(iface),
-		&cConfig)
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_receiver_configure(
+			r.cPtr,
+			(C.roc_slot)(slot),
+			(C.roc_interface)(iface),
+			&cConfig)
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_receiver_configure()", errCode)
+		r.slots.setBroken(slot)
+		return newNativeErrLog("roc_receiver_configure()", errCode, messages)
 	}
 
+	r.slots.setConfig(slot, iface, config)
+
 	return nil
 }
 
@@ -324,6 +361,10 @@ func (r *Receiver) Configure(slot Slot, iface Interface, config InterfaceConfig)
 // broken. The slot index remains reserved. The user is responsible for removing
 // the slot using Receiver.Unlink(), after which slot index can be reused.
 //
+// If endpoint protocol doesn't match the interface (see
+// Protocol.Interface() and Protocol.FecEncoding()), returns error matching
+// ErrBadEndpoint and leaves the slot untouched.
+//
 // If Endpoint has explicitly set zero port, the receiver is bound to a randomly
 // chosen ephemeral port. If the function succeeds, the actual port to which the
 // receiver was bound is written back to Endpoint.
@@ -341,11 +382,15 @@ func (r *Receiver) Bind(slot Slot, iface Interface, endpoint *Endpoint) (err err
 	defer r.mu.RUnlock()
 
 	if r.cPtr == nil {
-		return errors.New("receiver is closed")
+		return newErr(ErrClosed, "receiver is closed")
 	}
 
 	if endpoint == nil {
-		return errors.New("endpoint is nil")
+		return newErr(ErrBadEndpoint, "endpoint is nil")
+	}
+
+	if err = checkEndpointInterface(iface, endpoint); err != nil {
+		return err
 	}
 
 	var errCode C.int
@@ -370,22 +415,95 @@ func (r *Receiver) Bind(slot Slot, iface Interface, endpoint *Endpoint) (err err
 		return err
 	}
 
-	errCode = C.roc_receiver_bind(
-		r.cPtr,
-		(C.roc_slot)(slot),
-		(C.roc_interface)(iface),
-		cEndp)
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_receiver_bind(
+			r.cPtr,
+			(C.roc_slot)(slot),
+			(C.roc_interface)(iface),
+			cEndp)
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_receiver_bind()", errCode)
+		r.slots.setBroken(slot)
+		return newNativeErrLog("roc_receiver_bind()", errCode, messages)
 	}
 
 	if err = endpoint.fromC(cEndp); err != nil {
 		return err
 	}
 
+	r.slots.setEndpoint(slot, iface, endpoint)
+
 	return nil
 }
 
+// Bind all endpoints of the set to the slot.
+//
+// Binds InterfaceAudioSource, then InterfaceAudioRepair and
+// InterfaceAudioControl, if the corresponding endpoints are non-nil. See
+// Receiver.Bind() for details; in particular, zero ports of endpoints are
+// updated to actually bound ports.
+//
+// If any bind fails, the slot is removed using Receiver.Unlink(), so that
+// slot index can be reused immediately.
+func (r *Receiver) BindSet(slot Slot, set *EndpointSet) (err error) {
+	logWrite(LogDebug,
+		"entering Receiver.BindSet(): receiver=%p slot=%v set=%+v", r, slot, set,
+	)
+	defer func() {
+		logWrite(LogDebug, "leaving Receiver.BindSet(): receiver=%p err=%#v", r, err)
+	}()
+
+	return applyEndpointSet(set,
+		func(iface Interface, endpoint *Endpoint) error {
+			return r.Bind(slot, iface, endpoint)
+		},
+		func() error {
+			return r.Unlink(slot)
+		})
+}
+
+// Allocate a new slot.
+//
+// Reserves the first slot index that is not used by any other slot and returns
+// it. The returned slot is created in native library when it's used first time
+// (e.g. by Receiver.Bind()).
+//
+// If name is non-empty, it should be unique among slots of this receiver, and
+// can be used to find slot using Receiver.SlotByName(). If another slot already
+// has this name, returns ErrExists.
+func (r *Receiver) NewSlot(name string) (slot Slot, err error) {
+	logWrite(LogDebug, "entering Receiver.NewSlot(): receiver=%p name=%q", r, name)
+	defer func() {
+		logWrite(LogDebug, "leaving Receiver.NewSlot(): receiver=%p slot=%v err=%#v", r, slot, err)
+	}()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.cPtr == nil {
+		return 0, newErr(ErrClosed, "receiver is closed")
+	}
+
+	return r.slots.allocate(name)
+}
+
+// Find slot by name passed to Receiver.NewSlot().
+func (r *Receiver) SlotByName(name string) (Slot, bool) {
+	return r.slots.lookup(name)
+}
+
+// Get state of all slots.
+//
+// Returns slots allocated by Receiver.NewSlot() or implicitly created by other
+// methods, sorted by index, with their configured, connected, or bound
+// interfaces. Slots are removed from the list by Receiver.Unlink() and
+// Receiver.Close().
+//
+// Returned values are copies and can be freely modified.
+func (r *Receiver) Slots() []SlotInfo {
+	return r.slots.list()
+}
+
 // Delete receiver slot.
 //
 // Disconnects, unbinds, and removes all slot interfaces and removes the slot.
@@ -404,21 +522,98 @@ func (r *Receiver) Unlink(slot Slot) (err error) {
 	defer r.mu.RUnlock()
 
 	if r.cPtr == nil {
-		return errors.New("receiver is closed")
+		return newErr(ErrClosed, "receiver is closed")
 	}
 
-	var errCode C.int
+	if r.slots.removeReserved(slot) {
+		// slot was allocated by NewSlot() but never used
+		return nil
+	}
 
-	errCode = C.roc_receiver_unlink(
-		r.cPtr,
-		(C.roc_slot)(slot))
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_receiver_unlink(
+			r.cPtr,
+			(C.roc_slot)(slot))
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_receiver_unlink()", errCode)
+		return newNativeErrLog("roc_receiver_unlink()", errCode, messages)
 	}
 
+	r.slots.remove(slot)
+
 	return nil
 }
 
+// Query receiver slot metrics.
+//
+// Returns metrics of the slot and metrics of every active connection of the
+// slot. Connection metrics are reported per remote peer, i.e. one element per
+// each connected sender.
+//
+// The slot should be already created using Receiver.Configure() or
+// Receiver.Bind(). Querying a slot that was not created is an error.
+func (r *Receiver) Query(slot Slot) (
+	slotMetrics ReceiverMetrics, connMetrics []ConnectionMetrics, err error,
+) {
+	logWrite(LogDebug,
+		"entering Receiver.Query(): receiver=%p slot=%+v", r, slot,
+	)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving Receiver.Query(): receiver=%p slotMetrics=%+v connMetrics=%+v err=%#v",
+			r, slotMetrics, connMetrics, err,
+		)
+	}()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.cPtr == nil {
+		return ReceiverMetrics{}, nil, newErr(ErrClosed, "receiver is closed")
+	}
+
+	var cSlotMetrics C.struct_roc_receiver_metrics
+	var cConnMetrics []C.struct_roc_connection_metrics
+
+	// Number of connections may change between calls, so we repeat query
+	// until the array is large enough to hold metrics of all connections.
+	for {
+		var cConnMetricsPtr *C.struct_roc_connection_metrics
+		if len(cConnMetrics) != 0 {
+			cConnMetricsPtr = &cConnMetrics[0]
+		}
+		cConnMetricsCount := (C.size_t)(len(cConnMetrics))
+
+		errCode := C.roc_receiver_query(
+			r.cPtr,
+			(C.roc_slot)(slot),
+			&cSlotMetrics,
+			cConnMetricsPtr,
+			&cConnMetricsCount)
+		if errCode != 0 {
+			return ReceiverMetrics{}, nil, newNativeErr("roc_receiver_query()", errCode)
+		}
+
+		if int(cSlotMetrics.connection_count) <= len(cConnMetrics) {
+			cConnMetrics = cConnMetrics[:cConnMetricsCount]
+			break
+		}
+
+		cConnMetrics = make([]C.struct_roc_connection_metrics, cSlotMetrics.connection_count)
+	}
+
+	slotMetrics = ReceiverMetrics{
+		ConnectionCount: uint32(cSlotMetrics.connection_count),
+	}
+
+	connMetrics = make([]ConnectionMetrics, len(cConnMetrics))
+	for n := range cConnMetrics {
+		connMetrics[n].fromC(&cConnMetrics[n])
+	}
+
+	return slotMetrics, connMetrics, nil
+}
+
 // Read samples from the receiver.
 //
 // Reads retrieved network packets, decodes packets, repairs losses, extracts
@@ -433,25 +628,186 @@ func (r *Receiver) Unlink(slot Slot) (err error) {
 // If the receiver is connected to multiple senders, it mixes their streams into
 // one.
 func (r *Receiver) ReadFloats(frame []float32) (err error) {
+	return r.ReadFloatsContext(context.Background(), frame)
+}
+
+// Read samples from the receiver, with cancellation.
+//
+// Same as Receiver.ReadFloats(), but the blocking can be cancelled using ctx.
+// If ctx is cancelled or its deadline is exceeded, the function returns
+// ctx.Err(). In this case, only some leading part of the frame may be filled.
+//
+// If a read function fails in the middle of the frame, the part of the frame
+// that wasn't read is filled with zeros, and samples that were read are still
+// counted in ReceiverStats.SamplesRead.
+//
+// ReadFloats(), ReadFloatsContext() and other read functions return ErrClosed
+// if the receiver is closed concurrently by Receiver.Close() while the call
+// is in progress.
+func (r *Receiver) ReadFloatsContext(ctx context.Context, frame []float32) (err error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	if r.cPtr == nil {
-		return errors.New("receiver is closed")
+	return r.readFloats(ctx, frame)
+}
+
+// Read 16-bit integer samples from the receiver.
+//
+// Same as Receiver.ReadFloats(), but stores samples as signed 16-bit
+// integers. Samples are scaled from range [-1; 1) and clipped if they're
+// out of range. If dithering is enabled using Receiver.SetDither(), TPDF
+// dither is added before rounding.
+func (r *Receiver) ReadInt16(frame []int16) (err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.scratch.mu.Lock()
+	defer r.scratch.mu.Unlock()
+
+	var floats []float32
+	if frame != nil {
+		floats = r.scratch.get(len(frame))
 	}
 
-	if frame == nil {
-		return errors.New("frame is nil")
+	if err = r.readFloats(context.Background(), floats); err != nil {
+		return err
+	}
+
+	floatsToInt16(floats, frame, r.newDitherer())
+
+	return nil
+}
+
+// Read packed 24-bit integer samples from the receiver.
+//
+// Same as Receiver.ReadInt16(), but stores samples as signed 24-bit
+// little-endian integers, packed into 3 bytes each. Frame size should be
+// a multiple of 3.
+func (r *Receiver) ReadInt24Packed(frame []byte) (err error) {
+	if err = int24PackedCheck(frame); err != nil {
+		r.counters.count(0, err)
+		return err
 	}
 
-	if len(frame) == 0 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.scratch.mu.Lock()
+	defer r.scratch.mu.Unlock()
+
+	var floats []float32
+	if frame != nil {
+		floats = r.scratch.get(len(frame) / int24PackedSize)
+	}
+
+	if err = r.readFloats(context.Background(), floats); err != nil {
+		return err
+	}
+
+	floatsToInt24Packed(floats, frame, r.newDitherer())
+
+	return nil
+}
+
+// Read 32-bit integer samples from the receiver.
+//
+// Same as Receiver.ReadFloats(), but stores samples as signed 32-bit
+// integers. Samples are scaled from range [-1; 1) and clipped if they're
+// out of range. Since 32-bit floats don't have more precision than the
+// output, dithering is never applied.
+func (r *Receiver) ReadInt32(frame []int32) (err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.scratch.mu.Lock()
+	defer r.scratch.mu.Unlock()
+
+	var floats []float32
+	if frame != nil {
+		floats = r.scratch.get(len(frame))
+	}
+
+	if err = r.readFloats(context.Background(), floats); err != nil {
+		return err
+	}
+
+	floatsToInt32(floats, frame)
+
+	return nil
+}
+
+// Enable or disable dithering.
+//
+// When enabled, Receiver.ReadInt16() and Receiver.ReadInt24Packed() add TPDF
+// (triangular probability density function) dither noise of 1 LSB amplitude
+// before reducing precision of samples. This trades quantization distortion
+// for a constant low-level noise floor. Disabled by default.
+func (r *Receiver) SetDither(enabled bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.dither = enabled
+}
+
+// returns nil if dithering is disabled
+// should be called with r.mu locked
+func (r *Receiver) newDitherer() *ditherer {
+	if !r.dither {
 		return nil
 	}
+	d := newDitherer()
+	return &d
+}
 
-	errCode := C.rocGoReceiverReadFloats(
-		r.cPtr, (*C.float)(&frame[0]), (C.ulong)(len(frame)))
-	if errCode != 0 {
-		return newNativeErr("roc_receiver_read()", errCode)
+// should be called with r.mu locked
+func (r *Receiver) readFloats(ctx context.Context, frame []float32) (err error) {
+	var numRead int
+
+	defer func() {
+		if err != nil {
+			// don't leave garbage in the part of the frame that wasn't read
+			for n := numRead; n < len(frame); n++ {
+				frame[n] = 0
+			}
+		}
+		r.counters.count(numRead, err)
+	}()
+
+	if r.cPtr == nil {
+		return newErr(ErrClosed, "receiver is closed")
+	}
+
+	if ctx == nil {
+		return newErr(ErrInvalidArgument, "ctx is nil")
+	}
+
+	if frame == nil {
+		return newErr(ErrInvalidArgument, "frame is nil")
+	}
+
+	chunkLen := interruptChunkLen(r.frameEncoding)
+
+	for numRead != len(frame) {
+		if atomic.LoadInt32(&r.closing) != 0 {
+			return ErrClosed
+		}
+
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		n := len(frame) - numRead
+		if n > chunkLen {
+			n = chunkLen
+		}
+
+		errCode := C.rocGoReceiverReadFloats(
+			r.cPtr, (*C.float)(&frame[numRead]), (C.ulong)(n))
+		if errCode != 0 {
+			return newNativeErr("roc_receiver_read()", errCode)
+		}
+
+		numRead += n
 	}
 
 	return nil
@@ -463,22 +819,38 @@ func (r *Receiver) ReadFloats(frame []float32) (err error) {
 // The user should ensure that nobody uses the receiver during and after this
 // call. If this function fails, the receiver is kept opened and attached to the
 // context.
+//
+// Blocking reads that are in progress in other goroutines are interrupted and
+// return ErrClosed.
 func (r *Receiver) Close() (err error) {
 	logWrite(LogDebug, "entering Receiver.Close(): receiver=%p", r)
 	defer func() {
 		logWrite(LogDebug, "leaving Receiver.Close(): receiver=%p err=%#v", r, err)
 	}()
 
+	// interrupt in-flight reads, so that they release read lock
+	atomic.StoreInt32(&r.closing, 1)
+
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if r.cPtr != nil {
 		errCode := C.roc_receiver_close(r.cPtr)
 		if errCode != 0 {
+			atomic.StoreInt32(&r.closing, 0)
 			return newNativeErr("roc_receiver_close()", errCode)
 		}
 
 		r.cPtr = nil
+		r.slots.clear()
+
+		if r.release != nil {
+			release := r.release
+			r.release = nil
+			if err := release(); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
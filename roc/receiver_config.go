@@ -60,6 +60,19 @@ type ReceiverConfig struct {
 	// If zero, default profile is used (ResamplerProfileDefault).
 	ResamplerProfile ResamplerProfile
 
+	// PLC backend.
+	//
+	// Packet loss concealment (PLC), is used to reduce distortion caused by lost
+	// packets by filling gaps with interpolated or extrapolated data.
+	//
+	// PLC is used when a packet was lost and FEC was not able to recover it.
+	//
+	// To use a custom PLC implementation, register it using
+	// Context.RegisterPlc() and set this field to the plugin identifier.
+	//
+	// If zero, default backend is used (PlcBackendDefault).
+	PlcBackend PlcBackend
+
 	// Target latency, in nanoseconds.
 	//
 	// How latency is calculated depends on LatencyTunerBackend field.
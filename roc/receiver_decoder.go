@@ -0,0 +1,374 @@
+package roc
+
+/*
+#include <roc/receiver_decoder.h>
+
+int rocGoReceiverDecoderPushPacket(roc_receiver_decoder* decoder, roc_interface iface,
+                                   unsigned char* bytes, unsigned long bytes_size);
+int rocGoReceiverDecoderPopFeedbackPacket(roc_receiver_decoder* decoder, roc_interface iface,
+                                          unsigned char* bytes, unsigned long* bytes_size);
+int rocGoReceiverDecoderPopFloats(roc_receiver_decoder* decoder, float* samples,
+                                  unsigned long samples_size);
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Receiver decoder.
+//
+// Receiver decoder gets network packets from the user, decodes audio stream
+// from them, and provides it back to the user.
+//
+// Receiver decoder is a networkless single-stream version of Receiver. It
+// implements the same pipeline, but instead of receiving packets from network,
+// it gets them from the user. The user is responsible for carrying packets
+// over network. Unlike Receiver, it doesn't support multiple slots and
+// connections. It consumes traffic from a single remote peer.
+//
+// For detailed description of receiver pipeline, see documentation for
+// Receiver.
+//
+// # Context
+//
+// Receiver decoder is automatically attached to a context when opened and
+// detached from it when closed. The user should not close the context until
+// the decoder is closed.
+//
+// Receiver decoder does not use network worker threads of the context. All
+// the work is performed in the threads of the user.
+//
+// # Life cycle
+//
+//   - A receiver decoder is created using OpenReceiverDecoder().
+//   - The user activates one or more interfaces by invoking
+//     ReceiverDecoder.Activate(). This tells decoder what types of streams to
+//     consume and what protocols they use.
+//   - The packet stream is iteratively pushed to the decoder using
+//     ReceiverDecoder.PushPacket(). The user should push packets to the
+//     interfaces on which they were produced by SenderEncoder.
+//   - The audio stream is iteratively popped from the decoder using
+//     ReceiverDecoder.PopFrame(). The decoder returns the stream decoded from
+//     the pushed packets.
+//   - In addition, if a control interface is activated, the stream of encoded
+//     feedback packets is popped from the decoder using
+//     ReceiverDecoder.PopFeedbackPacket(). The user is responsible for
+//     delivering these packets back to SenderEncoder.
+//   - The decoder is destroyed using ReceiverDecoder.Close().
+//
+// # Interfaces and protocols
+//
+// Receiver decoder may have one or several interfaces, as defined in
+// Interface. The interface defines the type of the communication with the
+// remote peer and the set of the protocols supported by it.
+//
+// Each interface has its own incoming queue of packets. To consume packets
+// for an interface, the interface should be first activated with a protocol,
+// and then packets can be pushed to its queue.
+//
+// Supported interface configurations:
+//
+//   - InterfaceAudioSource
+//   - InterfaceAudioSource + InterfaceAudioRepair (when FEC is enabled)
+//   - InterfaceAudioSource + InterfaceAudioControl
+//   - InterfaceAudioSource + InterfaceAudioRepair + InterfaceAudioControl
+//
+// # Thread safety
+//
+// Can be used concurrently.
+type ReceiverDecoder struct {
+	mu            sync.RWMutex
+	cPtr          *C.roc_receiver_decoder
+	active        map[Interface]bool
+	maxPacketSize int
+}
+
+// Open a new receiver decoder.
+//
+// Allocates and initializes a new receiver decoder, and attaches it to the
+// context. Accepts the same configuration as OpenReceiver().
+func OpenReceiverDecoder(
+	context *Context, config ReceiverConfig,
+) (decoder *ReceiverDecoder, err error) {
+	logWrite(LogDebug, "entering OpenReceiverDecoder(): context=%p config=%+v", context, config)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving OpenReceiverDecoder(): context=%p decoder=%p err=%#v", context, decoder, err,
+		)
+	}()
+
+	checkVersionFn()
+
+	if context == nil {
+		return nil, ErrNilContext
+	}
+
+	context.mu.RLock()
+	defer context.mu.RUnlock()
+
+	if context.cPtr == nil {
+		return nil, newErr(ErrClosed, "context is closed")
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	cConfig, err := go2cReceiverConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var cDecoder *C.roc_receiver_decoder
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_receiver_decoder_open(context.cPtr, &cConfig, &cDecoder)
+	})
+	if errCode != 0 {
+		return nil, newNativeErrLog("roc_receiver_decoder_open()", errCode, messages)
+	}
+	if cDecoder == nil {
+		panic("roc_receiver_decoder_open() returned nil")
+	}
+
+	decoder = &ReceiverDecoder{
+		cPtr:          cDecoder,
+		active:        make(map[Interface]bool),
+		maxPacketSize: context.maxPacketSize,
+	}
+
+	return decoder, nil
+}
+
+// Activate decoder interface.
+//
+// Checks that the protocol is valid and supported by the interface, and
+// initializes given interface with given protocol.
+//
+// The user should invoke ReceiverDecoder.PushPacket() for all activated
+// interfaces, passing packets delivered from the remote peer.
+//
+// Each interface can be activated only once.
+func (d *ReceiverDecoder) Activate(iface Interface, proto Protocol) (err error) {
+	logWrite(LogDebug,
+		"entering ReceiverDecoder.Activate(): decoder=%p iface=%+v proto=%+v", d, iface, proto,
+	)
+	defer func() {
+		logWrite(LogDebug, "leaving ReceiverDecoder.Activate(): decoder=%p err=%#v", d, err)
+	}()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cPtr == nil {
+		return newErr(ErrClosed, "decoder is closed")
+	}
+
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_receiver_decoder_activate(
+			d.cPtr,
+			(C.roc_interface)(iface),
+			(C.roc_protocol)(proto))
+	})
+	if errCode != 0 {
+		return newNativeErrLog("roc_receiver_decoder_activate()", errCode, messages)
+	}
+
+	d.active[iface] = true
+
+	return nil
+}
+
+// Query decoder metrics.
+//
+// Returns metrics of the decoder and metrics of the connection to the remote
+// peer. Connection metrics are available after the decoder starts receiving
+// packets.
+func (d *ReceiverDecoder) Query() (
+	decoderMetrics ReceiverMetrics, connMetrics ConnectionMetrics, err error,
+) {
+	logWrite(LogDebug, "entering ReceiverDecoder.Query(): decoder=%p", d)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving ReceiverDecoder.Query(): decoder=%p decoderMetrics=%+v connMetrics=%+v err=%#v",
+			d, decoderMetrics, connMetrics, err,
+		)
+	}()
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.cPtr == nil {
+		return ReceiverMetrics{}, ConnectionMetrics{}, newErr(ErrClosed, "decoder is closed")
+	}
+
+	var cDecoderMetrics C.struct_roc_receiver_metrics
+	var cConnMetrics C.struct_roc_connection_metrics
+
+	errCode := C.roc_receiver_decoder_query(d.cPtr, &cDecoderMetrics, &cConnMetrics)
+	if errCode != 0 {
+		return ReceiverMetrics{}, ConnectionMetrics{},
+			newNativeErr("roc_receiver_decoder_query()", errCode)
+	}
+
+	decoderMetrics = ReceiverMetrics{
+		ConnectionCount: uint32(cDecoderMetrics.connection_count),
+	}
+	connMetrics.fromC(&cConnMetrics)
+
+	return decoderMetrics, connMetrics, nil
+}
+
+// Write packet to decoder.
+//
+// Adds encoded packet to the interface queue. The packet is copied, so the
+// user can reuse the buffer after the call.
+//
+// The user should iteratively push all delivered packets to appropriate
+// interfaces. They will be later decoded and returned by
+// ReceiverDecoder.PopFrame().
+//
+// The interface should be activated before pushing packets to it.
+func (d *ReceiverDecoder) PushPacket(iface Interface, packet []byte) (err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.cPtr == nil {
+		return newErr(ErrClosed, "decoder is closed")
+	}
+
+	if packet == nil {
+		return newErr(ErrInvalidArgument, "packet is nil")
+	}
+
+	if len(packet) == 0 {
+		return newErr(ErrInvalidArgument, "packet is empty")
+	}
+
+	errCode := C.rocGoReceiverDecoderPushPacket(
+		d.cPtr,
+		(C.roc_interface)(iface),
+		(*C.uchar)(&packet[0]),
+		(C.ulong)(len(packet)))
+	if errCode != 0 {
+		return newNativeErr("roc_receiver_decoder_push_packet()", errCode)
+	}
+
+	return nil
+}
+
+// Read feedback packet from decoder.
+//
+// Removes encoded feedback packet from the interface queue and copies it into
+// the provided buffer. Returns number of bytes written to the buffer.
+//
+// The buffer should be large enough to hold a packet, i.e. not less than
+// ContextConfig.MaxPacketSize (or its default, if it was zero). Otherwise,
+// returns ErrInvalidArgument without removing packet from the queue.
+//
+// Feedback packets are produced only for control interface. The user should
+// iteratively pop all available feedback packets after every popped frame, and
+// deliver them back to the remote SenderEncoder. When the interface is
+// activated, but there are no more packets in its queue, returns ErrNoPacket.
+// If the interface is not activated, returns NativeError.
+func (d *ReceiverDecoder) PopFeedbackPacket(iface Interface, packet []byte) (n int, err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.cPtr == nil {
+		return 0, newErr(ErrClosed, "decoder is closed")
+	}
+
+	if packet == nil {
+		return 0, newErr(ErrInvalidArgument, "packet is nil")
+	}
+
+	if len(packet) == 0 {
+		return 0, newErr(ErrInvalidArgument, "packet is empty")
+	}
+
+	if len(packet) < d.maxPacketSize {
+		return 0, newErr(ErrInvalidArgument,
+			fmt.Sprintf("packet size %d is less than max packet size %d",
+				len(packet), d.maxPacketSize))
+	}
+
+	cPacketSize := (C.ulong)(len(packet))
+
+	errCode := C.rocGoReceiverDecoderPopFeedbackPacket(
+		d.cPtr,
+		(C.roc_interface)(iface),
+		(*C.uchar)(&packet[0]),
+		&cPacketSize)
+	if errCode != 0 {
+		if d.active[iface] {
+			return 0, newErr(ErrNoPacket, "no more packets in interface queue")
+		}
+		return 0, newNativeErr("roc_receiver_decoder_pop_feedback_packet()", errCode)
+	}
+
+	return int(cPacketSize), nil
+}
+
+// Read samples from decoder.
+//
+// Reads pushed network packets, decodes packets, repairs losses, extracts
+// samples, adjusts sample rate and channel layout, compensates clock drift,
+// and finally stores samples into the provided frame.
+//
+// If ClockSourceInternal is used, the function blocks until it's time to
+// decode the samples according to the configured sample rate.
+//
+// Until enough packets are pushed, the decoder produces silence.
+func (d *ReceiverDecoder) PopFrame(frame []float32) (err error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	if d.cPtr == nil {
+		return newErr(ErrClosed, "decoder is closed")
+	}
+
+	if frame == nil {
+		return newErr(ErrInvalidArgument, "frame is nil")
+	}
+
+	if len(frame) == 0 {
+		return nil
+	}
+
+	errCode := C.rocGoReceiverDecoderPopFloats(
+		d.cPtr, (*C.float)(&frame[0]), (C.ulong)(len(frame)))
+	if errCode != 0 {
+		return newNativeErr("roc_receiver_decoder_pop_frame()", errCode)
+	}
+
+	return nil
+}
+
+// Close the receiver decoder.
+//
+// Deinitializes and deallocates the decoder, and detaches it from the
+// context. The user should ensure that nobody uses the decoder during and
+// after this call. If this function fails, the decoder is kept opened and
+// attached to the context.
+func (d *ReceiverDecoder) Close() (err error) {
+	logWrite(LogDebug, "entering ReceiverDecoder.Close(): decoder=%p", d)
+	defer func() {
+		logWrite(LogDebug, "leaving ReceiverDecoder.Close(): decoder=%p err=%#v", d, err)
+	}()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.cPtr != nil {
+		errCode := C.roc_receiver_decoder_close(d.cPtr)
+		if errCode != 0 {
+			return newNativeErr("roc_receiver_decoder_close()", errCode)
+		}
+
+		d.cPtr = nil
+	}
+
+	return nil
+}
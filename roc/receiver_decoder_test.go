@@ -0,0 +1,347 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReceiverDecoder_Open(t *testing.T) {
+	tests := []struct {
+		name        string
+		contextFunc func() *Context
+		configFunc  func() ReceiverConfig
+		wantErr     error
+	}{
+		{
+			name: "ok",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: makeReceiverConfig,
+			wantErr:    nil,
+		},
+		{
+			name: "nil context",
+			contextFunc: func() *Context {
+				return nil
+			},
+			configFunc: makeReceiverConfig,
+			wantErr:    ErrNilContext,
+		},
+		{
+			name: "closed context",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+
+				err = ctx.Close()
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: makeReceiverConfig,
+			wantErr:    newErr(ErrClosed, "context is closed"),
+		},
+		{
+			name: "invalid config.FrameEncoding.Rate",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.FrameEncoding.Rate = 0
+				return rc
+			},
+			wantErr: ConfigError{{Field: "FrameEncoding.Rate", Reason: "should be non-zero"}},
+		},
+		{
+			name: "invalid config.TargetLatency",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: func() ReceiverConfig {
+				rc := makeReceiverConfig()
+				rc.TargetLatency = -1
+				return rc
+			},
+			wantErr: ConfigError{{Field: "TargetLatency",
+				Reason: "should be non-negative, got -1ns"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.contextFunc()
+
+			decoder, err := OpenReceiverDecoder(ctx, tt.configFunc())
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				require.NotNil(t, decoder)
+
+				err = decoder.Close()
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tt.wantErr, stripNativeLog(err))
+				require.Nil(t, decoder)
+			}
+
+			if ctx != nil {
+				err = ctx.Close()
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestReceiverDecoder_Activate(t *testing.T) {
+	cases := []struct {
+		name    string
+		iface   Interface
+		proto   Protocol
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			iface:   InterfaceAudioSource,
+			proto:   ProtoRtpRs8mSource,
+			wantErr: nil,
+		},
+		{
+			name:    "bad iface",
+			iface:   -1,
+			proto:   ProtoRtpRs8mSource,
+			wantErr: newNativeErr("roc_receiver_decoder_activate()", -1),
+		},
+		{
+			name:    "bad protocol",
+			iface:   InterfaceAudioRepair,
+			proto:   ProtoRtpRs8mSource,
+			wantErr: newNativeErr("roc_receiver_decoder_activate()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			decoder, err := OpenReceiverDecoder(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, decoder)
+
+			err = decoder.Activate(tt.iface, tt.proto)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = decoder.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiverDecoder_PushPacket(t *testing.T) {
+	cases := []struct {
+		name    string
+		iface   Interface
+		packet  []byte
+		wantErr error
+	}{
+		{
+			name:    "nil packet",
+			iface:   InterfaceAudioSource,
+			packet:  nil,
+			wantErr: newErr(ErrInvalidArgument, "packet is nil"),
+		},
+		{
+			name:    "empty packet",
+			iface:   InterfaceAudioSource,
+			packet:  []byte{},
+			wantErr: newErr(ErrInvalidArgument, "packet is empty"),
+		},
+		{
+			name:    "not activated iface",
+			iface:   InterfaceAudioRepair,
+			packet:  make([]byte, 100),
+			wantErr: newNativeErr("roc_receiver_decoder_push_packet()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			decoder, err := OpenReceiverDecoder(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, decoder)
+
+			err = decoder.Activate(InterfaceAudioSource, ProtoRtp)
+			require.NoError(t, err)
+
+			err = decoder.PushPacket(tt.iface, tt.packet)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = decoder.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiverDecoder_PopFrame(t *testing.T) {
+	cases := []struct {
+		name    string
+		frame   []float32
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			frame:   make([]float32, 100),
+			wantErr: nil,
+		},
+		{
+			name:    "nil frame",
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+		{
+			name:    "empty frame",
+			frame:   []float32{},
+			wantErr: nil,
+		},
+		{
+			name:    "bad frame",
+			frame:   []float32{1.0},
+			wantErr: newNativeErr("roc_receiver_decoder_pop_frame()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			decoder, err := OpenReceiverDecoder(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, decoder)
+
+			err = decoder.PopFrame(tt.frame)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = decoder.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiverDecoder_PopFeedbackPacket(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	decoder, err := OpenReceiverDecoder(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	require.NotNil(t, decoder)
+
+	err = decoder.Activate(InterfaceAudioSource, ProtoRtp)
+	require.NoError(t, err)
+
+	err = decoder.Activate(InterfaceAudioControl, ProtoRtcp)
+	require.NoError(t, err)
+
+	packet := make([]byte, makeContextConfig().MaxPacketSize)
+
+	// no packets yet
+	_, err = decoder.PopFeedbackPacket(InterfaceAudioControl, packet)
+	require.True(t, errors.Is(err, ErrNoPacket))
+
+	// bad arguments
+	_, err = decoder.PopFeedbackPacket(InterfaceAudioControl, nil)
+	require.Equal(t, newErr(ErrInvalidArgument, "packet is nil"), err)
+
+	_, err = decoder.PopFeedbackPacket(InterfaceAudioControl, []byte{})
+	require.Equal(t, newErr(ErrInvalidArgument, "packet is empty"), err)
+
+	_, err = decoder.PopFeedbackPacket(InterfaceAudioControl, packet[:len(packet)-1])
+	require.True(t, errors.Is(err, ErrInvalidArgument))
+
+	// repair interface is not activated
+	_, err = decoder.PopFeedbackPacket(InterfaceAudioRepair, packet)
+	require.Equal(t, newNativeErr("roc_receiver_decoder_pop_feedback_packet()", -1), err)
+
+	err = decoder.Close()
+	require.NoError(t, err)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestReceiverDecoder_Close(t *testing.T) {
+	cases := []struct {
+		name      string
+		operation func(decoder *ReceiverDecoder) error
+	}{
+		{
+			name: "Activate after close",
+			operation: func(decoder *ReceiverDecoder) error {
+				return decoder.Activate(InterfaceAudioSource, ProtoRtp)
+			},
+		},
+		{
+			name: "Query after close",
+			operation: func(decoder *ReceiverDecoder) error {
+				_, _, err := decoder.Query()
+				return err
+			},
+		},
+		{
+			name: "PushPacket after close",
+			operation: func(decoder *ReceiverDecoder) error {
+				return decoder.PushPacket(InterfaceAudioSource, make([]byte, 10))
+			},
+		},
+		{
+			name: "PopFeedbackPacket after close",
+			operation: func(decoder *ReceiverDecoder) error {
+				_, err := decoder.PopFeedbackPacket(InterfaceAudioControl, make([]byte, 10))
+				return err
+			},
+		},
+		{
+			name: "PopFrame after close",
+			operation: func(decoder *ReceiverDecoder) error {
+				return decoder.PopFrame(make([]float32, 2))
+			},
+		},
+	}
+	for _, tt := range cases {
+		ctx, err := OpenContext(makeContextConfig())
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+
+		decoder, err := OpenReceiverDecoder(ctx, makeReceiverConfig())
+		require.NoError(t, err)
+		require.NotNil(t, decoder)
+
+		err = decoder.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, newErr(ErrClosed, "decoder is closed"), tt.operation(decoder))
+
+		err = ctx.Close()
+		require.NoError(t, err)
+	}
+}
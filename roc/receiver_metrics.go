@@ -0,0 +1,14 @@
+package roc
+
+// Receiver metrics.
+//
+// Holds receiver-side metrics that are not specific to connection. Metrics of
+// individual connections are reported using ConnectionMetrics.
+//
+// See Receiver.Query().
+type ReceiverMetrics struct {
+	// Number of active connections.
+	//
+	// Defines how much senders are currently connected to the receiver slot.
+	ConnectionCount uint32
+}
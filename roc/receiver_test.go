@@ -1,10 +1,12 @@
 package roc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +34,7 @@ func TestReceiver_Open(t *testing.T) {
 				return nil
 			},
 			configFunc: makeReceiverConfig,
-			wantErr:    errors.New("context is nil"),
+			wantErr:    ErrNilContext,
 		},
 		{
 			name: "closed context",
@@ -45,7 +47,7 @@ func TestReceiver_Open(t *testing.T) {
 				return ctx
 			},
 			configFunc: makeReceiverConfig,
-			wantErr:    errors.New("context is closed"),
+			wantErr:    newErr(ErrClosed, "context is closed"),
 		},
 		{
 			name: "invalid config.FrameEncoding.Rate",
@@ -59,7 +61,7 @@ func TestReceiver_Open(t *testing.T) {
 				rc.FrameEncoding.Rate = 0
 				return rc
 			},
-			wantErr: newNativeErr("roc_receiver_open()", -1),
+			wantErr: ConfigError{{Field: "FrameEncoding.Rate", Reason: "should be non-zero"}},
 		},
 		{
 			name: "invalid config.FrameEncoding.Channels",
@@ -73,7 +75,8 @@ func TestReceiver_Open(t *testing.T) {
 				rc.FrameEncoding.Channels = 0
 				return rc
 			},
-			wantErr: newNativeErr("roc_receiver_open()", -1),
+			wantErr: ConfigError{{Field: "FrameEncoding.Channels",
+				Reason: "unsupported value ChannelLayout(0)"}},
 		},
 		{
 			name: "invalid config.FrameEncoding.Format",
@@ -87,7 +90,8 @@ func TestReceiver_Open(t *testing.T) {
 				rc.FrameEncoding.Format = 0
 				return rc
 			},
-			wantErr: newNativeErr("roc_receiver_open()", -1),
+			wantErr: ConfigError{{Field: "FrameEncoding.Format",
+				Reason: "unsupported value Format(0)"}},
 		},
 		{
 			name: "invalid config.TargetLatency",
@@ -101,8 +105,8 @@ func TestReceiver_Open(t *testing.T) {
 				rc.TargetLatency = -1
 				return rc
 			},
-			wantErr: fmt.Errorf("invalid config.TargetLatency: %w",
-				fmt.Errorf("unexpected negative duration: -1ns")),
+			wantErr: ConfigError{{Field: "TargetLatency",
+				Reason: "should be non-negative, got -1ns"}},
 		},
 		{
 			name: "invalid config.LatencyTolerance",
@@ -116,8 +120,8 @@ func TestReceiver_Open(t *testing.T) {
 				rc.LatencyTolerance = -1
 				return rc
 			},
-			wantErr: fmt.Errorf("invalid config.LatencyTolerance: %w",
-				fmt.Errorf("unexpected negative duration: -1ns")),
+			wantErr: ConfigError{{Field: "LatencyTolerance",
+				Reason: "should be non-negative, got -1ns"}},
 		},
 	}
 
@@ -134,7 +138,7 @@ func TestReceiver_Open(t *testing.T) {
 				err = receiver.Close()
 				require.NoError(t, err)
 			} else {
-				require.Equal(t, tt.wantErr, err)
+				require.Equal(t, tt.wantErr, stripNativeLog(err))
 				require.Nil(t, receiver)
 			}
 
@@ -177,7 +181,7 @@ func TestReceiver_Configure(t *testing.T) {
 				ic.OutgoingAddress = strings.Repeat("x", 500)
 				return ic
 			},
-			wantErr: errors.New("invalid config.OutgoingAddress: too long"),
+			wantErr: newErr(ErrInvalidConfig, "invalid config.OutgoingAddress: too long"),
 		},
 		{
 			name:  "invalid OutgoingAddress",
@@ -188,8 +192,9 @@ func TestReceiver_Configure(t *testing.T) {
 				ic.OutgoingAddress = "127.0.0.1\x00"
 				return ic
 			},
-			wantErr: fmt.Errorf("invalid config.OutgoingAddress: %w",
-				fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\"")),
+			wantErr: wrapErr(ErrInvalidConfig,
+				fmt.Errorf("invalid config.OutgoingAddress: %w",
+					fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\""))),
 		},
 		{
 			name:  "out of range OutgoingAddress",
@@ -211,7 +216,7 @@ func TestReceiver_Configure(t *testing.T) {
 				ic.MulticastGroup = strings.Repeat("x", 500)
 				return ic
 			},
-			wantErr: errors.New("invalid config.MulticastGroup: too long"),
+			wantErr: newErr(ErrInvalidConfig, "invalid config.MulticastGroup: too long"),
 		},
 		{
 			name:  "invalid MulticastGroup",
@@ -222,8 +227,9 @@ func TestReceiver_Configure(t *testing.T) {
 				ic.MulticastGroup = "127.0.0.1\x00"
 				return ic
 			},
-			wantErr: fmt.Errorf("invalid config.MulticastGroup: %w",
-				fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\"")),
+			wantErr: wrapErr(ErrInvalidConfig,
+				fmt.Errorf("invalid config.MulticastGroup: %w",
+					fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\""))),
 		},
 		{
 			name:  "out of range MulticastGroup",
@@ -248,7 +254,7 @@ func TestReceiver_Configure(t *testing.T) {
 			require.NotNil(t, receiver)
 
 			err = receiver.Configure(tt.slot, tt.iface, tt.configFunc())
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
 
 			err = receiver.Close()
 			require.NoError(t, err)
@@ -282,21 +288,23 @@ func TestReceiver_Bind(t *testing.T) {
 			name:    "nil endpoint",
 			slot:    SlotDefault,
 			iface:   InterfaceAudioSource,
-			wantErr: errors.New("endpoint is nil"),
+			wantErr: newErr(ErrBadEndpoint, "endpoint is nil"),
 		},
 		{
 			name:     "bad endpoint",
 			slot:     SlotDefault,
 			iface:    InterfaceAudioSource,
 			endpoint: &Endpoint{Host: "127.0.0.1", Port: 0, Protocol: ProtoRs8mRepair},
-			wantErr:  newNativeErr("roc_receiver_bind()", -1),
+			wantErr: newErr(ErrBadEndpoint,
+				"protocol Rs8mRepair can't be used with interface AudioSource,"+
+					" expected interface AudioRepair"),
 		},
 		{
 			name:     "bad protocol",
 			slot:     SlotDefault,
 			iface:    InterfaceAudioSource,
 			endpoint: &Endpoint{Host: "127.0.0.1", Port: 0, Protocol: 1},
-			wantErr:  newNativeErr("roc_endpoint_set_protocol()", -1),
+			wantErr:  wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_protocol()", -1)),
 		},
 		{
 			name:     "bad iface",
@@ -317,7 +325,62 @@ func TestReceiver_Bind(t *testing.T) {
 			require.NotNil(t, receiver)
 
 			err = receiver.Bind(tt.slot, tt.iface, tt.endpoint)
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = receiver.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiver_BindSet(t *testing.T) {
+	cases := []struct {
+		name        string
+		fecEncoding FecEncoding
+		wantRepair  bool
+	}{
+		{
+			name:        "rs8m",
+			fecEncoding: FecEncodingRs8m,
+			wantRepair:  true,
+		},
+		{
+			name:        "ldpc",
+			fecEncoding: FecEncodingLdpcStaircase,
+			wantRepair:  true,
+		},
+		{
+			name:        "no fec",
+			fecEncoding: FecEncodingDisable,
+			wantRepair:  false,
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, receiver)
+
+			set, err := NewEndpointSet("127.0.0.1", 0, tt.fecEncoding)
+			require.NoError(t, err)
+
+			err = receiver.BindSet(SlotDefault, set)
+			require.NoError(t, err)
+
+			require.NotEqual(t, 0, set.Source.Port)
+			require.NotEqual(t, 0, set.Control.Port)
+			if tt.wantRepair {
+				require.NotEqual(t, 0, set.Repair.Port)
+			} else {
+				require.Nil(t, set.Repair)
+			}
 
 			err = receiver.Close()
 			require.NoError(t, err)
@@ -326,6 +389,38 @@ func TestReceiver_Bind(t *testing.T) {
 			require.NoError(t, err)
 		})
 	}
+
+	t.Run("rollback", func(t *testing.T) {
+		ctx, err := OpenContext(makeContextConfig())
+		require.NoError(t, err)
+
+		receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+		require.NoError(t, err)
+		require.NotNil(t, receiver)
+
+		set, err := NewEndpointSet("127.0.0.1", 0, FecEncodingRs8m)
+		require.NoError(t, err)
+
+		badSet := *set
+		badSet.Control = &Endpoint{Host: "127.0.0.1", Port: 0, Protocol: ProtoRtp}
+
+		err = receiver.BindSet(SlotDefault, &badSet)
+		require.Error(t, err)
+		require.True(t, errors.Is(err, ErrBadEndpoint))
+
+		// slot is rolled back and can be reused
+		set, err = NewEndpointSet("127.0.0.1", 0, FecEncodingRs8m)
+		require.NoError(t, err)
+
+		err = receiver.BindSet(SlotDefault, set)
+		require.NoError(t, err)
+
+		err = receiver.Close()
+		require.NoError(t, err)
+
+		err = ctx.Close()
+		require.NoError(t, err)
+	})
 }
 
 func TestReceiver_Unlink(t *testing.T) {
@@ -375,7 +470,118 @@ func TestReceiver_Unlink(t *testing.T) {
 			require.NoError(t, err)
 
 			err = receiver.Unlink(tt.slot)
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = receiver.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiver_Slots(t *testing.T) {
+	baseEndpoint, err := ParseEndpoint("rtp+rs8m://127.0.0.1:0")
+	require.NoError(t, err)
+	require.NotNil(t, baseEndpoint)
+
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	require.NotNil(t, receiver)
+
+	require.Empty(t, receiver.Slots())
+
+	slot, err := receiver.NewSlot("foo")
+	require.NoError(t, err)
+	require.Equal(t, SlotDefault, slot)
+
+	_, err = receiver.NewSlot("foo")
+	require.True(t, errors.Is(err, ErrExists))
+
+	err = receiver.Bind(slot, InterfaceAudioSource, baseEndpoint)
+	require.NoError(t, err)
+
+	slots := receiver.Slots()
+	require.Len(t, slots, 1)
+	require.Equal(t, slot, slots[0].Slot)
+	require.Equal(t, "foo", slots[0].Name)
+	require.False(t, slots[0].Broken)
+	require.Len(t, slots[0].Interfaces, 1)
+
+	iface := slots[0].Interfaces[0]
+	require.Equal(t, InterfaceAudioSource, iface.Interface)
+	require.Nil(t, iface.Config)
+	require.NotNil(t, iface.Endpoint)
+	require.Equal(t, ProtoRtpRs8mSource, iface.Endpoint.Protocol)
+
+	// actual bound port is reported
+	require.NotEqual(t, 0, iface.Endpoint.Port)
+	require.Equal(t, baseEndpoint.Port, iface.Endpoint.Port)
+
+	err = receiver.Unlink(slot)
+	require.NoError(t, err)
+
+	require.Empty(t, receiver.Slots())
+
+	err = receiver.Close()
+	require.NoError(t, err)
+
+	_, err = receiver.NewSlot("bar")
+	require.Equal(t, newErr(ErrClosed, "receiver is closed"), err)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestReceiver_Query(t *testing.T) {
+	baseEndpoint, err := ParseEndpoint("rtp+rs8m://127.0.0.1:0")
+	require.NoError(t, err)
+	require.NotNil(t, baseEndpoint)
+
+	cases := []struct {
+		name    string
+		slot    Slot
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			slot:    SlotDefault,
+			wantErr: nil,
+		},
+		{
+			name:    "bad slot",
+			slot:    1,
+			wantErr: newNativeErr("roc_receiver_query()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, receiver)
+
+			endpoint := *baseEndpoint
+			err = receiver.Bind(SlotDefault, InterfaceAudioSource, &endpoint)
+			require.NoError(t, err)
+
+			slotMetrics, connMetrics, err := receiver.Query(tt.slot)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			if tt.wantErr == nil {
+				require.Equal(t, ReceiverMetrics{ConnectionCount: 0}, slotMetrics)
+				require.Empty(t, connMetrics)
+			} else {
+				require.Equal(t, ReceiverMetrics{}, slotMetrics)
+				require.Nil(t, connMetrics)
+			}
 
 			err = receiver.Close()
 			require.NoError(t, err)
@@ -405,7 +611,7 @@ func TestReceiver_ReadFloats(t *testing.T) {
 		},
 		{
 			name:    "nil frame",
-			wantErr: errors.New("frame is nil"),
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
 		},
 		{
 			name:    "empty frame",
@@ -429,7 +635,7 @@ func TestReceiver_ReadFloats(t *testing.T) {
 			require.NotNil(t, receiver)
 
 			err = receiver.ReadFloats(tt.frame)
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
 
 			err = receiver.Close()
 			require.NoError(t, err)
@@ -440,6 +646,247 @@ func TestReceiver_ReadFloats(t *testing.T) {
 	}
 }
 
+func TestReceiver_ReadInt(t *testing.T) {
+	cases := []struct {
+		name    string
+		read    func(receiver *Receiver) error
+		wantErr error
+	}{
+		{
+			name: "int16 ok",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt16(make([]int16, 2))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int16 nil frame",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt16(nil)
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+		{
+			name: "int16 bad frame",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt16(make([]int16, 1))
+			},
+			wantErr: newNativeErr("roc_receiver_read()", -1),
+		},
+		{
+			name: "int16 dither",
+			read: func(receiver *Receiver) error {
+				receiver.SetDither(true)
+				return receiver.ReadInt16(make([]int16, 2))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int24 ok",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt24Packed(make([]byte, 6))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int24 nil frame",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt24Packed(nil)
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+		{
+			name: "int24 partial sample",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt24Packed(make([]byte, 5))
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame size is not a multiple of 3"),
+		},
+		{
+			name: "int32 ok",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt32(make([]int32, 2))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int32 nil frame",
+			read: func(receiver *Receiver) error {
+				return receiver.ReadInt32(nil)
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, receiver)
+
+			err = tt.read(receiver)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = receiver.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiver_ReadFloatsContext(t *testing.T) {
+	cases := []struct {
+		name     string
+		ctxFunc  func() (context.Context, context.CancelFunc)
+		frameLen int
+		wantErr  error
+	}{
+		{
+			name: "ok",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			frameLen: 100,
+			wantErr:  nil,
+		},
+		{
+			name: "nil ctx",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return nil, func() {}
+			},
+			frameLen: 100,
+			wantErr:  newErr(ErrInvalidArgument, "ctx is nil"),
+		},
+		{
+			name: "cancelled",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			frameLen: 100,
+			wantErr:  context.Canceled,
+		},
+		{
+			name: "deadline exceeded",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			// 10 seconds
+			frameLen: 44100 * NumChannels * 10,
+			wantErr:  context.DeadlineExceeded,
+		},
+		{
+			name: "bad frame",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			frameLen: 1,
+			wantErr:  newNativeErr("roc_receiver_read()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+			require.NoError(t, err)
+			require.NotNil(t, receiver)
+
+			callCtx, cancel := tt.ctxFunc()
+			defer cancel()
+
+			startTime := time.Now()
+
+			err = receiver.ReadFloatsContext(callCtx, make([]float32, tt.frameLen))
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			require.Less(t, int64(time.Since(startTime)), int64(5*time.Second))
+
+			err = receiver.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestReceiver_ReadFloatsContextPartial(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	require.NotNil(t, receiver)
+
+	callCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// 10 seconds
+	frame := make([]float32, 44100*NumChannels*10)
+	for n := range frame {
+		frame[n] = 1
+	}
+
+	err = receiver.ReadFloatsContext(callCtx, frame)
+	require.Equal(t, context.DeadlineExceeded, err)
+
+	// leading part of the frame was read and counted
+	stats := receiver.Stats()
+	require.Equal(t, uint64(0), stats.FramesRead)
+	require.Equal(t, uint64(1), stats.ReadErrors)
+	require.NotZero(t, stats.SamplesRead)
+	require.Less(t, stats.SamplesRead, uint64(len(frame)))
+
+	// the rest of the frame is zeroed
+	require.Equal(t, make([]float32, len(frame)-int(stats.SamplesRead)),
+		frame[stats.SamplesRead:])
+
+	err = receiver.Close()
+	require.NoError(t, err)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestReceiver_CloseInterrupt(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+	require.NotNil(t, receiver)
+
+	errCh := make(chan error, 1)
+	go func() {
+		// 10 seconds
+		errCh <- receiver.ReadFloats(make([]float32, 44100*NumChannels*10))
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	err = receiver.Close()
+	require.NoError(t, err)
+
+	select {
+	case err = <-errCh:
+		require.Equal(t, ErrClosed, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("ReadFloats() was not interrupted by Close()")
+	}
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
 func TestReceiver_Close(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -463,6 +910,13 @@ func TestReceiver_Close(t *testing.T) {
 				return receiver.Unlink(SlotDefault)
 			},
 		},
+		{
+			name: "Query after close",
+			operation: func(receiver *Receiver) error {
+				_, _, err := receiver.Query(SlotDefault)
+				return err
+			},
+		},
 		{
 			name: "ReadFloats after close",
 			operation: func(receiver *Receiver) error {
@@ -470,6 +924,30 @@ func TestReceiver_Close(t *testing.T) {
 				return receiver.ReadFloats(recFloats)
 			},
 		},
+		{
+			name: "ReadFloatsContext after close",
+			operation: func(receiver *Receiver) error {
+				return receiver.ReadFloatsContext(context.Background(), make([]float32, 2))
+			},
+		},
+		{
+			name: "ReadInt16 after close",
+			operation: func(receiver *Receiver) error {
+				return receiver.ReadInt16(make([]int16, 2))
+			},
+		},
+		{
+			name: "ReadInt24Packed after close",
+			operation: func(receiver *Receiver) error {
+				return receiver.ReadInt24Packed(make([]byte, 6))
+			},
+		},
+		{
+			name: "ReadInt32 after close",
+			operation: func(receiver *Receiver) error {
+				return receiver.ReadInt32(make([]int32, 2))
+			},
+		},
 	}
 	for _, tt := range cases {
 		ctx, err := OpenContext(makeContextConfig())
@@ -483,7 +961,7 @@ func TestReceiver_Close(t *testing.T) {
 		err = receiver.Close()
 		require.NoError(t, err)
 
-		require.Equal(t, errors.New("receiver is closed"), tt.operation(receiver))
+		require.Equal(t, newErr(ErrClosed, "receiver is closed"), tt.operation(receiver))
 
 		err = ctx.Close()
 		require.NoError(t, err)
@@ -0,0 +1,147 @@
+package roc
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+)
+
+// Size of packed 24-bit sample in bytes.
+const int24PackedSize = 3
+
+// Source of TPDF (triangular probability density function) dither noise.
+//
+// Dither is added to samples before quantizing them to lower precision. It
+// decorrelates quantization error from the signal, turning distortion into
+// a constant low-level noise floor.
+//
+// Uses xorshift64* generator, seeded from math/rand, so that it's cheap
+// enough to be invoked for every sample. Not thread-safe; each call of
+// a read function uses its own instance.
+type ditherer struct {
+	state uint64
+}
+
+func newDitherer() ditherer {
+	return ditherer{state: rand.Uint64() | 1}
+}
+
+// returns uniformly distributed value in range [0; 1)
+func (d *ditherer) uniform() float64 {
+	d.state ^= d.state >> 12
+	d.state ^= d.state << 25
+	d.state ^= d.state >> 27
+	return float64((d.state*2685821657736338717)>>11) / (1 << 53)
+}
+
+// returns triangularly distributed value in range (-1; 1), in LSBs
+func (d *ditherer) tpdf() float64 {
+	return d.uniform() - d.uniform()
+}
+
+// returns noise to be added before quantization, in LSBs
+// returns zero if dithering is disabled (d is nil)
+func (d *ditherer) noise() float64 {
+	if d == nil {
+		return 0
+	}
+	return d.tpdf()
+}
+
+func int24PackedCheck(frame []byte) error {
+	if len(frame)%int24PackedSize != 0 {
+		return newErr(ErrInvalidArgument, "frame size is not a multiple of 3")
+	}
+	return nil
+}
+
+// Buffer for converting integer samples to floats and back.
+//
+// Sender and Receiver reuse it between calls, instead of allocating a new
+// buffer for every frame, like PcmWriter and PcmReader do. Calls using the
+// buffer are serialized by its mutex.
+type sampleScratch struct {
+	mu     sync.Mutex
+	floats []float32
+}
+
+// returns buffer of n floats, growing it if needed
+// should be called with s.mu locked
+func (s *sampleScratch) get(n int) []float32 {
+	if cap(s.floats) < n {
+		s.floats = make([]float32, n)
+	}
+	return s.floats[:n]
+}
+
+func int16ToFloats(src []int16, scratch *sampleScratch) []float32 {
+	if src == nil {
+		return nil
+	}
+	dst := scratch.get(len(src))
+	for i, v := range src {
+		dst[i] = int2float(int32(v), 16)
+	}
+	return dst
+}
+
+func int32ToFloats(src []int32, scratch *sampleScratch) []float32 {
+	if src == nil {
+		return nil
+	}
+	dst := scratch.get(len(src))
+	for i, v := range src {
+		dst[i] = int2float(v, 32)
+	}
+	return dst
+}
+
+func int24PackedToFloats(src []byte, scratch *sampleScratch) []float32 {
+	if src == nil {
+		return nil
+	}
+	dst := scratch.get(len(src) / int24PackedSize)
+	pcmDecode(PcmFormatS24LE, src, dst)
+	return dst
+}
+
+func floatsToInt16(src []float32, dst []int16, d *ditherer) {
+	for i, s := range src {
+		dst[i] = int16(float2intNoise(s, 16, d.noise()))
+	}
+}
+
+func floatsToInt32(src []float32, dst []int32) {
+	for i, s := range src {
+		dst[i] = float2int(s, 32)
+	}
+}
+
+func floatsToInt24Packed(src []float32, dst []byte, d *ditherer) {
+	for i, s := range src {
+		v := uint32(float2intNoise(s, 24, d.noise()))
+		dst[i*3] = byte(v)
+		dst[i*3+1] = byte(v >> 8)
+		dst[i*3+2] = byte(v >> 16)
+	}
+}
+
+// converts float sample to signed integer with given number of bits,
+// adding given noise (in LSBs) before rounding
+// input is clipped to range [-1; 1)
+func float2intNoise(s float32, bits uint, noise float64) int32 {
+	scale := float64(uint64(1) << (bits - 1))
+
+	v := math.Round(float64(s)*scale + noise)
+	if v >= scale {
+		v = scale - 1
+	}
+	if v < -scale {
+		v = -scale
+	}
+	if math.IsNaN(v) {
+		v = 0
+	}
+
+	return int32(v)
+}
@@ -0,0 +1,113 @@
+package roc
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSamples_IntToFloats(t *testing.T) {
+	scratch := &sampleScratch{}
+
+	assert.Nil(t, int16ToFloats(nil, scratch))
+	assert.Nil(t, int32ToFloats(nil, scratch))
+	assert.Nil(t, int24PackedToFloats(nil, scratch))
+
+	assert.Equal(t,
+		[]float32{0, 0.5, -0.5, -1},
+		int16ToFloats([]int16{0, 1 << 14, -1 << 14, math.MinInt16}, scratch))
+
+	assert.Equal(t,
+		[]float32{0, 0.5, -0.5, -1},
+		int32ToFloats([]int32{0, 1 << 30, -1 << 30, math.MinInt32}, scratch))
+
+	assert.Equal(t,
+		[]float32{0, 0.5, -0.5, -1},
+		int24PackedToFloats([]byte{
+			0x00, 0x00, 0x00,
+			0x00, 0x00, 0x40,
+			0x00, 0x00, 0xc0,
+			0x00, 0x00, 0x80,
+		}, scratch))
+
+	// buffer is reused
+	floats := int16ToFloats(make([]int16, 4), scratch)
+	assert.Equal(t, &scratch.floats[0], &floats[0])
+}
+
+func TestSamples_FloatsToInt(t *testing.T) {
+	src := []float32{0, 0.5, -0.5, -1, 1, 2, -2}
+
+	dst16 := make([]int16, len(src))
+	floatsToInt16(src, dst16, nil)
+	assert.Equal(t,
+		[]int16{0, 1 << 14, -1 << 14,
+			math.MinInt16, math.MaxInt16, math.MaxInt16, math.MinInt16},
+		dst16)
+
+	dst32 := make([]int32, len(src))
+	floatsToInt32(src, dst32)
+	assert.Equal(t,
+		[]int32{0, 1 << 30, -1 << 30,
+			math.MinInt32, math.MaxInt32, math.MaxInt32, math.MinInt32},
+		dst32)
+
+	dst24 := make([]byte, len(src)*3)
+	floatsToInt24Packed(src, dst24, nil)
+	assert.Equal(t,
+		[]byte{
+			0x00, 0x00, 0x00,
+			0x00, 0x00, 0x40,
+			0x00, 0x00, 0xc0,
+			0x00, 0x00, 0x80,
+			0xff, 0xff, 0x7f,
+			0xff, 0xff, 0x7f,
+			0x00, 0x00, 0x80,
+		},
+		dst24)
+}
+
+func TestSamples_Dither(t *testing.T) {
+	const numSamples = 100000
+
+	d := newDitherer()
+
+	// noise is in range (-1; 1) with zero mean and variance 1/6
+	var sum, sumSq float64
+	for i := 0; i < numSamples; i++ {
+		n := d.noise()
+		require.True(t, n > -1 && n < 1, "noise=%v", n)
+		sum += n
+		sumSq += n * n
+	}
+	assert.InDelta(t, 0, sum/numSamples, 0.01)
+	assert.InDelta(t, 1.0/6, sumSq/numSamples, 0.01)
+
+	// nil ditherer produces no noise
+	var nilD *ditherer
+	assert.Equal(t, 0.0, nilD.noise())
+
+	// dithered signal below 1 LSB is not truncated to zero,
+	// and its mean is preserved
+	const lsb = 1.0 / (1 << 15)
+	src := make([]float32, numSamples)
+	for i := range src {
+		src[i] = lsb / 4
+	}
+
+	plain := make([]int16, numSamples)
+	floatsToInt16(src, plain, nil)
+	assert.Equal(t, make([]int16, numSamples), plain)
+
+	dithered := make([]int16, numSamples)
+	floatsToInt16(src, dithered, &d)
+
+	var ditheredSum float64
+	for _, v := range dithered {
+		require.True(t, v >= -1 && v <= 2, "v=%v", v)
+		ditheredSum += float64(v)
+	}
+	assert.InDelta(t, 0.25, ditheredSum/numSamples, 0.02)
+}
@@ -10,9 +10,10 @@ int rocGoSenderWriteFloats(roc_sender* sender, float* samples, unsigned long sam
 import "C"
 
 import (
-	"errors"
+	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 )
 
 // Sender peer.
@@ -148,8 +149,15 @@ import (
 //
 // Can be used concurrently.
 type Sender struct {
-	mu   sync.RWMutex
-	cPtr *C.roc_sender
+	counters      ioCounters
+	mu            sync.RWMutex
+	cPtr          *C.roc_sender
+	frameEncoding MediaEncoding
+	fecEncoding   FecEncoding
+	closing       int32
+	release       func() error
+	slots         slotRegistry
+	scratch       sampleScratch
 }
 
 // Open a new sender.
@@ -166,32 +174,68 @@ func OpenSender(context *Context, config SenderConfig) (sender *Sender, err erro
 	checkVersionFn()
 
 	if context == nil {
-		return nil, errors.New("context is nil")
+		return nil, ErrNilContext
 	}
 
 	context.mu.RLock()
 	defer context.mu.RUnlock()
 
 	if context.cPtr == nil {
-		return nil, errors.New("context is closed")
+		return nil, newErr(ErrClosed, "context is closed")
 	}
 
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	cConfig, err := go2cSenderConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var cSender *C.roc_sender
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_sender_open(context.cPtr, &cConfig, &cSender)
+	})
+	if errCode != 0 {
+		return nil, newNativeErrLog("roc_sender_open()", errCode, messages)
+	}
+	if cSender == nil {
+		panic("roc_sender_open() returned nil")
+	}
+
+	sender = &Sender{
+		cPtr:          cSender,
+		frameEncoding: config.FrameEncoding,
+		fecEncoding:   config.FecEncoding,
+	}
+
+	return sender, nil
+}
+
+// builds C sender config from Go config
+func go2cSenderConfig(config SenderConfig) (C.struct_roc_sender_config, error) {
+	var cConfig C.struct_roc_sender_config
+
 	cPacketLength, err := go2cUnsignedDuration(config.PacketLength)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config.PacketLength: %w", err)
+		return cConfig, wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.PacketLength: %w", err))
 	}
 
 	cTargetLatency, err := go2cUnsignedDuration(config.TargetLatency)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config.TargetLatency: %w", err)
+		return cConfig, wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.TargetLatency: %w", err))
 	}
 
 	cLatencyTolerance, err := go2cUnsignedDuration(config.LatencyTolerance)
 	if err != nil {
-		return nil, fmt.Errorf("invalid config.LatencyTolerance: %w", err)
+		return cConfig, wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.LatencyTolerance: %w", err))
 	}
 
-	cConfig := C.struct_roc_sender_config{
+	cConfig = C.struct_roc_sender_config{
 		frame_encoding: C.struct_roc_media_encoding{
 			rate:     C.uint(config.FrameEncoding.Rate),
 			format:   C.roc_format(config.FrameEncoding.Format),
@@ -213,20 +257,7 @@ func OpenSender(context *Context, config SenderConfig) (sender *Sender, err erro
 		latency_tolerance:        cLatencyTolerance,
 	}
 
-	var cSender *C.roc_sender
-	errCode := C.roc_sender_open(context.cPtr, &cConfig, &cSender)
-	if errCode != 0 {
-		return nil, newNativeErr("roc_sender_open()", errCode)
-	}
-	if cSender == nil {
-		panic("roc_sender_open() returned nil")
-	}
-
-	sender = &Sender{
-		cPtr: cSender,
-	}
-
-	return sender, nil
+	return cConfig, nil
 }
 
 // Set sender interface configuration.
@@ -252,17 +283,19 @@ func (s *Sender) Configure(slot Slot, iface Interface, config InterfaceConfig) (
 	defer s.mu.RUnlock()
 
 	if s.cPtr == nil {
-		return errors.New("sender is closed")
+		return newErr(ErrClosed, "sender is closed")
 	}
 
 	cOutgoingAddress, err := go2cStr(config.OutgoingAddress)
 	if err != nil {
-		return fmt.Errorf("invalid config.OutgoingAddress: %w", err)
+		return wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.OutgoingAddress: %w", err))
 	}
 
 	cMulticastGroup, err := go2cStr(config.MulticastGroup)
 	if err != nil {
-		return fmt.Errorf("invalid config.MulticastGroup: %w", err)
+		return wrapErr(ErrInvalidConfig,
+			fmt.Errorf("invalid config.MulticastGroup: %w", err))
 	}
 
 	var cConfig C.struct_roc_interface_config
@@ -270,25 +303,30 @@ func (s *Sender) Configure(slot Slot, iface Interface, config InterfaceConfig) (
 
 	errCode = C.rocGoSetOutgoingAddress(&cConfig, (*C.char)(&cOutgoingAddress[0]))
 	if errCode != 0 {
-		return fmt.Errorf("invalid config.OutgoingAddress: too long")
+		return newErr(ErrInvalidConfig, "invalid config.OutgoingAddress: too long")
 	}
 
 	errCode = C.rocGoSetMulticastGroup(&cConfig, (*C.char)(&cMulticastGroup[0]))
 	if errCode != 0 {
-		return fmt.Errorf("invalid config.MulticastGroup: too long")
+		return newErr(ErrInvalidConfig, "invalid config.MulticastGroup: too long")
 	}
 
 	cConfig.reuse_address = (C.int)(go2cBool(config.ReuseAddress))
 
-	errCode = C.roc_sender_configure(
-		s.cPtr,
-		(C.roc_slot)(slot),
-		(C.roc_interface)(iface),
-		&cConfig)
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_sender_configure(
+			s.cPtr,
+			(C.roc_slot)(slot),
+			(C.roc_interface)(iface),
+			&cConfig)
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_sender_configure()", errCode)
+		s.slots.setBroken(slot)
+		return newNativeErrLog("roc_sender_configure()", errCode, messages)
 	}
 
+	s.slots.setConfig(slot, iface, config)
+
 	return nil
 }
 
@@ -305,6 +343,10 @@ func (s *Sender) Configure(slot Slot, iface Interface, config InterfaceConfig) (
 // If an error happens during connect, the whole slot is disabled and marked
 // broken. The slot index remains reserved. The user is responsible for removing
 // the slot using Sender.Unlink(), after which slot index can be reused.
+//
+// If endpoint protocol doesn't match the interface or FEC encoding from SenderConfig (see
+// Protocol.Interface() and Protocol.FecEncoding()), returns error matching
+// ErrBadEndpoint and leaves the slot untouched.
 func (s *Sender) Connect(slot Slot, iface Interface, endpoint *Endpoint) (err error) {
 	logWrite(LogDebug,
 		"entering Sender.Connect(): sender=%p slot=%+v iface=%+v endpoint=%+v", s, slot, iface, endpoint,
@@ -317,11 +359,20 @@ func (s *Sender) Connect(slot Slot, iface Interface, endpoint *Endpoint) (err er
 	defer s.mu.RUnlock()
 
 	if s.cPtr == nil {
-		return errors.New("sender is closed")
+		return newErr(ErrClosed, "sender is closed")
 	}
 
 	if endpoint == nil {
-		return errors.New("endpoint is nil")
+		return newErr(ErrBadEndpoint, "endpoint is nil")
+	}
+
+	if err = checkEndpointInterface(iface, endpoint); err != nil {
+		return err
+	}
+	if iface == InterfaceAudioSource || iface == InterfaceAudioRepair {
+		if err = checkEndpointFec(s.fecEncoding, endpoint); err != nil {
+			return err
+		}
 	}
 
 	var errCode C.int
@@ -346,18 +397,90 @@ func (s *Sender) Connect(slot Slot, iface Interface, endpoint *Endpoint) (err er
 		return err
 	}
 
-	errCode = C.roc_sender_connect(
-		s.cPtr,
-		(C.roc_slot)(slot),
-		(C.roc_interface)(iface),
-		cEndp)
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_sender_connect(
+			s.cPtr,
+			(C.roc_slot)(slot),
+			(C.roc_interface)(iface),
+			cEndp)
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_sender_connect()", errCode)
+		s.slots.setBroken(slot)
+		return newNativeErrLog("roc_sender_connect()", errCode, messages)
 	}
 
+	s.slots.setEndpoint(slot, iface, endpoint)
+
 	return nil
 }
 
+// Connect all endpoints of the set to the slot.
+//
+// Connects InterfaceAudioSource, then InterfaceAudioRepair and
+// InterfaceAudioControl, if the corresponding endpoints are non-nil. See
+// Sender.Connect() for details.
+//
+// If any connect fails, the slot is removed using Sender.Unlink(), so that
+// slot index can be reused immediately.
+func (s *Sender) ConnectSet(slot Slot, set *EndpointSet) (err error) {
+	logWrite(LogDebug,
+		"entering Sender.ConnectSet(): sender=%p slot=%+v set=%+v", s, slot, set,
+	)
+	defer func() {
+		logWrite(LogDebug, "leaving Sender.ConnectSet(): sender=%p err=%#v", s, err)
+	}()
+
+	return applyEndpointSet(set,
+		func(iface Interface, endpoint *Endpoint) error {
+			return s.Connect(slot, iface, endpoint)
+		},
+		func() error {
+			return s.Unlink(slot)
+		})
+}
+
+// Allocate a new slot.
+//
+// Reserves the first slot index that is not used by any other slot and returns
+// it. The returned slot is created in native library when it's used first time
+// (e.g. by Sender.Connect()).
+//
+// If name is non-empty, it should be unique among slots of this sender, and
+// can be used to find slot using Sender.SlotByName(). If another slot already
+// has this name, returns ErrExists.
+func (s *Sender) NewSlot(name string) (slot Slot, err error) {
+	logWrite(LogDebug, "entering Sender.NewSlot(): sender=%p name=%q", s, name)
+	defer func() {
+		logWrite(LogDebug, "leaving Sender.NewSlot(): sender=%p slot=%v err=%#v", s, slot, err)
+	}()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.cPtr == nil {
+		return 0, newErr(ErrClosed, "sender is closed")
+	}
+
+	return s.slots.allocate(name)
+}
+
+// Find slot by name passed to Sender.NewSlot().
+func (s *Sender) SlotByName(name string) (Slot, bool) {
+	return s.slots.lookup(name)
+}
+
+// Get state of all slots.
+//
+// Returns slots allocated by Sender.NewSlot() or implicitly created by other
+// methods, sorted by index, with their configured, connected, or bound
+// interfaces. Slots are removed from the list by Sender.Unlink() and
+// Sender.Close().
+//
+// Returned values are copies and can be freely modified.
+func (s *Sender) Slots() []SlotInfo {
+	return s.slots.list()
+}
+
 // Delete sender slot.
 //
 // Disconnects, unbinds, and removes all slot interfaces and removes the slot.
@@ -376,21 +499,98 @@ func (s *Sender) Unlink(slot Slot) (err error) {
 	defer s.mu.RUnlock()
 
 	if s.cPtr == nil {
-		return errors.New("sender is closed")
+		return newErr(ErrClosed, "sender is closed")
 	}
 
-	var errCode C.int
+	if s.slots.removeReserved(slot) {
+		// slot was allocated by NewSlot() but never used
+		return nil
+	}
 
-	errCode = C.roc_sender_unlink(
-		s.cPtr,
-		(C.roc_slot)(slot))
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_sender_unlink(
+			s.cPtr,
+			(C.roc_slot)(slot))
+	})
 	if errCode != 0 {
-		return newNativeErr("roc_sender_unlink()", errCode)
+		return newNativeErrLog("roc_sender_unlink()", errCode, messages)
 	}
 
+	s.slots.remove(slot)
+
 	return nil
 }
 
+// Query sender slot metrics.
+//
+// Returns metrics of the slot and metrics of every active connection of the
+// slot. Connection metrics are reported per remote peer, i.e. one element per
+// each connected receiver.
+//
+// The slot should be already created using Sender.Configure() or
+// Sender.Connect(). Querying a slot that was not created is an error.
+func (s *Sender) Query(slot Slot) (
+	slotMetrics SenderMetrics, connMetrics []ConnectionMetrics, err error,
+) {
+	logWrite(LogDebug,
+		"entering Sender.Query(): sender=%p slot=%+v", s, slot,
+	)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving Sender.Query(): sender=%p slotMetrics=%+v connMetrics=%+v err=%#v",
+			s, slotMetrics, connMetrics, err,
+		)
+	}()
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.cPtr == nil {
+		return SenderMetrics{}, nil, newErr(ErrClosed, "sender is closed")
+	}
+
+	var cSlotMetrics C.struct_roc_sender_metrics
+	var cConnMetrics []C.struct_roc_connection_metrics
+
+	// Number of connections may change between calls, so we repeat query
+	// until the array is large enough to hold metrics of all connections.
+	for {
+		var cConnMetricsPtr *C.struct_roc_connection_metrics
+		if len(cConnMetrics) != 0 {
+			cConnMetricsPtr = &cConnMetrics[0]
+		}
+		cConnMetricsCount := (C.size_t)(len(cConnMetrics))
+
+		errCode := C.roc_sender_query(
+			s.cPtr,
+			(C.roc_slot)(slot),
+			&cSlotMetrics,
+			cConnMetricsPtr,
+			&cConnMetricsCount)
+		if errCode != 0 {
+			return SenderMetrics{}, nil, newNativeErr("roc_sender_query()", errCode)
+		}
+
+		if int(cSlotMetrics.connection_count) <= len(cConnMetrics) {
+			cConnMetrics = cConnMetrics[:cConnMetricsCount]
+			break
+		}
+
+		cConnMetrics = make([]C.struct_roc_connection_metrics, cSlotMetrics.connection_count)
+	}
+
+	slotMetrics = SenderMetrics{
+		ConnectionCount: uint32(cSlotMetrics.connection_count),
+	}
+
+	connMetrics = make([]ConnectionMetrics, len(cConnMetrics))
+	for n := range cConnMetrics {
+		connMetrics[n].fromC(&cConnMetrics[n])
+	}
+
+	return slotMetrics, connMetrics, nil
+}
+
 // Encode samples to packets and transmit them to the receiver.
 //
 // Encodes samples to packets and enqueues them for transmission by the network worker
@@ -405,52 +605,149 @@ func (s *Sender) Unlink(slot Slot) (err error) {
 // dropped. If the sender is connected to multiple receivers, the stream is
 // duplicated to each of them.
 func (s *Sender) WriteFloats(frame []float32) (err error) {
+	return s.WriteFloatsContext(context.Background(), frame)
+}
+
+// Encode samples to packets and transmit them to the receiver, with
+// cancellation.
+//
+// Same as Sender.WriteFloats(), but the blocking can be cancelled using ctx.
+// If ctx is cancelled or its deadline is exceeded, the function returns
+// ctx.Err(). In this case, some leading part of the frame may be already
+// written, and it's counted in SenderStats.SamplesWritten.
+//
+// Both WriteFloats() and WriteFloatsContext() return ErrClosed if the sender
+// is closed concurrently by Sender.Close() while the call is in progress.
+func (s *Sender) WriteFloatsContext(ctx context.Context, frame []float32) (err error) {
+	var numWritten int
+
+	defer func() {
+		s.counters.count(numWritten, err)
+	}()
+
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	if s.cPtr == nil {
-		return errors.New("sender is closed")
+		return newErr(ErrClosed, "sender is closed")
 	}
 
-	if frame == nil {
-		return errors.New("frame is nil")
+	if ctx == nil {
+		return newErr(ErrInvalidArgument, "ctx is nil")
 	}
 
-	if len(frame) == 0 {
-		return nil
+	if frame == nil {
+		return newErr(ErrInvalidArgument, "frame is nil")
 	}
 
-	errCode := C.rocGoSenderWriteFloats(
-		s.cPtr, (*C.float)(&frame[0]), (C.ulong)(len(frame)))
-	if errCode != 0 {
-		return newNativeErr("roc_sender_write()", errCode)
+	chunkLen := interruptChunkLen(s.frameEncoding)
+
+	for numWritten != len(frame) {
+		if atomic.LoadInt32(&s.closing) != 0 {
+			return ErrClosed
+		}
+
+		if err = ctx.Err(); err != nil {
+			return err
+		}
+
+		n := len(frame) - numWritten
+		if n > chunkLen {
+			n = chunkLen
+		}
+
+		errCode := C.rocGoSenderWriteFloats(
+			s.cPtr, (*C.float)(&frame[numWritten]), (C.ulong)(n))
+		if errCode != 0 {
+			return newNativeErr("roc_sender_write()", errCode)
+		}
+
+		numWritten += n
 	}
 
 	return nil
 }
 
+// Encode 16-bit integer samples and transmit them to the receiver.
+//
+// Same as Sender.WriteFloats(), but accepts samples as signed 16-bit
+// integers. Samples are scaled to range [-1; 1) and passed to the sender
+// as FormatPcmFloat32. Number and order of channels are the same as for
+// Sender.WriteFloats().
+func (s *Sender) WriteInt16(frame []int16) (err error) {
+	s.scratch.mu.Lock()
+	defer s.scratch.mu.Unlock()
+
+	return s.WriteFloats(int16ToFloats(frame, &s.scratch))
+}
+
+// Encode packed 24-bit integer samples and transmit them to the receiver.
+//
+// Same as Sender.WriteFloats(), but accepts samples as signed 24-bit
+// little-endian integers, packed into 3 bytes each. Frame size should be
+// a multiple of 3.
+func (s *Sender) WriteInt24Packed(frame []byte) (err error) {
+	if err = int24PackedCheck(frame); err != nil {
+		s.counters.count(0, err)
+		return err
+	}
+
+	s.scratch.mu.Lock()
+	defer s.scratch.mu.Unlock()
+
+	return s.WriteFloats(int24PackedToFloats(frame, &s.scratch))
+}
+
+// Encode 32-bit integer samples and transmit them to the receiver.
+//
+// Same as Sender.WriteFloats(), but accepts samples as signed 32-bit
+// integers. Since samples are converted to 32-bit floats, only 24 most
+// significant bits of each sample are preserved.
+func (s *Sender) WriteInt32(frame []int32) (err error) {
+	s.scratch.mu.Lock()
+	defer s.scratch.mu.Unlock()
+
+	return s.WriteFloats(int32ToFloats(frame, &s.scratch))
+}
+
 // Close the sender.
 //
 // Deinitializes and deallocates the sender, and detaches it from the context.
 // The user should ensure that nobody uses the sender during and after this
 // call. If this function fails, the sender is kept opened and attached to the
 // context.
+//
+// Blocking writes that are in progress in other goroutines are interrupted and
+// return ErrClosed.
 func (s *Sender) Close() (err error) {
 	logWrite(LogDebug, "entering Sender.Close(): sender=%p", s)
 	defer func() {
 		logWrite(LogDebug, "leaving Sender.Close(): sender=%p err=%#v", s, err)
 	}()
 
+	// interrupt in-flight writes, so that they release read lock
+	atomic.StoreInt32(&s.closing, 1)
+
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if s.cPtr != nil {
 		errCode := C.roc_sender_close(s.cPtr)
 		if errCode != 0 {
+			atomic.StoreInt32(&s.closing, 0)
 			return newNativeErr("roc_sender_close()", errCode)
 		}
 
 		s.cPtr = nil
+		s.slots.clear()
+
+		if s.release != nil {
+			release := s.release
+			s.release = nil
+			if err := release(); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -0,0 +1,371 @@
+package roc
+
+/*
+#include <roc/sender_encoder.h>
+
+int rocGoSenderEncoderPushFloats(roc_sender_encoder* encoder, float* samples,
+                                 unsigned long samples_size);
+int rocGoSenderEncoderPushFeedbackPacket(roc_sender_encoder* encoder, roc_interface iface,
+                                         unsigned char* bytes, unsigned long bytes_size);
+int rocGoSenderEncoderPopPacket(roc_sender_encoder* encoder, roc_interface iface,
+                                unsigned char* bytes, unsigned long* bytes_size);
+*/
+import "C"
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Sender encoder.
+//
+// Sender encoder gets an audio stream from the user, encodes it into network
+// packets, and provides encoded packets back to the user.
+//
+// Sender encoder is a networkless single-stream version of Sender. It
+// implements the same pipeline, but instead of sending packets to network, it
+// returns them to the user. The user is responsible for carrying packets over
+// network. Unlike Sender, it doesn't support multiple slots and connections.
+// It produces traffic for a single remote peer.
+//
+// For detailed description of sender pipeline, see documentation for Sender.
+//
+// # Context
+//
+// Sender encoder is automatically attached to a context when opened and
+// detached from it when closed. The user should not close the context until
+// the encoder is closed.
+//
+// Sender encoder does not use network worker threads of the context. All the
+// work is performed in the threads of the user.
+//
+// # Life cycle
+//
+//   - A sender encoder is created using OpenSenderEncoder().
+//   - The user activates one or more interfaces by invoking
+//     SenderEncoder.Activate(). This tells encoder what types of streams to
+//     produce and what protocols to use for them (e.g. only audio packets or
+//     also redundancy packets).
+//   - The audio stream is iteratively pushed to the encoder using
+//     SenderEncoder.PushFrame(). The encoder encodes the stream into packets
+//     and accumulates them in internal queue.
+//   - The packet stream is iteratively popped from the encoder internal queue
+//     using SenderEncoder.PopPacket(). The user should retrieve all available
+//     packets from all activated interfaces every time after pushing a frame.
+//   - The user is responsible for delivering packets to ReceiverDecoder and
+//     pushing them to appropriate interfaces of the decoder.
+//   - In addition, if a control interface is activated, the stream of encoded
+//     feedback packets from the decoder is pushed to the encoder using
+//     SenderEncoder.PushFeedbackPacket().
+//   - The encoder is destroyed using SenderEncoder.Close().
+//
+// # Interfaces and protocols
+//
+// Sender encoder may have one or several interfaces, as defined in Interface.
+// The interface defines the type of the communication with the remote peer
+// and the set of the protocols supported by it.
+//
+// Each interface has its own outgoing queue of packets. To produce packets
+// for an interface, the interface should be first activated with a protocol,
+// and then packets can be popped from its queue.
+//
+// Supported interface configurations:
+//
+//   - InterfaceAudioSource
+//   - InterfaceAudioSource + InterfaceAudioRepair (when FEC is enabled)
+//   - InterfaceAudioSource + InterfaceAudioControl
+//   - InterfaceAudioSource + InterfaceAudioRepair + InterfaceAudioControl
+//
+// # Thread safety
+//
+// Can be used concurrently.
+type SenderEncoder struct {
+	mu            sync.RWMutex
+	cPtr          *C.roc_sender_encoder
+	active        map[Interface]bool
+	maxPacketSize int
+}
+
+// Open a new sender encoder.
+//
+// Allocates and initializes a new sender encoder, and attaches it to the
+// context. Accepts the same configuration as OpenSender().
+func OpenSenderEncoder(context *Context, config SenderConfig) (encoder *SenderEncoder, err error) {
+	logWrite(LogDebug, "entering OpenSenderEncoder(): context=%p config=%+v", context, config)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving OpenSenderEncoder(): context=%p encoder=%p err=%#v", context, encoder, err,
+		)
+	}()
+
+	checkVersionFn()
+
+	if context == nil {
+		return nil, ErrNilContext
+	}
+
+	context.mu.RLock()
+	defer context.mu.RUnlock()
+
+	if context.cPtr == nil {
+		return nil, newErr(ErrClosed, "context is closed")
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	cConfig, err := go2cSenderConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	var cEncoder *C.roc_sender_encoder
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_sender_encoder_open(context.cPtr, &cConfig, &cEncoder)
+	})
+	if errCode != 0 {
+		return nil, newNativeErrLog("roc_sender_encoder_open()", errCode, messages)
+	}
+	if cEncoder == nil {
+		panic("roc_sender_encoder_open() returned nil")
+	}
+
+	encoder = &SenderEncoder{
+		cPtr:          cEncoder,
+		active:        make(map[Interface]bool),
+		maxPacketSize: context.maxPacketSize,
+	}
+
+	return encoder, nil
+}
+
+// Activate encoder interface.
+//
+// Checks that the protocol is valid and supported by the interface, and
+// initializes given interface with given protocol.
+//
+// The user should invoke SenderEncoder.PopPacket() for all activated
+// interfaces and deliver packets to appropriate destinations.
+//
+// Each interface can be activated only once.
+func (e *SenderEncoder) Activate(iface Interface, proto Protocol) (err error) {
+	logWrite(LogDebug,
+		"entering SenderEncoder.Activate(): encoder=%p iface=%+v proto=%+v", e, iface, proto,
+	)
+	defer func() {
+		logWrite(LogDebug, "leaving SenderEncoder.Activate(): encoder=%p err=%#v", e, err)
+	}()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cPtr == nil {
+		return newErr(ErrClosed, "encoder is closed")
+	}
+
+	errCode, messages := logCapture(func() C.int {
+		return C.roc_sender_encoder_activate(
+			e.cPtr,
+			(C.roc_interface)(iface),
+			(C.roc_protocol)(proto))
+	})
+	if errCode != 0 {
+		return newNativeErrLog("roc_sender_encoder_activate()", errCode, messages)
+	}
+
+	e.active[iface] = true
+
+	return nil
+}
+
+// Query encoder metrics.
+//
+// Returns metrics of the encoder and metrics of the connection to the remote
+// peer. Connection metrics are available only if control interface is
+// activated and feedback packets are delivered to the encoder.
+func (e *SenderEncoder) Query() (
+	encoderMetrics SenderMetrics, connMetrics ConnectionMetrics, err error,
+) {
+	logWrite(LogDebug, "entering SenderEncoder.Query(): encoder=%p", e)
+	defer func() {
+		logWrite(LogDebug,
+			"leaving SenderEncoder.Query(): encoder=%p encoderMetrics=%+v connMetrics=%+v err=%#v",
+			e, encoderMetrics, connMetrics, err,
+		)
+	}()
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.cPtr == nil {
+		return SenderMetrics{}, ConnectionMetrics{}, newErr(ErrClosed, "encoder is closed")
+	}
+
+	var cEncoderMetrics C.struct_roc_sender_metrics
+	var cConnMetrics C.struct_roc_connection_metrics
+
+	errCode := C.roc_sender_encoder_query(e.cPtr, &cEncoderMetrics, &cConnMetrics)
+	if errCode != 0 {
+		return SenderMetrics{}, ConnectionMetrics{},
+			newNativeErr("roc_sender_encoder_query()", errCode)
+	}
+
+	encoderMetrics = SenderMetrics{
+		ConnectionCount: uint32(cEncoderMetrics.connection_count),
+	}
+	connMetrics.fromC(&cConnMetrics)
+
+	return encoderMetrics, connMetrics, nil
+}
+
+// Write frame to encoder.
+//
+// Encodes samples to packets and enqueues them to internal queues of
+// activated interfaces.
+//
+// If ClockSourceInternal is used, the function blocks until it's time to
+// encode the samples according to the configured sample rate.
+//
+// Until at least one interface is activated, the stream is just dropped.
+func (e *SenderEncoder) PushFrame(frame []float32) (err error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.cPtr == nil {
+		return newErr(ErrClosed, "encoder is closed")
+	}
+
+	if frame == nil {
+		return newErr(ErrInvalidArgument, "frame is nil")
+	}
+
+	if len(frame) == 0 {
+		return nil
+	}
+
+	errCode := C.rocGoSenderEncoderPushFloats(
+		e.cPtr, (*C.float)(&frame[0]), (C.ulong)(len(frame)))
+	if errCode != 0 {
+		return newNativeErr("roc_sender_encoder_push_frame()", errCode)
+	}
+
+	return nil
+}
+
+// Write feedback packet to encoder.
+//
+// Adds encoded feedback packet to the interface queue. The packet is copied,
+// so the user can reuse the buffer after the call.
+//
+// The user should iteratively push all delivered feedback packets to
+// appropriate interfaces. They will be later decoded and used to adjust
+// encoding.
+//
+// The interface should be activated before pushing packets to it.
+func (e *SenderEncoder) PushFeedbackPacket(iface Interface, packet []byte) (err error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.cPtr == nil {
+		return newErr(ErrClosed, "encoder is closed")
+	}
+
+	if packet == nil {
+		return newErr(ErrInvalidArgument, "packet is nil")
+	}
+
+	if len(packet) == 0 {
+		return newErr(ErrInvalidArgument, "packet is empty")
+	}
+
+	errCode := C.rocGoSenderEncoderPushFeedbackPacket(
+		e.cPtr,
+		(C.roc_interface)(iface),
+		(*C.uchar)(&packet[0]),
+		(C.ulong)(len(packet)))
+	if errCode != 0 {
+		return newNativeErr("roc_sender_encoder_push_feedback_packet()", errCode)
+	}
+
+	return nil
+}
+
+// Read packet from encoder.
+//
+// Removes encoded packet from the interface queue and copies it into the
+// provided buffer. Returns number of bytes written to the buffer.
+//
+// The buffer should be large enough to hold a packet, i.e. not less than
+// ContextConfig.MaxPacketSize (or its default, if it was zero). Otherwise,
+// returns ErrInvalidArgument without removing packet from the queue.
+//
+// The user should iteratively pop all available packets from all activated
+// interfaces after every pushed frame, and deliver them to the remote peer.
+// When the interface is activated, but there are no more packets in its queue,
+// returns ErrNoPacket. If the interface is not activated, returns NativeError.
+func (e *SenderEncoder) PopPacket(iface Interface, packet []byte) (n int, err error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.cPtr == nil {
+		return 0, newErr(ErrClosed, "encoder is closed")
+	}
+
+	if packet == nil {
+		return 0, newErr(ErrInvalidArgument, "packet is nil")
+	}
+
+	if len(packet) == 0 {
+		return 0, newErr(ErrInvalidArgument, "packet is empty")
+	}
+
+	if len(packet) < e.maxPacketSize {
+		return 0, newErr(ErrInvalidArgument,
+			fmt.Sprintf("packet size %d is less than max packet size %d",
+				len(packet), e.maxPacketSize))
+	}
+
+	cPacketSize := (C.ulong)(len(packet))
+
+	errCode := C.rocGoSenderEncoderPopPacket(
+		e.cPtr,
+		(C.roc_interface)(iface),
+		(*C.uchar)(&packet[0]),
+		&cPacketSize)
+	if errCode != 0 {
+		if e.active[iface] {
+			return 0, newErr(ErrNoPacket, "no more packets in interface queue")
+		}
+		return 0, newNativeErr("roc_sender_encoder_pop_packet()", errCode)
+	}
+
+	return int(cPacketSize), nil
+}
+
+// Close the sender encoder.
+//
+// Deinitializes and deallocates the encoder, and detaches it from the
+// context. The user should ensure that nobody uses the encoder during and
+// after this call. If this function fails, the encoder is kept opened and
+// attached to the context.
+func (e *SenderEncoder) Close() (err error) {
+	logWrite(LogDebug, "entering SenderEncoder.Close(): encoder=%p", e)
+	defer func() {
+		logWrite(LogDebug, "leaving SenderEncoder.Close(): encoder=%p err=%#v", e, err)
+	}()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.cPtr != nil {
+		errCode := C.roc_sender_encoder_close(e.cPtr)
+		if errCode != 0 {
+			return newNativeErr("roc_sender_encoder_close()", errCode)
+		}
+
+		e.cPtr = nil
+	}
+
+	return nil
+}
@@ -0,0 +1,310 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSenderEncoder_Open(t *testing.T) {
+	tests := []struct {
+		name        string
+		contextFunc func() *Context
+		configFunc  func() SenderConfig
+		wantErr     error
+	}{
+		{
+			name: "ok",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: makeSenderConfig,
+			wantErr:    nil,
+		},
+		{
+			name: "nil context",
+			contextFunc: func() *Context {
+				return nil
+			},
+			configFunc: makeSenderConfig,
+			wantErr:    ErrNilContext,
+		},
+		{
+			name: "closed context",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+
+				err = ctx.Close()
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: makeSenderConfig,
+			wantErr:    newErr(ErrClosed, "context is closed"),
+		},
+		{
+			name: "invalid config.FrameEncoding.Rate",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.FrameEncoding.Rate = 0
+				return sc
+			},
+			wantErr: ConfigError{{Field: "FrameEncoding.Rate", Reason: "should be non-zero"}},
+		},
+		{
+			name: "invalid config.PacketLength",
+			contextFunc: func() *Context {
+				ctx, err := OpenContext(makeContextConfig())
+				require.NoError(t, err)
+				return ctx
+			},
+			configFunc: func() SenderConfig {
+				sc := makeSenderConfig()
+				sc.PacketLength = -1
+				return sc
+			},
+			wantErr: ConfigError{{Field: "PacketLength",
+				Reason: "should be non-negative, got -1ns"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.contextFunc()
+
+			encoder, err := OpenSenderEncoder(ctx, tt.configFunc())
+
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+				require.NotNil(t, encoder)
+
+				err = encoder.Close()
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tt.wantErr, stripNativeLog(err))
+				require.Nil(t, encoder)
+			}
+
+			if ctx != nil {
+				err = ctx.Close()
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestSenderEncoder_Activate(t *testing.T) {
+	cases := []struct {
+		name    string
+		iface   Interface
+		proto   Protocol
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			iface:   InterfaceAudioSource,
+			proto:   ProtoRtpRs8mSource,
+			wantErr: nil,
+		},
+		{
+			name:    "bad iface",
+			iface:   -1,
+			proto:   ProtoRtpRs8mSource,
+			wantErr: newNativeErr("roc_sender_encoder_activate()", -1),
+		},
+		{
+			name:    "bad protocol",
+			iface:   InterfaceAudioSource,
+			proto:   ProtoRs8mRepair,
+			wantErr: newNativeErr("roc_sender_encoder_activate()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			encoder, err := OpenSenderEncoder(ctx, makeSenderConfig())
+			require.NoError(t, err)
+			require.NotNil(t, encoder)
+
+			err = encoder.Activate(tt.iface, tt.proto)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = encoder.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSenderEncoder_PushFrame(t *testing.T) {
+	cases := []struct {
+		name    string
+		frame   []float32
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			frame:   make([]float32, 100),
+			wantErr: nil,
+		},
+		{
+			name:    "nil frame",
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+		{
+			name:    "empty frame",
+			frame:   []float32{},
+			wantErr: nil,
+		},
+		{
+			name:    "bad frame",
+			frame:   []float32{1.0},
+			wantErr: newNativeErr("roc_sender_encoder_push_frame()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			encoder, err := OpenSenderEncoder(ctx, makeSenderConfig())
+			require.NoError(t, err)
+			require.NotNil(t, encoder)
+
+			err = encoder.PushFrame(tt.frame)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = encoder.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSenderEncoder_PopPacket(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	config := makeSenderConfig()
+	config.FecEncoding = FecEncodingDisable
+
+	encoder, err := OpenSenderEncoder(ctx, config)
+	require.NoError(t, err)
+	require.NotNil(t, encoder)
+
+	err = encoder.Activate(InterfaceAudioSource, ProtoRtp)
+	require.NoError(t, err)
+
+	packet := make([]byte, makeContextConfig().MaxPacketSize)
+
+	// no packets yet
+	_, err = encoder.PopPacket(InterfaceAudioSource, packet)
+	require.True(t, errors.Is(err, ErrNoPacket))
+
+	// bad arguments
+	_, err = encoder.PopPacket(InterfaceAudioSource, nil)
+	require.Equal(t, newErr(ErrInvalidArgument, "packet is nil"), err)
+
+	_, err = encoder.PopPacket(InterfaceAudioSource, []byte{})
+	require.Equal(t, newErr(ErrInvalidArgument, "packet is empty"), err)
+
+	_, err = encoder.PopPacket(InterfaceAudioSource, packet[:len(packet)-1])
+	require.True(t, errors.Is(err, ErrInvalidArgument))
+
+	// push enough samples to produce at least one packet
+	frame := make([]float32, 44100/10*NumChannels)
+	err = encoder.PushFrame(frame)
+	require.NoError(t, err)
+
+	// too small buffer doesn't drop packet
+	_, err = encoder.PopPacket(InterfaceAudioSource, packet[:10])
+	require.True(t, errors.Is(err, ErrInvalidArgument))
+
+	n, err := encoder.PopPacket(InterfaceAudioSource, packet)
+	require.NoError(t, err)
+	require.NotZero(t, n)
+	require.LessOrEqual(t, n, len(packet))
+
+	// repair interface is not activated
+	_, err = encoder.PopPacket(InterfaceAudioRepair, packet)
+	require.Equal(t, newNativeErr("roc_sender_encoder_pop_packet()", -1), err)
+
+	err = encoder.Close()
+	require.NoError(t, err)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestSenderEncoder_Close(t *testing.T) {
+	cases := []struct {
+		name      string
+		operation func(encoder *SenderEncoder) error
+	}{
+		{
+			name: "Activate after close",
+			operation: func(encoder *SenderEncoder) error {
+				return encoder.Activate(InterfaceAudioSource, ProtoRtp)
+			},
+		},
+		{
+			name: "Query after close",
+			operation: func(encoder *SenderEncoder) error {
+				_, _, err := encoder.Query()
+				return err
+			},
+		},
+		{
+			name: "PushFrame after close",
+			operation: func(encoder *SenderEncoder) error {
+				return encoder.PushFrame(make([]float32, 2))
+			},
+		},
+		{
+			name: "PushFeedbackPacket after close",
+			operation: func(encoder *SenderEncoder) error {
+				return encoder.PushFeedbackPacket(InterfaceAudioControl, make([]byte, 10))
+			},
+		},
+		{
+			name: "PopPacket after close",
+			operation: func(encoder *SenderEncoder) error {
+				_, err := encoder.PopPacket(InterfaceAudioSource, make([]byte, 10))
+				return err
+			},
+		},
+	}
+	for _, tt := range cases {
+		ctx, err := OpenContext(makeContextConfig())
+		require.NoError(t, err)
+		require.NotNil(t, ctx)
+
+		encoder, err := OpenSenderEncoder(ctx, makeSenderConfig())
+		require.NoError(t, err)
+		require.NotNil(t, encoder)
+
+		err = encoder.Close()
+		require.NoError(t, err)
+
+		require.Equal(t, newErr(ErrClosed, "encoder is closed"), tt.operation(encoder))
+
+		err = ctx.Close()
+		require.NoError(t, err)
+	}
+}
@@ -0,0 +1,14 @@
+package roc
+
+// Sender metrics.
+//
+// Holds sender-side metrics that are not specific to connection. Metrics of
+// individual connections are reported using ConnectionMetrics.
+//
+// See Sender.Query().
+type SenderMetrics struct {
+	// Number of active connections.
+	//
+	// Defines how much receivers are currently connected to the sender slot.
+	ConnectionCount uint32
+}
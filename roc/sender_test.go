@@ -1,10 +1,12 @@
 package roc
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -32,7 +34,7 @@ func TestSender_Open(t *testing.T) {
 				return nil
 			},
 			configFunc: makeSenderConfig,
-			wantErr:    errors.New("context is nil"),
+			wantErr:    ErrNilContext,
 		},
 		{
 			name: "closed context",
@@ -45,7 +47,7 @@ func TestSender_Open(t *testing.T) {
 				return ctx
 			},
 			configFunc: makeSenderConfig,
-			wantErr:    errors.New("context is closed"),
+			wantErr:    newErr(ErrClosed, "context is closed"),
 		},
 		{
 			name: "invalid config.FrameEncoding.Rate",
@@ -59,7 +61,7 @@ func TestSender_Open(t *testing.T) {
 				sc.FrameEncoding.Rate = 0
 				return sc
 			},
-			wantErr: newNativeErr("roc_sender_open()", -1),
+			wantErr: ConfigError{{Field: "FrameEncoding.Rate", Reason: "should be non-zero"}},
 		},
 		{
 			name: "invalid config.FrameEncoding.Channels",
@@ -73,7 +75,8 @@ func TestSender_Open(t *testing.T) {
 				sc.FrameEncoding.Channels = 0
 				return sc
 			},
-			wantErr: newNativeErr("roc_sender_open()", -1),
+			wantErr: ConfigError{{Field: "FrameEncoding.Channels",
+				Reason: "unsupported value ChannelLayout(0)"}},
 		},
 		{
 			name: "invalid config.FrameEncoding.Format",
@@ -87,7 +90,8 @@ func TestSender_Open(t *testing.T) {
 				sc.FrameEncoding.Format = 0
 				return sc
 			},
-			wantErr: newNativeErr("roc_sender_open()", -1),
+			wantErr: ConfigError{{Field: "FrameEncoding.Format",
+				Reason: "unsupported value Format(0)"}},
 		},
 		{
 			name: "invalid config.PacketLength",
@@ -101,8 +105,8 @@ func TestSender_Open(t *testing.T) {
 				sc.PacketLength = -1
 				return sc
 			},
-			wantErr: fmt.Errorf("invalid config.PacketLength: %w",
-				fmt.Errorf("unexpected negative duration: -1ns")),
+			wantErr: ConfigError{{Field: "PacketLength",
+				Reason: "should be non-negative, got -1ns"}},
 		},
 	}
 
@@ -119,7 +123,7 @@ func TestSender_Open(t *testing.T) {
 				err = sender.Close()
 				require.NoError(t, err)
 			} else {
-				require.Equal(t, tt.wantErr, err)
+				require.Equal(t, tt.wantErr, stripNativeLog(err))
 				require.Nil(t, sender)
 			}
 
@@ -162,7 +166,7 @@ func TestSender_Configure(t *testing.T) {
 				ic.OutgoingAddress = strings.Repeat("x", 500)
 				return ic
 			},
-			wantErr: errors.New("invalid config.OutgoingAddress: too long"),
+			wantErr: newErr(ErrInvalidConfig, "invalid config.OutgoingAddress: too long"),
 		},
 		{
 			name:  "invalid OutgoingAddress",
@@ -173,8 +177,9 @@ func TestSender_Configure(t *testing.T) {
 				ic.OutgoingAddress = "127.0.0.1\x00"
 				return ic
 			},
-			wantErr: fmt.Errorf("invalid config.OutgoingAddress: %w",
-				fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\"")),
+			wantErr: wrapErr(ErrInvalidConfig,
+				fmt.Errorf("invalid config.OutgoingAddress: %w",
+					fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\""))),
 		},
 		{
 			name:  "out of range OutgoingAddress",
@@ -196,7 +201,7 @@ func TestSender_Configure(t *testing.T) {
 				ic.MulticastGroup = strings.Repeat("x", 500)
 				return ic
 			},
-			wantErr: errors.New("invalid config.MulticastGroup: too long"),
+			wantErr: newErr(ErrInvalidConfig, "invalid config.MulticastGroup: too long"),
 		},
 		{
 			name:  "invalid MulticastGroup",
@@ -207,8 +212,9 @@ func TestSender_Configure(t *testing.T) {
 				ic.MulticastGroup = "127.0.0.1\x00"
 				return ic
 			},
-			wantErr: fmt.Errorf("invalid config.MulticastGroup: %w",
-				fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\"")),
+			wantErr: wrapErr(ErrInvalidConfig,
+				fmt.Errorf("invalid config.MulticastGroup: %w",
+					fmt.Errorf("unexpected zero byte in the string: \"127.0.0.1\\x00\""))),
 		},
 		{
 			name:  "out of range MulticastGroup",
@@ -233,7 +239,7 @@ func TestSender_Configure(t *testing.T) {
 			require.NotNil(t, sender)
 
 			err = sender.Configure(tt.slot, tt.iface, tt.configFunc())
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
 
 			err = sender.Close()
 			require.NoError(t, err)
@@ -267,21 +273,32 @@ func TestSender_Connect(t *testing.T) {
 			name:    "nil endpoint",
 			slot:    SlotDefault,
 			iface:   InterfaceAudioSource,
-			wantErr: errors.New("endpoint is nil"),
+			wantErr: newErr(ErrBadEndpoint, "endpoint is nil"),
 		},
 		{
 			name:     "bad endpoint",
 			slot:     SlotDefault,
 			iface:    InterfaceAudioSource,
 			endpoint: &Endpoint{Host: "127.0.0.1", Port: 0, Protocol: ProtoRs8mRepair},
-			wantErr:  newNativeErr("roc_sender_connect()", -1),
+			wantErr: newErr(ErrBadEndpoint,
+				"protocol Rs8mRepair can't be used with interface AudioSource,"+
+					" expected interface AudioRepair"),
+		},
+		{
+			name:     "fec mismatch",
+			slot:     SlotDefault,
+			iface:    InterfaceAudioSource,
+			endpoint: &Endpoint{Host: "127.0.0.1", Port: 0, Protocol: ProtoRtp},
+			wantErr: newErr(ErrBadEndpoint,
+				"protocol Rtp can't be used with fec encoding Default,"+
+					" expected fec encoding Disable"),
 		},
 		{
 			name:     "bad protocol",
 			slot:     SlotDefault,
 			iface:    InterfaceAudioSource,
 			endpoint: &Endpoint{Host: "127.0.0.1", Port: 0, Protocol: 1},
-			wantErr:  newNativeErr("roc_endpoint_set_protocol()", -1),
+			wantErr:  wrapErr(ErrBadEndpoint, newNativeErr("roc_endpoint_set_protocol()", -1)),
 		},
 	}
 
@@ -295,7 +312,78 @@ func TestSender_Connect(t *testing.T) {
 			require.NotNil(t, sender)
 
 			err = sender.Connect(tt.slot, tt.iface, tt.endpoint)
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = sender.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSender_ConnectSet(t *testing.T) {
+	baseSet, err := NewEndpointSet("127.0.0.1", 10001, FecEncodingRs8m)
+	require.NoError(t, err)
+	require.NotNil(t, baseSet)
+
+	noFecSet, err := NewEndpointSet("127.0.0.1", 10001, FecEncodingDisable)
+	require.NoError(t, err)
+	require.NotNil(t, noFecSet)
+
+	cases := []struct {
+		name    string
+		set     *EndpointSet
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			set:     baseSet,
+			wantErr: nil,
+		},
+		{
+			name:    "nil set",
+			set:     nil,
+			wantErr: newErr(ErrBadEndpoint, "endpoint set is nil"),
+		},
+		{
+			name: "bad source",
+			set:  noFecSet,
+			wantErr: newErr(ErrBadEndpoint,
+				"protocol Rtp can't be used with fec encoding Default,"+
+					" expected fec encoding Disable"),
+		},
+		{
+			name: "bad repair",
+			set: &EndpointSet{
+				Source:  baseSet.Source,
+				Repair:  &Endpoint{Host: "127.0.0.1", Port: 10002, Protocol: ProtoLdpcRepair},
+				Control: baseSet.Control,
+			},
+			wantErr: newErr(ErrBadEndpoint,
+				"protocol LdpcRepair can't be used with fec encoding Default,"+
+					" expected fec encoding LdpcStaircase"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			sender, err := OpenSender(ctx, makeSenderConfig())
+			require.NoError(t, err)
+			require.NotNil(t, sender)
+
+			err = sender.ConnectSet(SlotDefault, tt.set)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			if tt.wantErr != nil {
+				// slot is rolled back and can be reused
+				err = sender.ConnectSet(SlotDefault, baseSet)
+				require.NoError(t, err)
+			}
 
 			err = sender.Close()
 			require.NoError(t, err)
@@ -349,7 +437,135 @@ func TestSender_Unlink(t *testing.T) {
 			require.NoError(t, err)
 
 			err = sender.Unlink(tt.slot)
-			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = sender.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSender_Slots(t *testing.T) {
+	baseEndpoint, err := ParseEndpoint("rtp+rs8m://127.0.0.1:123")
+	require.NoError(t, err)
+	require.NotNil(t, baseEndpoint)
+
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+	require.NotNil(t, sender)
+
+	require.Empty(t, sender.Slots())
+
+	slot0, err := sender.NewSlot("foo")
+	require.NoError(t, err)
+	require.Equal(t, SlotDefault, slot0)
+
+	slot1, err := sender.NewSlot("")
+	require.NoError(t, err)
+	require.Equal(t, Slot(1), slot1)
+
+	_, err = sender.NewSlot("foo")
+	require.True(t, errors.Is(err, ErrExists))
+
+	slot, ok := sender.SlotByName("foo")
+	require.True(t, ok)
+	require.Equal(t, slot0, slot)
+
+	err = sender.Configure(slot0, InterfaceAudioSource, makeInterfaceConfig())
+	require.NoError(t, err)
+
+	err = sender.Connect(slot0, InterfaceAudioSource, baseEndpoint)
+	require.NoError(t, err)
+
+	config := makeInterfaceConfig()
+	require.Equal(t, []SlotInfo{
+		{
+			Slot: slot0,
+			Name: "foo",
+			Interfaces: []SlotInterface{
+				{
+					Interface: InterfaceAudioSource,
+					Config:    &config,
+					Endpoint:  baseEndpoint,
+				},
+			},
+		},
+		{
+			Slot: slot1,
+		},
+	}, sender.Slots())
+
+	// slot was never used, so it's removed without touching native library
+	err = sender.Unlink(slot1)
+	require.NoError(t, err)
+
+	err = sender.Unlink(slot0)
+	require.NoError(t, err)
+
+	require.Empty(t, sender.Slots())
+
+	_, ok = sender.SlotByName("foo")
+	require.False(t, ok)
+
+	err = sender.Close()
+	require.NoError(t, err)
+
+	_, err = sender.NewSlot("bar")
+	require.Equal(t, newErr(ErrClosed, "sender is closed"), err)
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
+func TestSender_Query(t *testing.T) {
+	baseEndpoint, err := ParseEndpoint("rtp+rs8m://127.0.0.1:123")
+	require.NoError(t, err)
+	require.NotNil(t, baseEndpoint)
+
+	cases := []struct {
+		name    string
+		slot    Slot
+		wantErr error
+	}{
+		{
+			name:    "ok",
+			slot:    SlotDefault,
+			wantErr: nil,
+		},
+		{
+			name:    "bad slot",
+			slot:    1,
+			wantErr: newNativeErr("roc_sender_query()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			sender, err := OpenSender(ctx, makeSenderConfig())
+			require.NoError(t, err)
+			require.NotNil(t, sender)
+
+			err = sender.Connect(SlotDefault, InterfaceAudioSource, baseEndpoint)
+			require.NoError(t, err)
+
+			slotMetrics, connMetrics, err := sender.Query(tt.slot)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			if tt.wantErr == nil {
+				require.Equal(t, int(slotMetrics.ConnectionCount), len(connMetrics))
+			} else {
+				require.Equal(t, SenderMetrics{}, slotMetrics)
+				require.Nil(t, connMetrics)
+			}
 
 			err = sender.Close()
 			require.NoError(t, err)
@@ -379,7 +595,7 @@ func TestSender_WriteFloats(t *testing.T) {
 		},
 		{
 			name:    "nil frame",
-			wantErr: errors.New("frame is nil"),
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
 		},
 		{
 			name:    "empty frame",
@@ -404,7 +620,7 @@ func TestSender_WriteFloats(t *testing.T) {
 
 			err = sender.WriteFloats(tt.frame)
 			if tt.wantErr != nil {
-				require.Equal(t, tt.wantErr.Error(), err.Error())
+				require.Equal(t, tt.wantErr.Error(), stripNativeLog(err).Error())
 			} else {
 				require.NoError(t, err)
 			}
@@ -418,6 +634,201 @@ func TestSender_WriteFloats(t *testing.T) {
 	}
 }
 
+func TestSender_WriteInt(t *testing.T) {
+	cases := []struct {
+		name    string
+		write   func(sender *Sender) error
+		wantErr error
+	}{
+		{
+			name: "int16 ok",
+			write: func(sender *Sender) error {
+				return sender.WriteInt16([]int16{1, -1})
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int16 nil frame",
+			write: func(sender *Sender) error {
+				return sender.WriteInt16(nil)
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+		{
+			name: "int16 bad frame",
+			write: func(sender *Sender) error {
+				return sender.WriteInt16([]int16{1})
+			},
+			wantErr: newNativeErr("roc_sender_write()", -1),
+		},
+		{
+			name: "int24 ok",
+			write: func(sender *Sender) error {
+				return sender.WriteInt24Packed(make([]byte, 6))
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int24 nil frame",
+			write: func(sender *Sender) error {
+				return sender.WriteInt24Packed(nil)
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+		{
+			name: "int24 partial sample",
+			write: func(sender *Sender) error {
+				return sender.WriteInt24Packed(make([]byte, 5))
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame size is not a multiple of 3"),
+		},
+		{
+			name: "int32 ok",
+			write: func(sender *Sender) error {
+				return sender.WriteInt32([]int32{1, -1})
+			},
+			wantErr: nil,
+		},
+		{
+			name: "int32 nil frame",
+			write: func(sender *Sender) error {
+				return sender.WriteInt32(nil)
+			},
+			wantErr: newErr(ErrInvalidArgument, "frame is nil"),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			sender, err := OpenSender(ctx, makeSenderConfig())
+			require.NoError(t, err)
+			require.NotNil(t, sender)
+
+			err = tt.write(sender)
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			err = sender.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSender_WriteFloatsContext(t *testing.T) {
+	cases := []struct {
+		name     string
+		ctxFunc  func() (context.Context, context.CancelFunc)
+		frameLen int
+		wantErr  error
+	}{
+		{
+			name: "ok",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			frameLen: 100,
+			wantErr:  nil,
+		},
+		{
+			name: "nil ctx",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return nil, func() {}
+			},
+			frameLen: 100,
+			wantErr:  newErr(ErrInvalidArgument, "ctx is nil"),
+		},
+		{
+			name: "cancelled",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx, cancel
+			},
+			frameLen: 100,
+			wantErr:  context.Canceled,
+		},
+		{
+			name: "deadline exceeded",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return context.WithTimeout(context.Background(), 50*time.Millisecond)
+			},
+			// 10 seconds
+			frameLen: 44100 * NumChannels * 10,
+			wantErr:  context.DeadlineExceeded,
+		},
+		{
+			name: "bad frame",
+			ctxFunc: func() (context.Context, context.CancelFunc) {
+				return context.WithCancel(context.Background())
+			},
+			frameLen: 1,
+			wantErr:  newNativeErr("roc_sender_write()", -1),
+		},
+	}
+
+	for _, tt := range cases {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, err := OpenContext(makeContextConfig())
+			require.NoError(t, err)
+
+			sender, err := OpenSender(ctx, makeSenderConfig())
+			require.NoError(t, err)
+			require.NotNil(t, sender)
+
+			callCtx, cancel := tt.ctxFunc()
+			defer cancel()
+
+			startTime := time.Now()
+
+			err = sender.WriteFloatsContext(callCtx, make([]float32, tt.frameLen))
+			require.Equal(t, tt.wantErr, stripNativeLog(err))
+
+			require.Less(t, int64(time.Since(startTime)), int64(5*time.Second))
+
+			err = sender.Close()
+			require.NoError(t, err)
+
+			err = ctx.Close()
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestSender_CloseInterrupt(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+	require.NotNil(t, sender)
+
+	errCh := make(chan error, 1)
+	go func() {
+		// 10 seconds
+		errCh <- sender.WriteFloats(make([]float32, 44100*NumChannels*10))
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	err = sender.Close()
+	require.NoError(t, err)
+
+	select {
+	case err = <-errCh:
+		require.Equal(t, ErrClosed, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("WriteFloats() was not interrupted by Close()")
+	}
+
+	err = ctx.Close()
+	require.NoError(t, err)
+}
+
 func TestSender_Close(t *testing.T) {
 	cases := []struct {
 		name      string
@@ -441,6 +852,13 @@ func TestSender_Close(t *testing.T) {
 				return sender.Unlink(SlotDefault)
 			},
 		},
+		{
+			name: "Query after close",
+			operation: func(sender *Sender) error {
+				_, _, err := sender.Query(SlotDefault)
+				return err
+			},
+		},
 		{
 			name: "WriteFloats after close",
 			operation: func(sender *Sender) error {
@@ -448,6 +866,30 @@ func TestSender_Close(t *testing.T) {
 				return sender.WriteFloats(recFloats)
 			},
 		},
+		{
+			name: "WriteFloatsContext after close",
+			operation: func(sender *Sender) error {
+				return sender.WriteFloatsContext(context.Background(), make([]float32, 2))
+			},
+		},
+		{
+			name: "WriteInt16 after close",
+			operation: func(sender *Sender) error {
+				return sender.WriteInt16(make([]int16, 2))
+			},
+		},
+		{
+			name: "WriteInt24Packed after close",
+			operation: func(sender *Sender) error {
+				return sender.WriteInt24Packed(make([]byte, 6))
+			},
+		},
+		{
+			name: "WriteInt32 after close",
+			operation: func(sender *Sender) error {
+				return sender.WriteInt32(make([]int32, 2))
+			},
+		},
 	}
 	for _, tt := range cases {
 		ctx, err := OpenContext(makeContextConfig())
@@ -461,7 +903,7 @@ func TestSender_Close(t *testing.T) {
 		err = sender.Close()
 		require.NoError(t, err)
 
-		require.Equal(t, errors.New("sender is closed"), tt.operation(sender))
+		require.Equal(t, newErr(ErrClosed, "sender is closed"), tt.operation(sender))
 
 		err = ctx.Close()
 		require.NoError(t, err)
@@ -0,0 +1,222 @@
+package roc
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// SlotInfo describes state of a slot of Sender or Receiver.
+//
+// Returned by Sender.Slots() and Receiver.Slots(). Contains only information
+// known to the bindings, i.e. what was passed to successful calls of
+// Configure(), Connect(), and Bind(), and what was reported back by them.
+type SlotInfo struct {
+	// Slot index.
+	Slot Slot
+
+	// Slot name passed to NewSlot(), or empty string.
+	Name string
+
+	// True if one of the operations on slot failed in native library, and the
+	// slot should be removed using Unlink().
+	Broken bool
+
+	// Configured, connected, or bound interfaces, sorted by Interface.
+	Interfaces []SlotInterface
+}
+
+// SlotInterface describes state of an interface of a slot.
+type SlotInterface struct {
+	// Interface type.
+	Interface Interface
+
+	// Config passed to Configure(), or nil if interface was not configured.
+	Config *InterfaceConfig
+
+	// Endpoint passed to Connect() or Bind(), or nil if interface was not
+	// connected or bound. For receiver, contains actually bound port.
+	Endpoint *Endpoint
+}
+
+// tracks slots of sender or receiver
+type slotRegistry struct {
+	mu    sync.Mutex
+	slots map[Slot]*slotState
+}
+
+type slotState struct {
+	name    string
+	created bool
+	broken  bool
+	ifaces  map[Interface]*SlotInterface
+}
+
+// reserves first unused slot index
+func (reg *slotRegistry) allocate(name string) (Slot, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	if slot, ok := reg.lookupLocked(name); ok {
+		return 0, newErr(ErrExists,
+			fmt.Sprintf("slot name %q is already used by slot %d", name, slot))
+	}
+
+	slot := SlotDefault
+	for {
+		if _, ok := reg.slots[slot]; !ok {
+			break
+		}
+		slot++
+	}
+
+	reg.getLocked(slot).name = name
+
+	return slot, nil
+}
+
+func (reg *slotRegistry) lookup(name string) (Slot, bool) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	return reg.lookupLocked(name)
+}
+
+func (reg *slotRegistry) lookupLocked(name string) (Slot, bool) {
+	if name == "" {
+		return 0, false
+	}
+	for slot, state := range reg.slots {
+		if state.name == name {
+			return slot, true
+		}
+	}
+	return 0, false
+}
+
+// remembers successful configure
+func (reg *slotRegistry) setConfig(slot Slot, iface Interface, config InterfaceConfig) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	reg.getIfaceLocked(slot, iface).Config = &config
+}
+
+// remembers successful connect or bind
+func (reg *slotRegistry) setEndpoint(slot Slot, iface Interface, endpoint *Endpoint) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	endpointCopy := *endpoint
+	reg.getIfaceLocked(slot, iface).Endpoint = &endpointCopy
+}
+
+// remembers failed native operation, which marks slot broken
+func (reg *slotRegistry) setBroken(slot Slot) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	state := reg.getLocked(slot)
+	state.created = true
+	state.broken = true
+}
+
+func (reg *slotRegistry) remove(slot Slot) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	delete(reg.slots, slot)
+}
+
+// removes slot if it was allocated but not created in native library
+func (reg *slotRegistry) removeReserved(slot Slot) bool {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	state, ok := reg.slots[slot]
+	if !ok || state.created {
+		return false
+	}
+
+	delete(reg.slots, slot)
+	return true
+}
+
+func (reg *slotRegistry) clear() {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	reg.slots = nil
+}
+
+// returns deep copy of all slots, sorted by index
+func (reg *slotRegistry) list() []SlotInfo {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+
+	infos := make([]SlotInfo, 0, len(reg.slots))
+
+	for slot, state := range reg.slots {
+		info := SlotInfo{
+			Slot:   slot,
+			Name:   state.name,
+			Broken: state.broken,
+		}
+
+		for _, iface := range state.ifaces {
+			ifaceCopy := *iface
+			if iface.Config != nil {
+				config := *iface.Config
+				ifaceCopy.Config = &config
+			}
+			if iface.Endpoint != nil {
+				endpoint := *iface.Endpoint
+				ifaceCopy.Endpoint = &endpoint
+			}
+			info.Interfaces = append(info.Interfaces, ifaceCopy)
+		}
+
+		sort.Slice(info.Interfaces, func(i, j int) bool {
+			return info.Interfaces[i].Interface < info.Interfaces[j].Interface
+		})
+
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Slot < infos[j].Slot
+	})
+
+	return infos
+}
+
+func (reg *slotRegistry) getLocked(slot Slot) *slotState {
+	if reg.slots == nil {
+		reg.slots = make(map[Slot]*slotState)
+	}
+
+	state, ok := reg.slots[slot]
+	if !ok {
+		state = &slotState{
+			ifaces: make(map[Interface]*SlotInterface),
+		}
+		reg.slots[slot] = state
+	}
+
+	return state
+}
+
+func (reg *slotRegistry) getIfaceLocked(slot Slot, iface Interface) *SlotInterface {
+	state := reg.getLocked(slot)
+	state.created = true
+
+	ifaceState, ok := state.ifaces[iface]
+	if !ok {
+		ifaceState = &SlotInterface{
+			Interface: iface,
+		}
+		state.ifaces[iface] = ifaceState
+	}
+
+	return ifaceState
+}
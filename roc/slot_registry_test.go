@@ -0,0 +1,107 @@
+package roc
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSlotRegistry(t *testing.T) {
+	var reg slotRegistry
+
+	require.Empty(t, reg.list())
+
+	slot0, err := reg.allocate("foo")
+	require.NoError(t, err)
+	require.Equal(t, SlotDefault, slot0)
+
+	slot1, err := reg.allocate("")
+	require.NoError(t, err)
+	require.Equal(t, Slot(1), slot1)
+
+	slot2, err := reg.allocate("bar")
+	require.NoError(t, err)
+	require.Equal(t, Slot(2), slot2)
+
+	_, err = reg.allocate("foo")
+	require.True(t, errors.Is(err, ErrExists))
+
+	slot, ok := reg.lookup("bar")
+	require.True(t, ok)
+	require.Equal(t, slot2, slot)
+
+	_, ok = reg.lookup("")
+	require.False(t, ok)
+
+	_, ok = reg.lookup("baz")
+	require.False(t, ok)
+
+	// reserved slot can be removed without native call
+	require.True(t, reg.removeReserved(slot1))
+	require.False(t, reg.removeReserved(slot1))
+
+	slot, err = reg.allocate("")
+	require.NoError(t, err)
+	require.Equal(t, slot1, slot)
+
+	endpoint := &Endpoint{Protocol: ProtoRtpRs8mSource, Host: "127.0.0.1", Port: 10001}
+	config := InterfaceConfig{ReuseAddress: true}
+
+	reg.setEndpoint(slot0, InterfaceAudioRepair, endpoint)
+	reg.setEndpoint(slot0, InterfaceAudioSource, endpoint)
+	reg.setConfig(slot0, InterfaceAudioSource, config)
+	reg.setBroken(slot2)
+
+	// used slot is not removed as reserved
+	require.False(t, reg.removeReserved(slot0))
+	require.False(t, reg.removeReserved(slot2))
+
+	// registry keeps its own copy of endpoint
+	endpoint.Port = 20001
+
+	infos := reg.list()
+	require.Equal(t, []SlotInfo{
+		{
+			Slot: slot0,
+			Name: "foo",
+			Interfaces: []SlotInterface{
+				{
+					Interface: InterfaceAudioSource,
+					Config:    &InterfaceConfig{ReuseAddress: true},
+					Endpoint: &Endpoint{
+						Protocol: ProtoRtpRs8mSource, Host: "127.0.0.1", Port: 10001,
+					},
+				},
+				{
+					Interface: InterfaceAudioRepair,
+					Endpoint: &Endpoint{
+						Protocol: ProtoRtpRs8mSource, Host: "127.0.0.1", Port: 10001,
+					},
+				},
+			},
+		},
+		{
+			Slot: slot1,
+		},
+		{
+			Slot:   slot2,
+			Name:   "bar",
+			Broken: true,
+		},
+	}, infos)
+
+	// returned values are copies
+	infos[0].Interfaces[0].Endpoint.Port = 30001
+	infos[0].Interfaces[0].Config.ReuseAddress = false
+	require.Equal(t, 10001, reg.list()[0].Interfaces[0].Endpoint.Port)
+	require.True(t, reg.list()[0].Interfaces[0].Config.ReuseAddress)
+
+	reg.remove(slot0)
+	_, ok = reg.lookup("foo")
+	require.False(t, ok)
+	require.Len(t, reg.list(), 2)
+
+	reg.clear()
+	require.Empty(t, reg.list())
+}
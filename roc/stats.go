@@ -0,0 +1,94 @@
+package roc
+
+import (
+	"sync/atomic"
+)
+
+// Sender statistics.
+//
+// Holds counters maintained by Go bindings, as opposed to SenderMetrics,
+// which are reported by the native library. Counters are cumulative since
+// the sender was opened and remain available after it's closed.
+//
+// See Sender.Stats().
+type SenderStats struct {
+	// Number of frames successfully written to the sender.
+	//
+	// Includes frames written using Sender.WriteFloats() and all functions
+	// built on top of it, like Sender.WriteInt16() or PcmWriter.
+	FramesWritten uint64
+
+	// Number of samples (for all channels) written to the sender.
+	//
+	// Includes samples from the leading part of a frame that was written
+	// before the write failed, e.g. because it was cancelled.
+	SamplesWritten uint64
+
+	// Number of write calls that returned an error.
+	WriteErrors uint64
+}
+
+// Receiver statistics.
+//
+// Holds counters maintained by Go bindings, as opposed to ReceiverMetrics,
+// which are reported by the native library. Counters are cumulative since
+// the receiver was opened and remain available after it's closed.
+//
+// See Receiver.Stats().
+type ReceiverStats struct {
+	// Number of frames successfully read from the receiver.
+	//
+	// Includes frames read using Receiver.ReadFloats() and all functions
+	// built on top of it, like Receiver.ReadInt16() or PcmReader.
+	FramesRead uint64
+
+	// Number of samples (for all channels) read from the receiver.
+	//
+	// Includes samples from the leading part of a frame that was read before
+	// the read failed, e.g. because it was cancelled.
+	SamplesRead uint64
+
+	// Number of read calls that returned an error.
+	ReadErrors uint64
+}
+
+// counters updated atomically on every read or write
+// should be placed first in struct to be 64-bit aligned on 32-bit platforms
+type ioCounters struct {
+	frames  uint64
+	samples uint64
+	errors  uint64
+}
+
+func (c *ioCounters) count(numSamples int, err error) {
+	atomic.AddUint64(&c.samples, uint64(numSamples))
+	if err != nil {
+		atomic.AddUint64(&c.errors, 1)
+		return
+	}
+	atomic.AddUint64(&c.frames, 1)
+}
+
+func (c *ioCounters) load() (frames, samples, errors uint64) {
+	return atomic.LoadUint64(&c.frames),
+		atomic.LoadUint64(&c.samples),
+		atomic.LoadUint64(&c.errors)
+}
+
+// Get sender statistics.
+//
+// Can be called at any time, including after the sender is closed.
+func (s *Sender) Stats() SenderStats {
+	var stats SenderStats
+	stats.FramesWritten, stats.SamplesWritten, stats.WriteErrors = s.counters.load()
+	return stats
+}
+
+// Get receiver statistics.
+//
+// Can be called at any time, including after the receiver is closed.
+func (r *Receiver) Stats() ReceiverStats {
+	var stats ReceiverStats
+	stats.FramesRead, stats.SamplesRead, stats.ReadErrors = r.counters.load()
+	return stats
+}
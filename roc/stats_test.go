@@ -0,0 +1,69 @@
+package roc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats_Sender(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	sender, err := OpenSender(ctx, makeSenderConfig())
+	require.NoError(t, err)
+
+	require.Equal(t, SenderStats{}, sender.Stats())
+
+	require.NoError(t, sender.WriteFloats(make([]float32, 100)))
+	require.NoError(t, sender.WriteInt16(make([]int16, 20)))
+	require.Error(t, sender.WriteFloats(nil))
+	require.Error(t, sender.WriteInt24Packed(make([]byte, 5)))
+
+	require.Equal(t, SenderStats{
+		FramesWritten:  2,
+		SamplesWritten: 120,
+		WriteErrors:    2,
+	}, sender.Stats())
+
+	require.NoError(t, sender.Close())
+	require.Error(t, sender.WriteFloats(make([]float32, 100)))
+
+	require.Equal(t, SenderStats{
+		FramesWritten:  2,
+		SamplesWritten: 120,
+		WriteErrors:    3,
+	}, sender.Stats())
+}
+
+func TestStats_Receiver(t *testing.T) {
+	ctx, err := OpenContext(makeContextConfig())
+	require.NoError(t, err)
+	defer ctx.Close()
+
+	receiver, err := OpenReceiver(ctx, makeReceiverConfig())
+	require.NoError(t, err)
+
+	require.Equal(t, ReceiverStats{}, receiver.Stats())
+
+	require.NoError(t, receiver.ReadFloats(make([]float32, 100)))
+	require.NoError(t, receiver.ReadInt32(make([]int32, 20)))
+	require.Error(t, receiver.ReadFloats(nil))
+	require.Error(t, receiver.ReadInt24Packed(make([]byte, 5)))
+
+	require.Equal(t, ReceiverStats{
+		FramesRead:  2,
+		SamplesRead: 120,
+		ReadErrors:  2,
+	}, receiver.Stats())
+
+	require.NoError(t, receiver.Close())
+	require.Error(t, receiver.ReadFloats(make([]float32, 100)))
+
+	require.Equal(t, ReceiverStats{
+		FramesRead:  2,
+		SamplesRead: 120,
+		ReadErrors:  3,
+	}, receiver.Stats())
+}
@@ -17,6 +17,8 @@ func TestStringer(t *testing.T) {
 		assert.NotEmpty(t, LatencyTunerProfile(i).String())
 		assert.NotEmpty(t, LogLevel(i).String())
 		assert.NotEmpty(t, PacketEncoding(i).String())
+		assert.NotEmpty(t, PcmFormat(i).String())
+		assert.NotEmpty(t, PlcBackend(i).String())
 		assert.NotEmpty(t, Protocol(i).String())
 		assert.NotEmpty(t, ResamplerBackend(i).String())
 		assert.NotEmpty(t, ResamplerProfile(i).String())
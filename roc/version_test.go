@@ -94,6 +94,30 @@ func TestVersion_Entrypoints(t *testing.T) {
 				defer recv.Close()
 			},
 		},
+		{
+			name: "OpenSenderEncoder",
+			entrypoint: func() {
+				ctx, err := OpenContext(ContextConfig{})
+				require.NoError(t, err)
+				defer ctx.Close()
+
+				encoder, err := OpenSenderEncoder(ctx, makeSenderConfig())
+				require.NoError(t, err)
+				defer encoder.Close()
+			},
+		},
+		{
+			name: "OpenReceiverDecoder",
+			entrypoint: func() {
+				ctx, err := OpenContext(ContextConfig{})
+				require.NoError(t, err)
+				defer ctx.Close()
+
+				decoder, err := OpenReceiverDecoder(ctx, makeReceiverConfig())
+				require.NoError(t, err)
+				defer decoder.Close()
+			},
+		},
 		{
 			name: "ParseEndpoint",
 			entrypoint: func() {
@@ -0,0 +1,162 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// Reader reads samples from WAV file.
+//
+// Reader parses RIFF header and provides access to raw interleaved samples
+// from data chunk. Format of samples is described by Reader.Header().
+//
+// Reader implements io.Reader. It returns io.EOF when the data chunk ends.
+// If the file is truncated (e.g. recording was interrupted), Reader returns
+// all available samples and then io.EOF.
+type Reader struct {
+	reader    io.Reader
+	header    Header
+	remaining int64
+}
+
+// NewReader reads WAV header from r and returns Reader positioned at the
+// beginning of samples.
+//
+// Chunks other than "fmt " and "data" are skipped. Besides plain PCM and
+// IEEE float format tags, WAVE_FORMAT_EXTENSIBLE with PCM or float sub-format
+// is also accepted.
+func NewReader(r io.Reader) (*Reader, error) {
+	if r == nil {
+		return nil, errors.New("reader is nil")
+	}
+
+	var riffHeader [riffHeaderSize]byte
+	if _, err := io.ReadFull(r, riffHeader[:]); err != nil {
+		return nil, fmt.Errorf("can't read riff header: %w", err)
+	}
+
+	if string(riffHeader[0:4]) != "RIFF" || string(riffHeader[8:12]) != "WAVE" {
+		return nil, errors.New("not a riff/wave file")
+	}
+
+	var (
+		header    Header
+		hasHeader bool
+	)
+
+	for {
+		var chunkHeader [chunkHeaderSize]byte
+		if _, err := io.ReadFull(r, chunkHeader[:]); err != nil {
+			if err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, fmt.Errorf("can't read chunk header: %w", err)
+		}
+
+		chunkID := string(chunkHeader[0:4])
+		chunkSize := binary.LittleEndian.Uint32(chunkHeader[4:8])
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < fmtChunkSize {
+				return nil, fmt.Errorf("invalid fmt chunk size: %d", chunkSize)
+			}
+
+			chunk := make([]byte, chunkSize+chunkSize%2)
+			if _, err := io.ReadFull(r, chunk); err != nil {
+				return nil, fmt.Errorf("can't read fmt chunk: %w", err)
+			}
+
+			var err error
+			if header, err = parseFmtChunk(chunk[:chunkSize]); err != nil {
+				return nil, err
+			}
+			hasHeader = true
+
+		case "data":
+			if !hasHeader {
+				return nil, errors.New("data chunk before fmt chunk")
+			}
+
+			remaining := int64(chunkSize)
+			if chunkSize == unknownDataSize {
+				remaining = -1
+			}
+
+			return &Reader{
+				reader:    r,
+				header:    header,
+				remaining: remaining,
+			}, nil
+
+		default:
+			skipSize := int64(chunkSize) + int64(chunkSize%2)
+			if _, err := io.CopyN(ioutil.Discard, r, skipSize); err != nil {
+				return nil, fmt.Errorf("can't skip %q chunk: %w", chunkID, err)
+			}
+		}
+	}
+}
+
+func parseFmtChunk(chunk []byte) (Header, error) {
+	formatTag := binary.LittleEndian.Uint16(chunk[0:2])
+	numChans := binary.LittleEndian.Uint16(chunk[2:4])
+	rate := binary.LittleEndian.Uint32(chunk[4:8])
+	bits := binary.LittleEndian.Uint16(chunk[14:16])
+
+	if formatTag == formatTagExtensible {
+		// cbSize(2) validBits(2) channelMask(4) subFormat(16)
+		if len(chunk) < fmtChunkSize+24 {
+			return Header{}, errors.New("invalid extensible fmt chunk")
+		}
+		formatTag = binary.LittleEndian.Uint16(chunk[24:26])
+	}
+
+	format, err := pcmFormat(formatTag, bits)
+	if err != nil {
+		return Header{}, err
+	}
+
+	header := Header{
+		Format:   format,
+		Channels: int(numChans),
+		Rate:     rate,
+	}
+
+	if err := header.validate(); err != nil {
+		return Header{}, err
+	}
+
+	return header, nil
+}
+
+// Header returns format of samples.
+func (r *Reader) Header() Header {
+	return r.header
+}
+
+// Read reads raw interleaved samples from data chunk.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if r.remaining == 0 {
+		return 0, io.EOF
+	}
+
+	if r.remaining > 0 && int64(len(p)) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err = r.reader.Read(p)
+
+	if r.remaining > 0 {
+		r.remaining -= int64(n)
+	}
+
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+
+	return n, err
+}
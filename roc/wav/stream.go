@@ -0,0 +1,259 @@
+package wav
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/roc-streaming/roc-go/roc"
+)
+
+// Duration of samples transferred at once by Send and Record.
+const chunkDuration = 10 * time.Millisecond
+
+// Send streams WAV file to sender.
+//
+// Reads WAV file from src and writes its samples to sender, until the end of
+// file or until ctx is cancelled. Encoding should be the FrameEncoding of
+// roc.SenderConfig used to open the sender. The file should have the same
+// sample rate and number of channels as the encoding.
+//
+// Samples are written in real time: if sender doesn't block (e.g. when it
+// uses roc.ClockSourceExternal), Send sleeps between writes to match the
+// sample rate. If the sender uses roc.ClockSourceInternal, pacing is done
+// by the sender itself.
+//
+// Returns nil when the whole file is sent, or ctx.Err() if ctx is cancelled.
+func Send(
+	ctx context.Context, sender *roc.Sender, encoding roc.MediaEncoding, src io.Reader,
+) error {
+	if sender == nil {
+		return errors.New("sender is nil")
+	}
+
+	reader, err := NewReader(src)
+	if err != nil {
+		return err
+	}
+
+	header := reader.Header()
+	if err := header.Check(encoding); err != nil {
+		return err
+	}
+
+	return send(ctx, sender, header, reader)
+}
+
+// SendRaw streams raw PCM samples to sender.
+//
+// Same as Send, but src contains interleaved samples in given format, without
+// WAV header. Sample rate and number of channels are taken from encoding.
+func SendRaw(
+	ctx context.Context, sender *roc.Sender, encoding roc.MediaEncoding,
+	src io.Reader, format roc.PcmFormat,
+) error {
+	if sender == nil {
+		return errors.New("sender is nil")
+	}
+
+	header, err := NewHeader(format, encoding)
+	if err != nil {
+		return err
+	}
+
+	return send(ctx, sender, header, src)
+}
+
+func send(ctx context.Context, sender *roc.Sender, header Header, src io.Reader) error {
+	writer, err := sender.AsWriter(header.Format)
+	if err != nil {
+		return err
+	}
+
+	frameSize := header.FrameSize()
+	buf := make([]byte, chunkLen(header.Rate)*frameSize)
+	pacer := newPacer(header.Rate)
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := io.ReadFull(src, buf)
+		if n != 0 {
+			if _, err := writer.Write(buf[:n]); err != nil {
+				return err
+			}
+			if err := pacer.wait(ctx, n/frameSize); err != nil {
+				return err
+			}
+		}
+
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// Record records stream from receiver to WAV file.
+//
+// Reads samples from receiver and writes them to dst as WAV file with given
+// sample format, until given duration is recorded or until ctx is cancelled.
+// If duration is zero, records until ctx is cancelled. Encoding should be the
+// FrameEncoding of roc.ReceiverConfig used to open the receiver.
+//
+// Samples are read in real time: if receiver doesn't block (e.g. when it
+// uses roc.ClockSourceExternal), Record sleeps between reads to match the
+// sample rate.
+//
+// WAV header is finalized in any case, including cancellation. If dst
+// implements io.Seeker, sizes in the header are updated. Record doesn't
+// close dst.
+//
+// Returns nil when the whole duration is recorded, or ctx.Err() if ctx is
+// cancelled.
+func Record(
+	ctx context.Context, receiver *roc.Receiver, encoding roc.MediaEncoding,
+	dst io.Writer, format roc.PcmFormat, duration time.Duration,
+) (err error) {
+	if receiver == nil {
+		return errors.New("receiver is nil")
+	}
+
+	header, err := NewHeader(format, encoding)
+	if err != nil {
+		return err
+	}
+
+	writer, err := NewWriter(dst, header)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		if closeErr := writer.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	return record(ctx, receiver, header, writer, duration)
+}
+
+// RecordRaw records stream from receiver as raw PCM samples.
+//
+// Same as Record, but writes interleaved samples in given format to dst,
+// without WAV header.
+func RecordRaw(
+	ctx context.Context, receiver *roc.Receiver, encoding roc.MediaEncoding,
+	dst io.Writer, format roc.PcmFormat, duration time.Duration,
+) error {
+	if receiver == nil {
+		return errors.New("receiver is nil")
+	}
+
+	header, err := NewHeader(format, encoding)
+	if err != nil {
+		return err
+	}
+
+	return record(ctx, receiver, header, dst, duration)
+}
+
+func record(
+	ctx context.Context, receiver *roc.Receiver, header Header,
+	dst io.Writer, duration time.Duration,
+) error {
+	reader, err := receiver.AsReader(header.Format)
+	if err != nil {
+		return err
+	}
+
+	remainingFrames := int64(-1)
+	if duration > 0 {
+		remainingFrames = int64(duration.Seconds()*float64(header.Rate) + 0.5)
+	}
+
+	frameSize := header.FrameSize()
+	buf := make([]byte, chunkLen(header.Rate)*frameSize)
+	pacer := newPacer(header.Rate)
+
+	for remainingFrames != 0 {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		numFrames := int64(len(buf) / frameSize)
+		if remainingFrames > 0 && numFrames > remainingFrames {
+			numFrames = remainingFrames
+		}
+
+		chunk := buf[:numFrames*int64(frameSize)]
+		if _, err := io.ReadFull(reader, chunk); err != nil {
+			return err
+		}
+		if _, err := dst.Write(chunk); err != nil {
+			return err
+		}
+
+		if remainingFrames > 0 {
+			remainingFrames -= numFrames
+		}
+
+		if err := pacer.wait(ctx, int(numFrames)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// returns number of samples per channel in chunkDuration
+func chunkLen(rate uint32) int {
+	n := int(uint64(rate) * uint64(chunkDuration) / uint64(time.Second))
+	if n == 0 {
+		n = 1
+	}
+	return n
+}
+
+// Sleeps to keep transferred samples in sync with wall clock.
+type pacer struct {
+	rate      uint64
+	startTime time.Time
+	numFrames uint64
+}
+
+func newPacer(rate uint32) *pacer {
+	return &pacer{
+		rate:      uint64(rate),
+		startTime: time.Now(),
+	}
+}
+
+// account numFrames transferred samples per channel, and sleep until
+// wall clock time reaches their stream time
+func (p *pacer) wait(ctx context.Context, numFrames int) error {
+	p.numFrames += uint64(numFrames)
+
+	streamTime := time.Duration(p.numFrames/p.rate)*time.Second +
+		time.Duration(p.numFrames%p.rate)*time.Second/time.Duration(p.rate)
+
+	delay := time.Until(p.startTime.Add(streamTime))
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
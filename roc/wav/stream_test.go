@@ -0,0 +1,194 @@
+package wav
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/roc-streaming/roc-go/roc"
+	"github.com/stretchr/testify/require"
+)
+
+func openContext(t *testing.T) *roc.Context {
+	ctx, err := roc.OpenContext(roc.ContextConfig{})
+	require.NoError(t, err)
+	return ctx
+}
+
+func TestSend(t *testing.T) {
+	rocCtx := openContext(t)
+	defer rocCtx.Close()
+
+	encoding := makeEncoding(roc.ChannelLayoutStereo)
+
+	sender, err := roc.OpenSender(rocCtx, roc.SenderConfig{
+		FrameEncoding:  encoding,
+		PacketEncoding: roc.PacketEncodingAvpL16Stereo,
+		ClockSource:    roc.ClockSourceExternal,
+	})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	header, err := NewHeader(roc.PcmFormatS16LE, encoding)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	writer, err := NewWriter(&buf, header)
+	require.NoError(t, err)
+
+	// 200ms
+	_, err = writer.Write(make([]byte, 8820*header.FrameSize()))
+	require.NoError(t, err)
+
+	err = writer.Close()
+	require.NoError(t, err)
+
+	startTime := time.Now()
+
+	err = Send(context.Background(), sender, encoding, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// pacing
+	require.GreaterOrEqual(t, int64(time.Since(startTime)), int64(150*time.Millisecond))
+
+	// mismatch
+	err = Send(context.Background(), sender,
+		makeEncoding(roc.ChannelLayoutMono), bytes.NewReader(buf.Bytes()))
+	require.Equal(t,
+		errors.New("channel count mismatch: wav has 2, encoding Mono has 1"), err)
+
+	// cancellation
+	cancelCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	err = Send(cancelCtx, sender, encoding, bytes.NewReader(buf.Bytes()))
+	require.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestSendRaw(t *testing.T) {
+	rocCtx := openContext(t)
+	defer rocCtx.Close()
+
+	encoding := makeEncoding(roc.ChannelLayoutStereo)
+
+	sender, err := roc.OpenSender(rocCtx, roc.SenderConfig{
+		FrameEncoding:  encoding,
+		PacketEncoding: roc.PacketEncodingAvpL16Stereo,
+		ClockSource:    roc.ClockSourceExternal,
+	})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	// 200ms
+	data := make([]byte, 8820*roc.PcmFormatS16LE.SampleSize()*2)
+
+	startTime := time.Now()
+
+	err = SendRaw(context.Background(), sender, encoding,
+		bytes.NewReader(data), roc.PcmFormatS16LE)
+	require.NoError(t, err)
+
+	// pacing
+	require.GreaterOrEqual(t, int64(time.Since(startTime)), int64(150*time.Millisecond))
+}
+
+func TestRecord(t *testing.T) {
+	rocCtx := openContext(t)
+	defer rocCtx.Close()
+
+	encoding := makeEncoding(roc.ChannelLayoutStereo)
+
+	receiver, err := roc.OpenReceiver(rocCtx, roc.ReceiverConfig{
+		FrameEncoding: encoding,
+		ClockSource:   roc.ClockSourceExternal,
+	})
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	for _, format := range []roc.PcmFormat{roc.PcmFormatS24LE, roc.PcmFormatF32LE} {
+		t.Run(format.String(), func(t *testing.T) {
+			file, err := ioutil.TempFile("", "roc-wav-test")
+			require.NoError(t, err)
+			defer os.Remove(file.Name())
+			defer file.Close()
+
+			startTime := time.Now()
+
+			// 100ms
+			err = Record(context.Background(), receiver, encoding,
+				file, format, 100*time.Millisecond)
+			require.NoError(t, err)
+
+			// pacing
+			require.GreaterOrEqual(t, int64(time.Since(startTime)), int64(50*time.Millisecond))
+
+			data, err := ioutil.ReadFile(file.Name())
+			require.NoError(t, err)
+
+			frameSize := format.SampleSize() * 2
+			require.Equal(t, uint32(4410*frameSize),
+				binary.LittleEndian.Uint32(data[headerSize-4:headerSize]))
+
+			reader, err := NewReader(bytes.NewReader(data))
+			require.NoError(t, err)
+			require.NoError(t, reader.Header().Check(encoding))
+			require.Equal(t, format, reader.Header().Format)
+
+			samples, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, make([]byte, 4410*frameSize), samples)
+		})
+	}
+
+	t.Run("cancellation", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		cancelCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		// until cancelled
+		err = Record(cancelCtx, receiver, encoding, &buf, roc.PcmFormatS16LE, 0)
+		require.Equal(t, context.DeadlineExceeded, err)
+
+		reader, err := NewReader(&buf)
+		require.NoError(t, err)
+
+		samples, err := ioutil.ReadAll(reader)
+		require.NoError(t, err)
+		require.NotEmpty(t, samples)
+	})
+}
+
+func TestRecordRaw(t *testing.T) {
+	rocCtx := openContext(t)
+	defer rocCtx.Close()
+
+	encoding := makeEncoding(roc.ChannelLayoutStereo)
+
+	receiver, err := roc.OpenReceiver(rocCtx, roc.ReceiverConfig{
+		FrameEncoding: encoding,
+		ClockSource:   roc.ClockSourceExternal,
+	})
+	require.NoError(t, err)
+	defer receiver.Close()
+
+	var buf bytes.Buffer
+
+	startTime := time.Now()
+
+	// 100ms
+	err = RecordRaw(context.Background(), receiver, encoding,
+		&buf, roc.PcmFormatS16LE, 100*time.Millisecond)
+	require.NoError(t, err)
+
+	// pacing
+	require.GreaterOrEqual(t, int64(time.Since(startTime)), int64(50*time.Millisecond))
+
+	// no header
+	require.Equal(t, make([]byte, 4410*roc.PcmFormatS16LE.SampleSize()*2), buf.Bytes())
+}
@@ -0,0 +1,150 @@
+// Package wav implements reading and writing of WAV files, and streaming them
+// to and from Roc senders and receivers.
+//
+// Reader and Writer handle RIFF/WAVE container with PCM 16-bit, 24-bit and
+// 32-bit integer samples, and 32-bit IEEE float samples. Send and Record
+// connect them with roc.Sender and roc.Receiver. SendRaw and RecordRaw do
+// the same for raw PCM streams without WAV header.
+package wav
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/roc-streaming/roc-go/roc"
+)
+
+// WAV format tags.
+const (
+	formatTagPcm        = 0x0001
+	formatTagFloat      = 0x0003
+	formatTagExtensible = 0xFFFE
+)
+
+// Sizes of RIFF structures.
+const (
+	riffHeaderSize  = 12
+	chunkHeaderSize = 8
+	fmtChunkSize    = 16
+	headerSize      = riffHeaderSize + chunkHeaderSize + fmtChunkSize + chunkHeaderSize
+)
+
+// Value of data chunk size used by streaming encoders when the size is not
+// known in advance.
+const unknownDataSize = 0xFFFFFFFF
+
+// Header describes format of samples in WAV file.
+type Header struct {
+	// Sample format.
+	//
+	// PcmFormatS16LE, PcmFormatS24LE, and PcmFormatS32LE correspond to WAV
+	// PCM format with 16, 24, and 32 bits per sample. PcmFormatF32LE
+	// corresponds to WAV IEEE float format with 32 bits per sample.
+	Format roc.PcmFormat
+
+	// Number of interleaved channels.
+	Channels int
+
+	// Number of samples per channel per second.
+	Rate uint32
+}
+
+// NewHeader returns header matching given sample format and media encoding.
+//
+// Encoding is typically FrameEncoding from roc.SenderConfig or
+// roc.ReceiverConfig.
+func NewHeader(format roc.PcmFormat, encoding roc.MediaEncoding) (Header, error) {
+	numChans, err := channelCount(encoding)
+	if err != nil {
+		return Header{}, err
+	}
+
+	header := Header{
+		Format:   format,
+		Channels: numChans,
+		Rate:     encoding.Rate,
+	}
+
+	if err := header.validate(); err != nil {
+		return Header{}, err
+	}
+
+	return header, nil
+}
+
+// FrameSize returns number of bytes per sample for all channels.
+func (h Header) FrameSize() int {
+	return h.Format.SampleSize() * h.Channels
+}
+
+// Check checks that header is compatible with given media encoding, i.e.
+// has the same sample rate and number of channels.
+func (h Header) Check(encoding roc.MediaEncoding) error {
+	numChans, err := channelCount(encoding)
+	if err != nil {
+		return err
+	}
+
+	if h.Channels != numChans {
+		return fmt.Errorf("channel count mismatch: wav has %d, encoding %v has %d",
+			h.Channels, encoding.Channels, numChans)
+	}
+
+	if h.Rate != encoding.Rate {
+		return fmt.Errorf("sample rate mismatch: wav has %d, encoding has %d",
+			h.Rate, encoding.Rate)
+	}
+
+	return nil
+}
+
+func (h Header) validate() error {
+	if h.Format.SampleSize() == 0 {
+		return fmt.Errorf("unsupported sample format: %v", h.Format)
+	}
+
+	if h.Channels <= 0 || h.Channels > 0xFFFF {
+		return fmt.Errorf("invalid channel count: %d", h.Channels)
+	}
+
+	if h.Rate == 0 {
+		return errors.New("invalid sample rate: 0")
+	}
+
+	return nil
+}
+
+func (h Header) formatTag() uint16 {
+	if h.Format == roc.PcmFormatF32LE {
+		return formatTagFloat
+	}
+	return formatTagPcm
+}
+
+func channelCount(encoding roc.MediaEncoding) (int, error) {
+	switch encoding.Channels {
+	case roc.ChannelLayoutMono:
+		return 1, nil
+	case roc.ChannelLayoutStereo:
+		return 2, nil
+	case roc.ChannelLayoutMultitrack:
+		if encoding.Tracks != 0 {
+			return int(encoding.Tracks), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported channel layout: %v", encoding.Channels)
+}
+
+func pcmFormat(formatTag uint16, bits uint16) (roc.PcmFormat, error) {
+	switch {
+	case formatTag == formatTagPcm && bits == 16:
+		return roc.PcmFormatS16LE, nil
+	case formatTag == formatTagPcm && bits == 24:
+		return roc.PcmFormatS24LE, nil
+	case formatTag == formatTagPcm && bits == 32:
+		return roc.PcmFormatS32LE, nil
+	case formatTag == formatTagFloat && bits == 32:
+		return roc.PcmFormatF32LE, nil
+	}
+	return 0, fmt.Errorf("unsupported wav format: tag=0x%04x bits=%d", formatTag, bits)
+}
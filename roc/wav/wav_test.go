@@ -0,0 +1,420 @@
+package wav
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/roc-streaming/roc-go/roc"
+	"github.com/stretchr/testify/require"
+)
+
+type testChunk struct {
+	id   string
+	data []byte
+}
+
+func makeFmtChunk(formatTag uint16, numChans uint16, rate uint32, bits uint16) testChunk {
+	data := make([]byte, fmtChunkSize)
+	binary.LittleEndian.PutUint16(data[0:2], formatTag)
+	binary.LittleEndian.PutUint16(data[2:4], numChans)
+	binary.LittleEndian.PutUint32(data[4:8], rate)
+	binary.LittleEndian.PutUint32(data[8:12], rate*uint32(numChans*bits/8))
+	binary.LittleEndian.PutUint16(data[12:14], numChans*bits/8)
+	binary.LittleEndian.PutUint16(data[14:16], bits)
+	return testChunk{id: "fmt ", data: data}
+}
+
+func makeExtensibleFmtChunk(subFormat uint16, numChans uint16, rate uint32, bits uint16) testChunk {
+	chunk := makeFmtChunk(formatTagExtensible, numChans, rate, bits)
+	ext := make([]byte, 24)
+	binary.LittleEndian.PutUint16(ext[0:2], 22)
+	binary.LittleEndian.PutUint16(ext[2:4], bits)
+	binary.LittleEndian.PutUint16(ext[8:10], subFormat)
+	chunk.data = append(chunk.data, ext...)
+	return chunk
+}
+
+func makeWav(chunks ...testChunk) []byte {
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	for _, chunk := range chunks {
+		body.WriteString(chunk.id)
+		_ = binary.Write(&body, binary.LittleEndian, uint32(len(chunk.data)))
+		body.Write(chunk.data)
+		if len(chunk.data)%2 != 0 {
+			body.WriteByte(0)
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	_ = binary.Write(&buf, binary.LittleEndian, uint32(body.Len()))
+	buf.Write(body.Bytes())
+	return buf.Bytes()
+}
+
+func makeEncoding(channels roc.ChannelLayout) roc.MediaEncoding {
+	return roc.MediaEncoding{
+		Rate:     44100,
+		Format:   roc.FormatPcmFloat32,
+		Channels: channels,
+	}
+}
+
+func TestReader(t *testing.T) {
+	samples := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	tests := []struct {
+		name       string
+		file       []byte
+		wantHeader Header
+	}{
+		{
+			name: "pcm16",
+			file: makeWav(
+				makeFmtChunk(formatTagPcm, 2, 44100, 16),
+				testChunk{id: "data", data: samples},
+			),
+			wantHeader: Header{Format: roc.PcmFormatS16LE, Channels: 2, Rate: 44100},
+		},
+		{
+			name: "pcm24",
+			file: makeWav(
+				makeFmtChunk(formatTagPcm, 1, 48000, 24),
+				testChunk{id: "data", data: samples},
+			),
+			wantHeader: Header{Format: roc.PcmFormatS24LE, Channels: 1, Rate: 48000},
+		},
+		{
+			name: "pcm32",
+			file: makeWav(
+				makeFmtChunk(formatTagPcm, 2, 44100, 32),
+				testChunk{id: "data", data: samples},
+			),
+			wantHeader: Header{Format: roc.PcmFormatS32LE, Channels: 2, Rate: 44100},
+		},
+		{
+			name: "float32",
+			file: makeWav(
+				makeFmtChunk(formatTagFloat, 2, 44100, 32),
+				testChunk{id: "fact", data: []byte{2, 0, 0, 0}},
+				testChunk{id: "data", data: samples},
+			),
+			wantHeader: Header{Format: roc.PcmFormatF32LE, Channels: 2, Rate: 44100},
+		},
+		{
+			name: "extensible",
+			file: makeWav(
+				makeExtensibleFmtChunk(formatTagPcm, 4, 44100, 16),
+				testChunk{id: "data", data: samples},
+			),
+			wantHeader: Header{Format: roc.PcmFormatS16LE, Channels: 4, Rate: 44100},
+		},
+		{
+			name: "extra chunks",
+			file: makeWav(
+				testChunk{id: "JUNK", data: []byte{1, 2, 3}},
+				makeFmtChunk(formatTagPcm, 2, 44100, 16),
+				testChunk{id: "LIST", data: []byte("INFOtest")},
+				testChunk{id: "data", data: samples},
+				testChunk{id: "LIST", data: []byte("INFOtest")},
+			),
+			wantHeader: Header{Format: roc.PcmFormatS16LE, Channels: 2, Rate: 44100},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewReader(tt.file))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantHeader, reader.Header())
+
+			data, err := ioutil.ReadAll(reader)
+			require.NoError(t, err)
+			require.Equal(t, samples, data)
+		})
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    []byte
+		wantErr error
+	}{
+		{
+			name:    "empty",
+			file:    []byte{},
+			wantErr: fmt.Errorf("can't read riff header: %w", io.EOF),
+		},
+		{
+			name:    "not riff",
+			file:    []byte("RIFX\x00\x00\x00\x00WAVE"),
+			wantErr: errors.New("not a riff/wave file"),
+		},
+		{
+			name:    "no data",
+			file:    makeWav(makeFmtChunk(formatTagPcm, 2, 44100, 16)),
+			wantErr: fmt.Errorf("can't read chunk header: %w", io.ErrUnexpectedEOF),
+		},
+		{
+			name: "data before fmt",
+			file: makeWav(
+				testChunk{id: "data", data: []byte{1, 2}},
+				makeFmtChunk(formatTagPcm, 2, 44100, 16),
+			),
+			wantErr: errors.New("data chunk before fmt chunk"),
+		},
+		{
+			name: "unsupported bits",
+			file: makeWav(
+				makeFmtChunk(formatTagPcm, 2, 44100, 8),
+				testChunk{id: "data", data: []byte{1, 2}},
+			),
+			wantErr: errors.New("unsupported wav format: tag=0x0001 bits=8"),
+		},
+		{
+			name: "unsupported tag",
+			file: makeWav(
+				makeFmtChunk(0x0055, 2, 44100, 16),
+				testChunk{id: "data", data: []byte{1, 2}},
+			),
+			wantErr: errors.New("unsupported wav format: tag=0x0055 bits=16"),
+		},
+		{
+			name: "zero channels",
+			file: makeWav(
+				makeFmtChunk(formatTagPcm, 0, 44100, 16),
+				testChunk{id: "data", data: []byte{1, 2}},
+			),
+			wantErr: errors.New("invalid channel count: 0"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reader, err := NewReader(bytes.NewReader(tt.file))
+			require.Equal(t, tt.wantErr, err)
+			require.Nil(t, reader)
+		})
+	}
+}
+
+func TestReader_Truncated(t *testing.T) {
+	file := makeWav(
+		makeFmtChunk(formatTagPcm, 2, 44100, 16),
+		testChunk{id: "data", data: []byte{1, 2, 3, 4, 5, 6, 7, 8}},
+	)
+
+	reader, err := NewReader(bytes.NewReader(file[:len(file)-3]))
+	require.NoError(t, err)
+
+	data, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4, 5}, data)
+}
+
+func TestWriter(t *testing.T) {
+	for _, format := range []roc.PcmFormat{
+		roc.PcmFormatS16LE, roc.PcmFormatS24LE, roc.PcmFormatS32LE, roc.PcmFormatF32LE,
+	} {
+		for _, channels := range []roc.ChannelLayout{
+			roc.ChannelLayoutMono, roc.ChannelLayoutStereo,
+		} {
+			t.Run(fmt.Sprintf("%v/%v", format, channels), func(t *testing.T) {
+				file, err := ioutil.TempFile("", "roc-wav-test")
+				require.NoError(t, err)
+				defer os.Remove(file.Name())
+				defer file.Close()
+
+				header, err := NewHeader(format, makeEncoding(channels))
+				require.NoError(t, err)
+
+				writer, err := NewWriter(file, header)
+				require.NoError(t, err)
+
+				// odd number of bytes for mono 24-bit, to check padding
+				samples := make([]byte, header.FrameSize()*101)
+				for i := range samples {
+					samples[i] = byte(i)
+				}
+
+				for i := 0; i < len(samples); i += 100 {
+					end := i + 100
+					if end > len(samples) {
+						end = len(samples)
+					}
+					n, err := writer.Write(samples[i:end])
+					require.NoError(t, err)
+					require.Equal(t, end-i, n)
+				}
+
+				err = writer.Close()
+				require.NoError(t, err)
+
+				err = writer.Close()
+				require.NoError(t, err)
+
+				_, err = writer.Write(samples)
+				require.Equal(t, errors.New("writer is closed"), err)
+
+				data, err := ioutil.ReadFile(file.Name())
+				require.NoError(t, err)
+
+				require.Equal(t, uint32(len(data)-chunkHeaderSize),
+					binary.LittleEndian.Uint32(data[4:8]))
+				require.Equal(t, uint32(len(samples)),
+					binary.LittleEndian.Uint32(data[headerSize-4:headerSize]))
+
+				reader, err := NewReader(bytes.NewReader(data))
+				require.NoError(t, err)
+				require.Equal(t, header, reader.Header())
+
+				readSamples, err := ioutil.ReadAll(reader)
+				require.NoError(t, err)
+				require.Equal(t, samples, readSamples)
+			})
+		}
+	}
+}
+
+func TestWriter_NotSeekable(t *testing.T) {
+	var buf bytes.Buffer
+
+	header, err := NewHeader(roc.PcmFormatS16LE, makeEncoding(roc.ChannelLayoutStereo))
+	require.NoError(t, err)
+
+	writer, err := NewWriter(&buf, header)
+	require.NoError(t, err)
+
+	samples := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	_, err = writer.Write(samples)
+	require.NoError(t, err)
+
+	err = writer.Close()
+	require.NoError(t, err)
+
+	data := buf.Bytes()
+	require.Equal(t, uint32(unknownDataSize), binary.LittleEndian.Uint32(data[4:8]))
+	require.Equal(t, uint32(unknownDataSize),
+		binary.LittleEndian.Uint32(data[headerSize-4:headerSize]))
+
+	reader, err := NewReader(&buf)
+	require.NoError(t, err)
+
+	readSamples, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, samples, readSamples)
+}
+
+func TestWriter_Pipe(t *testing.T) {
+	pr, pw, err := os.Pipe()
+	require.NoError(t, err)
+	defer pr.Close()
+
+	dataCh := make(chan []byte, 1)
+	go func() {
+		data, _ := ioutil.ReadAll(pr)
+		dataCh <- data
+	}()
+
+	header, err := NewHeader(roc.PcmFormatS16LE, makeEncoding(roc.ChannelLayoutStereo))
+	require.NoError(t, err)
+
+	// *os.File implements io.Seeker, but pipe can't seek
+	writer, err := NewWriter(pw, header)
+	require.NoError(t, err)
+
+	samples := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	_, err = writer.Write(samples)
+	require.NoError(t, err)
+
+	err = writer.Close()
+	require.NoError(t, err)
+
+	require.NoError(t, pw.Close())
+
+	data := <-dataCh
+	require.Equal(t, uint32(unknownDataSize), binary.LittleEndian.Uint32(data[4:8]))
+
+	reader, err := NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+
+	readSamples, err := ioutil.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, samples, readSamples)
+}
+
+func TestHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		format     roc.PcmFormat
+		encoding   roc.MediaEncoding
+		wantHeader Header
+		wantErr    error
+	}{
+		{
+			name:       "mono",
+			format:     roc.PcmFormatS16LE,
+			encoding:   makeEncoding(roc.ChannelLayoutMono),
+			wantHeader: Header{Format: roc.PcmFormatS16LE, Channels: 1, Rate: 44100},
+		},
+		{
+			name:   "multitrack",
+			format: roc.PcmFormatF32LE,
+			encoding: roc.MediaEncoding{
+				Rate:     48000,
+				Format:   roc.FormatPcmFloat32,
+				Channels: roc.ChannelLayoutMultitrack,
+				Tracks:   6,
+			},
+			wantHeader: Header{Format: roc.PcmFormatF32LE, Channels: 6, Rate: 48000},
+		},
+		{
+			name:     "bad format",
+			format:   0,
+			encoding: makeEncoding(roc.ChannelLayoutStereo),
+			wantErr:  errors.New("unsupported sample format: PcmFormat(0)"),
+		},
+		{
+			name:     "bad channels",
+			format:   roc.PcmFormatS16LE,
+			encoding: makeEncoding(0),
+			wantErr:  errors.New("unsupported channel layout: ChannelLayout(0)"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header, err := NewHeader(tt.format, tt.encoding)
+			require.Equal(t, tt.wantErr, err)
+			require.Equal(t, tt.wantHeader, header)
+		})
+	}
+}
+
+func TestHeader_Check(t *testing.T) {
+	header := Header{Format: roc.PcmFormatS16LE, Channels: 2, Rate: 44100}
+
+	err := header.Check(makeEncoding(roc.ChannelLayoutStereo))
+	require.NoError(t, err)
+
+	err = header.Check(makeEncoding(roc.ChannelLayoutMono))
+	require.Equal(t,
+		errors.New("channel count mismatch: wav has 2, encoding Mono has 1"), err)
+
+	encoding := makeEncoding(roc.ChannelLayoutStereo)
+	encoding.Rate = 48000
+
+	err = header.Check(encoding)
+	require.Equal(t,
+		errors.New("sample rate mismatch: wav has 44100, encoding has 48000"), err)
+}
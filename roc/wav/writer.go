@@ -0,0 +1,150 @@
+package wav
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Maximum size of data chunk, limited by 32-bit RIFF size field.
+const maxDataSize = unknownDataSize - (headerSize - chunkHeaderSize) - 1
+
+// Writer writes samples to WAV file.
+//
+// Writer writes RIFF header when created, and then accepts raw interleaved
+// samples in format described by the header.
+//
+// Sizes in RIFF header are not known until all samples are written. If the
+// underlying writer implements io.Seeker and can actually seek (e.g. it's a
+// regular file, not a pipe or terminal), Writer.Close() seeks back and
+// updates the sizes. Otherwise, the sizes are left set to 0xFFFFFFFF, which
+// is commonly used for streamed WAV files and is understood by Reader.
+type Writer struct {
+	writer   io.Writer
+	seeker   io.Seeker // nil if header can't be updated
+	header   Header
+	offset   int64
+	dataSize int64
+	closed   bool
+}
+
+// NewWriter writes WAV header to w and returns Writer for samples.
+func NewWriter(w io.Writer, header Header) (*Writer, error) {
+	if w == nil {
+		return nil, errors.New("writer is nil")
+	}
+
+	if err := header.validate(); err != nil {
+		return nil, err
+	}
+
+	// remember where the header starts, to update it on close; if seeking
+	// fails (e.g. w is a pipe), fall back to streaming mode
+	var offset int64
+	seeker, _ := w.(io.Seeker)
+	if seeker != nil {
+		var err error
+		if offset, err = seeker.Seek(0, io.SeekCurrent); err != nil {
+			seeker = nil
+			offset = 0
+		}
+	}
+
+	var buf [headerSize]byte
+
+	copy(buf[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(buf[4:8], unknownDataSize)
+	copy(buf[8:12], "WAVE")
+
+	copy(buf[12:16], "fmt ")
+	binary.LittleEndian.PutUint32(buf[16:20], fmtChunkSize)
+	binary.LittleEndian.PutUint16(buf[20:22], header.formatTag())
+	binary.LittleEndian.PutUint16(buf[22:24], uint16(header.Channels))
+	binary.LittleEndian.PutUint32(buf[24:28], header.Rate)
+	binary.LittleEndian.PutUint32(buf[28:32], header.Rate*uint32(header.FrameSize()))
+	binary.LittleEndian.PutUint16(buf[32:34], uint16(header.FrameSize()))
+	binary.LittleEndian.PutUint16(buf[34:36], uint16(header.Format.SampleSize()*8))
+
+	copy(buf[36:40], "data")
+	binary.LittleEndian.PutUint32(buf[40:44], unknownDataSize)
+
+	if _, err := w.Write(buf[:]); err != nil {
+		return nil, err
+	}
+
+	return &Writer{
+		writer: w,
+		seeker: seeker,
+		header: header,
+		offset: offset,
+	}, nil
+}
+
+// Header returns format of samples.
+func (w *Writer) Header() Header {
+	return w.header
+}
+
+// Write writes raw interleaved samples to data chunk.
+func (w *Writer) Write(p []byte) (n int, err error) {
+	if w.closed {
+		return 0, errors.New("writer is closed")
+	}
+
+	if w.dataSize+int64(len(p)) > maxDataSize {
+		return 0, errors.New("wav file is too large")
+	}
+
+	n, err = w.writer.Write(p)
+	w.dataSize += int64(n)
+
+	return n, err
+}
+
+// Close finishes WAV file.
+//
+// Writes padding byte if needed, and, if the underlying writer is seekable,
+// updates sizes in RIFF header. Doesn't close the underlying
+// writer. Subsequent calls are no-op.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+
+	// chunks are aligned to 2 bytes
+	riffSize := headerSize - chunkHeaderSize + w.dataSize
+	if w.dataSize%2 != 0 {
+		if _, err := w.writer.Write([]byte{0}); err != nil {
+			return err
+		}
+		riffSize++
+	}
+
+	if w.seeker == nil {
+		return nil
+	}
+
+	if err := w.writeAt(4, uint32(riffSize)); err != nil {
+		return err
+	}
+
+	if err := w.writeAt(headerSize-4, uint32(w.dataSize)); err != nil {
+		return err
+	}
+
+	_, err := w.seeker.Seek(0, io.SeekEnd)
+	return err
+}
+
+func (w *Writer) writeAt(offset int64, value uint32) error {
+	if _, err := w.seeker.Seek(w.offset+offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], value)
+
+	_, err := w.writer.Write(buf[:])
+	return err
+}